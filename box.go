@@ -5,7 +5,9 @@ import (
 	"fmt"
 	"image"
 	"image/draw"
+	"sort"
 	"unicode"
+	"unicode/utf8"
 
 	"golang.org/x/image/font"
 	"golang.org/x/image/math/fixed"
@@ -23,7 +25,7 @@ type Box interface {
 	DrawBox(i Image, y fixed.Int26_6, dc *DrawConfig)
 	// FontDrawer returns the font face used for this box.
 	FontDrawer() *font.Drawer
-	// Len returns the length of the content (e.g. rune count).
+	// Len returns the length of the content in runes, as positions in the text are counted.
 	Len() int
 	// TextValue returns the text string content of the box.
 	TextValue() string
@@ -87,6 +89,19 @@ type SimpleBoxer struct {
 	fontDrawer     *font.Drawer
 	Tokenizer      Tokenizer
 	cacheQueue     []Box
+	measureCache   *MeasureCache
+	// runes is the decoded text of each content
+	runes [][]rune
+	// starts is the rune offset each content starts at, with the total length at the end
+	starts []int
+	// lastLive is the index of the last content that produces a box, or -1
+	lastLive int
+	// queueLen is the rune length of the boxes in cacheQueue
+	queueLen int
+	// listIndent is the indent of the list item being boxed, if any
+	listIndent int
+	// listLevel is how deeply nested the lists being boxed are
+	listLevel int
 }
 
 // Ensures that SimpleBoxer fits model
@@ -107,6 +122,7 @@ func NewSimpleBoxer(contents []*Content, drawer *font.Drawer, options ...BoxerOp
 	for _, option := range options {
 		option.ApplyBoxConfig(sb)
 	}
+	sb.indexContents()
 	return sb
 }
 
@@ -126,6 +142,7 @@ func NewRichBoxer(args ...interface{}) *SimpleBoxer {
 	for _, option := range boxerOptions {
 		option.ApplyBoxConfig(sb)
 	}
+	sb.indexContents()
 	return sb
 }
 
@@ -134,20 +151,39 @@ func (sb *SimpleBoxer) Reset() {
 	sb.n = 0
 	sb.contentIndex = 0
 	sb.cacheQueue = nil
+	sb.queueLen = 0
+	sb.indexContents()
 }
 
-// Pos current parser position.
-func (sb *SimpleBoxer) Pos() int {
+// indexContents decodes the text of every content and records where each starts, so positions can be found without
+// walking the contents
+func (sb *SimpleBoxer) indexContents() {
+	sb.runes = make([][]rune, len(sb.contents))
+	sb.starts = make([]int, len(sb.contents)+1)
+	sb.lastLive = -1
 	pos := 0
-	for i := 0; i < sb.contentIndex; i++ {
-		pos += len([]rune(sb.contents[i].text))
+	for i, c := range sb.contents {
+		sb.runes[i] = []rune(c.text)
+		sb.starts[i] = pos
+		pos += c.runeLen()
+		if c.boxedWhole() || len(sb.runes[i]) > 0 {
+			sb.lastLive = i
+		}
 	}
-	pos += sb.n
+	sb.starts[len(sb.contents)] = pos
+}
 
-	for _, e := range sb.cacheQueue {
-		pos -= e.Len()
+// indexed makes sure the contents have been indexed, for boxers that weren't made with a constructor
+func (sb *SimpleBoxer) indexed() {
+	if len(sb.starts) != len(sb.contents)+1 {
+		sb.indexContents()
 	}
-	return pos
+}
+
+// Pos current parser position.
+func (sb *SimpleBoxer) Pos() int {
+	sb.indexed()
+	return sb.starts[min(sb.contentIndex, len(sb.contents))] + sb.n - sb.queueLen
 }
 
 // HasNext unprocessed bytes exist
@@ -155,23 +191,18 @@ func (sb *SimpleBoxer) HasNext() bool {
 	if len(sb.cacheQueue) > 0 {
 		return true
 	}
-	for i := sb.contentIndex; i < len(sb.contents); i++ {
-		text := []rune(sb.contents[i].text)
-		start := 0
-		if i == sb.contentIndex {
-			start = sb.n
-		}
-		if len(sb.contents[i].children) > 0 && start == 0 {
-			return true
-		}
-		if sb.contents[i].image != nil && start == 0 {
-			return true
-		}
-		if start < len(text) {
-			return true
-		}
+	sb.indexed()
+	if sb.contentIndex >= len(sb.contents) {
+		return false
 	}
-	return false
+	if sb.lastLive > sb.contentIndex {
+		return true
+	}
+	c := sb.contents[sb.contentIndex]
+	if c.boxedWhole() && sb.n == 0 {
+		return true
+	}
+	return sb.n < len(sb.runes[sb.contentIndex])
 }
 
 // SetFontDrawer Changes the default font
@@ -181,16 +212,17 @@ func (sb *SimpleBoxer) SetFontDrawer(face *font.Drawer) {
 
 // Back goes back i spaces (ie unreads)
 func (sb *SimpleBoxer) Back(i int) {
-	sb.n -= i
-	for sb.n < 0 {
-		sb.contentIndex--
-		if sb.contentIndex < 0 {
-			sb.contentIndex = 0
-			sb.n = 0
-			break
-		}
-		sb.n += len([]rune(sb.contents[sb.contentIndex].text))
+	sb.indexed()
+	ci := min(sb.contentIndex, len(sb.contents))
+	target := sb.starts[ci] + sb.n - i
+	if target < 0 {
+		sb.contentIndex = 0
+		sb.n = 0
+		return
 	}
+	// The last content up to the current one which starts at or before the target
+	sb.contentIndex = sort.Search(ci+1, func(n int) bool { return sb.starts[n] > target }) - 1
+	sb.n = target - sb.starts[sb.contentIndex]
 }
 
 // FontDrawer encapsulates default fonts and more
@@ -201,22 +233,34 @@ func (sb *SimpleBoxer) FontDrawer() *font.Drawer {
 // Push puts a box back on to the cache stack
 func (sb *SimpleBoxer) Push(box ...Box) {
 	sb.cacheQueue = append(sb.cacheQueue, box...)
+	sb.queueLen += boxesLen(box)
 }
 
 // Unshift basically is Push but to the start
 func (sb *SimpleBoxer) Unshift(box ...Box) {
 	sb.cacheQueue = append(append(make([]Box, 0, len(box)+len(sb.cacheQueue)), box...), sb.cacheQueue...)
+	sb.queueLen += boxesLen(box)
 }
 
 func (sb *SimpleBoxer) Shift() Box {
 	if len(sb.cacheQueue) > 0 {
 		cb := sb.cacheQueue[0]
 		sb.cacheQueue = sb.cacheQueue[1:]
+		sb.queueLen -= cb.Len()
 		return cb
 	}
 	return nil
 }
 
+// boxesLen the total rune length of the boxes
+func boxesLen(boxes []Box) int {
+	l := 0
+	for _, b := range boxes {
+		l += b.Len()
+	}
+	return l
+}
+
 // RowBox holds multiple boxes on a single line
 type RowBox struct {
 	Boxes []Box
@@ -313,6 +357,68 @@ func (rb *RowBox) DrawBox(i Image, y fixed.Int26_6, dc *DrawConfig) {
 	}
 }
 
+// contentDrawer the drawer for the content's font and colour
+func (sb *SimpleBoxer) contentDrawer(c *Content) *font.Drawer {
+	drawer := sb.fontDrawer
+	if c.style != nil {
+		if c.style.font != nil {
+			drawer = &font.Drawer{
+				Src:  sb.fontDrawer.Src,
+				Face: c.style.font,
+			}
+		}
+		if c.style.FontDrawerSrc != nil {
+			if drawer == sb.fontDrawer {
+				drawer = &font.Drawer{
+					Src:  sb.fontDrawer.Src,
+					Face: sb.fontDrawer.Face,
+				}
+			}
+			drawer.Src = c.style.FontDrawerSrc
+		}
+	}
+	return drawer
+}
+
+// decorateText wraps a box of the content's text in the boxes of the content's style and ID
+func (c *Content) decorateText(b Box) Box {
+	if c.style != nil {
+		if c.style.BackgroundColor != nil {
+			b = &BackgroundBox{
+				Box:           b,
+				Background:    c.style.BackgroundColor,
+				BgPositioning: c.style.BgPositioning,
+			}
+		}
+		if len(c.style.Effects) > 0 {
+			b = &EffectBox{
+				Box:     b,
+				Effects: c.style.Effects,
+			}
+		}
+		if c.style.Alignment != AlignBaseline {
+			b = &AlignedBox{
+				Box:       b,
+				Alignment: c.style.Alignment,
+			}
+		}
+		if !c.style.Padding.Empty() || !c.style.Margin.Empty() {
+			bg := c.style.BackgroundColor
+			b = NewDecorationBox(b, c.style.Padding, c.style.Margin, bg, c.style.BgPositioning)
+		}
+		for i := len(c.decorators) - 1; i >= 0; i-- {
+			b = c.decorators[i](b)
+		}
+	}
+	if c.id != nil {
+		b = &IDBox{
+			Box: b,
+			id:  c.id,
+		}
+	}
+	return b
+}
+
 // Next gets the next word in a Box
 func (sb *SimpleBoxer) Next() (Box, int, error) {
 	if len(sb.cacheQueue) > 0 {
@@ -324,6 +430,57 @@ func (sb *SimpleBoxer) Next() (Box, int, error) {
 		}
 		currentContent := sb.contents[sb.contentIndex]
 
+		if currentContent.footnote != nil {
+			sb.contentIndex++
+			sb.n = 0
+			b, err := sb.footnoteBox(currentContent)
+			if err != nil {
+				return nil, 0, fmt.Errorf("boxing footnote: %w", err)
+			}
+			return b, 0, nil
+		}
+
+		if currentContent.field != nil {
+			sb.contentIndex++
+			sb.n = 0
+			b, err := newFieldBox(sb.contentDrawer(currentContent), *currentContent.field, currentContent.decorateText)
+			if err != nil {
+				return nil, 0, fmt.Errorf("boxing field: %w", err)
+			}
+			return b, 0, nil
+		}
+
+		if currentContent.section != nil {
+			sb.contentIndex++
+			sb.n = 0
+			return &sectionBox{title: currentContent.section.title}, 0, nil
+		}
+
+		if currentContent.list != nil {
+			sb.contentIndex++
+			sb.n = 0
+			boxes, err := sb.listBoxes(currentContent)
+			if err != nil {
+				return nil, 0, fmt.Errorf("boxing list: %w", err)
+			}
+			sb.Push(boxes[1:]...)
+			return boxes[0], 0, nil
+		}
+
+		if currentContent.table != nil {
+			sb.contentIndex++
+			sb.n = 0
+			boxes, err := sb.tableRowBoxes(currentContent.table)
+			if err != nil {
+				return nil, 0, fmt.Errorf("boxing table: %w", err)
+			}
+			if len(boxes) == 0 {
+				continue
+			}
+			sb.Push(boxes[1:]...)
+			return boxes[0], 0, nil
+		}
+
 		if len(currentContent.children) > 0 {
 			sb.contentIndex++
 			sb.n = 0
@@ -356,6 +513,7 @@ func (sb *SimpleBoxer) Next() (Box, int, error) {
 					b = NewDecorationBox(b, currentContent.style.Padding, currentContent.style.Margin, bg, currentContent.style.BgPositioning)
 				}
 			}
+			// Decorators are also added by content options such as WithFloat, so are applied with or without a style
 			for i := len(currentContent.decorators) - 1; i >= 0; i-- {
 				b = currentContent.decorators[i](b)
 			}
@@ -405,9 +563,10 @@ func (sb *SimpleBoxer) Next() (Box, int, error) {
 					bg := currentContent.style.BackgroundColor
 					b = NewDecorationBox(b, currentContent.style.Padding, currentContent.style.Margin, bg, currentContent.style.BgPositioning)
 				}
-				for i := len(currentContent.decorators) - 1; i >= 0; i-- {
-					b = currentContent.decorators[i](b)
-				}
+			}
+			// Decorators are also added by content options such as WithFloat, so are applied with or without a style
+			for i := len(currentContent.decorators) - 1; i >= 0; i-- {
+				b = currentContent.decorators[i](b)
 			}
 			if currentContent.id != nil {
 				b = &IDBox{
@@ -420,33 +579,26 @@ func (sb *SimpleBoxer) Next() (Box, int, error) {
 			}
 			return b, 1, nil
 		}
-		text := []rune(currentContent.text)
+		sb.indexed()
+		text := sb.runes[sb.contentIndex]
 		if sb.n >= len(text) {
 			sb.contentIndex++
 			sb.n = 0
 			continue
 		}
-		n, rs, rmode := sb.Tokenizer(text[sb.n:])
+		mode := currentContent.whiteSpace()
+		var n int
+		var rs []rune
+		var rmode int
+		if mode.collapses() && unicode.IsSpace(text[sb.n]) {
+			// The run of white space, line breaks included, is a space
+			n, rs, rmode = collapseSpace(text[sb.n:]), []rune{' '}, RSimpleBox
+		} else {
+			n, rs, rmode = sb.Tokenizer(text[sb.n:])
+		}
 		sb.n += n
 		var b Box
-		drawer := sb.fontDrawer
-		if currentContent.style != nil {
-			if currentContent.style.font != nil {
-				drawer = &font.Drawer{
-					Src:  sb.fontDrawer.Src,
-					Face: currentContent.style.font,
-				}
-			}
-			if currentContent.style.FontDrawerSrc != nil {
-				if drawer == sb.fontDrawer {
-					drawer = &font.Drawer{
-						Src:  sb.fontDrawer.Src,
-						Face: sb.fontDrawer.Face,
-					}
-				}
-				drawer.Src = currentContent.style.FontDrawerSrc
-			}
-		}
+		drawer := sb.contentDrawer(currentContent)
 
 		switch rmode {
 		case RNIL:
@@ -462,45 +614,19 @@ func (sb *SimpleBoxer) Next() (Box, int, error) {
 		case RSimpleBox, RCRLF:
 			t := string(rs)
 			var err error
-			b, err = NewSimpleTextBox(drawer, t)
+			b, err = newSimpleTextBox(drawer, t, sb.measureCache)
 			if err != nil {
 				return nil, 0, err
 			}
 		default:
 			return nil, 0, fmt.Errorf("unknown rmode %d", rmode)
 		}
-		if currentContent.style != nil {
-			if currentContent.style.BackgroundColor != nil {
-				b = &BackgroundBox{
-					Box:           b,
-					Background:    currentContent.style.BackgroundColor,
-					BgPositioning: currentContent.style.BgPositioning,
-				}
-			}
-			if len(currentContent.style.Effects) > 0 {
-				b = &EffectBox{
-					Box:     b,
-					Effects: currentContent.style.Effects,
-				}
-			}
-			if currentContent.style.Alignment != AlignBaseline {
-				b = &AlignedBox{
-					Box:       b,
-					Alignment: currentContent.style.Alignment,
-				}
-			}
-			if !currentContent.style.Padding.Empty() || !currentContent.style.Margin.Empty() {
-				bg := currentContent.style.BackgroundColor
-				b = NewDecorationBox(b, currentContent.style.Padding, currentContent.style.Margin, bg, currentContent.style.BgPositioning)
-			}
-			for i := len(currentContent.decorators) - 1; i >= 0; i-- {
-				b = currentContent.decorators[i](b)
-			}
-		}
-		if currentContent.id != nil {
-			b = &IDBox{
-				Box: b,
-				id:  currentContent.id,
+		b = currentContent.decorateText(b)
+		if mode != WhiteSpacePreWrap {
+			b = &whiteSpaceBox{
+				Box:   b,
+				mode:  mode,
+				runes: n,
 			}
 		}
 		switch rmode {
@@ -649,6 +775,9 @@ type SimpleTextBox struct {
 	Advance  fixed.Int26_6
 	Metrics  font.Metrics
 	boxBox   bool
+	// hyphen is drawn after Contents, and measured in Bounds and Advance, where a word was broken. It isn't part of the
+	// text.
+	hyphen string
 }
 
 // NewSimpleTextBox constructor
@@ -672,9 +801,9 @@ func (sb *SimpleTextBox) TextValue() string {
 	return sb.Contents
 }
 
-// Len is the string length of the contents of the box
+// Len is the rune length of the contents of the box
 func (sb *SimpleTextBox) Len() int {
-	return len(sb.Contents)
+	return utf8.RuneCountInString(sb.Contents)
 }
 
 // FontDrawer font used
@@ -712,25 +841,85 @@ func (sb *SimpleTextBox) MaxSize() (fixed.Int26_6, fixed.Int26_6) {
 
 // DrawBox renders object
 func (sb *SimpleTextBox) DrawBox(i Image, y fixed.Int26_6, dc *DrawConfig) {
+	// Drawn with a copy so the shared drawer isn't modified, allowing boxes to be drawn concurrently
+	d := *sb.drawer
+	d.Face = dc.face(d.Face)
 	if dc.SourceImageMapper != nil {
-		originalSrc := sb.drawer.Src
-		sb.drawer.Src = dc.SourceImageMapper(originalSrc)
-		defer func() {
-			sb.drawer.Src = originalSrc
-		}()
+		d.Src = dc.SourceImageMapper(d.Src)
 	}
-	sb.drawer.Dst = i
+	d.Dst = i
 	b := i.Bounds()
-	sb.drawer.Dot = fixed.Point26_6{
+	d.Dot = fixed.Point26_6{
 		X: fixed.I(b.Min.X),
 		Y: fixed.I(b.Min.Y) + y,
 	}
-	sb.drawer.DrawString(sb.Contents)
+	drawString(&d, sb.drawer.Face, sb.Contents+sb.hyphen, dc)
 	if sb.boxBox {
 		DrawBox(i, b, dc)
 	}
 }
 
+// glyphEdges returns the x position of each rune boundary within the box, measured using the glyph advances and kerning
+// of the box's font face, as mapped by the draw config if there is one. The result has one more entry than there are
+// runes.
+func (sb *SimpleTextBox) glyphEdges(dc *DrawConfig) []fixed.Int26_6 {
+	rs := []rune(sb.Contents)
+	edges := make([]fixed.Int26_6, len(rs)+1)
+	if sb.drawer == nil || sb.drawer.Face == nil {
+		for i := range rs {
+			edges[i+1] = sb.Advance * fixed.Int26_6(i+1) / fixed.Int26_6(len(rs))
+		}
+		return edges
+	}
+	face := dc.face(sb.drawer.Face)
+	var x fixed.Int26_6
+	prev := rune(-1)
+	for i, r := range rs {
+		if prev >= 0 {
+			x += face.Kern(prev, r)
+		}
+		a, ok := face.GlyphAdvance(r)
+		if ok {
+			x += a
+		}
+		edges[i+1] = x
+		prev = r
+	}
+	return edges
+}
+
+// PrefixAdvance returns the width of the first n runes of the box
+func (sb *SimpleTextBox) PrefixAdvance(n int) fixed.Int26_6 {
+	return sb.prefixAdvance(n, nil)
+}
+
+// prefixAdvance is PrefixAdvance measured with the faces of the draw config
+func (sb *SimpleTextBox) prefixAdvance(n int, dc *DrawConfig) fixed.Int26_6 {
+	if n <= 0 {
+		return 0
+	}
+	edges := sb.glyphEdges(dc)
+	if n >= len(edges)-1 {
+		if sb.hyphen != "" {
+			// The hyphen isn't part of the text
+			return edges[len(edges)-1]
+		}
+		return sb.Advance
+	}
+	return edges[n]
+}
+
+// RuneOffsetAt returns the rune boundary nearest to x, where x is relative to the start of the box
+func (sb *SimpleTextBox) RuneOffsetAt(x fixed.Int26_6) int {
+	edges := sb.glyphEdges(nil)
+	for i := 1; i < len(edges); i++ {
+		if x < (edges[i-1]+edges[i])/2 {
+			return i - 1
+		}
+	}
+	return len(edges) - 1
+}
+
 // LineBreakBox represents a natural or an effective line break
 type LineBreakBox struct {
 	// Box is the box that linebreak contains if any
@@ -4,6 +4,8 @@ import (
 	"image"
 	"image/color"
 	"reflect"
+	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/arran4/golang-wordwrap/util"
@@ -353,3 +355,132 @@ func TestImageBoxMetricsInSimpleBoxer(t *testing.T) {
 		t.Errorf("Expected height to be 30, got %d", m.Height.Ceil())
 	}
 }
+
+func TestSimpleBoxer_PositionTracking(t *testing.T) {
+	fontFace := FontFace16DPI180ForTest(t)
+	drawer := &font.Drawer{Face: fontFace, Src: image.NewUniform(color.Black)}
+	var contents []*Content
+	total := 0
+	for i := 0; i < 50; i++ {
+		text := strings.Repeat("ab ", i%4)
+		contents = append(contents, &Content{text: text})
+		total += len(text)
+	}
+	contents = append(contents, &Content{}, &Content{})
+	boxer := NewSimpleBoxer(contents, drawer)
+
+	consumed := 0
+	for boxer.HasNext() {
+		if boxer.Pos() != consumed {
+			t.Fatalf("Pos() = %d, want %d", boxer.Pos(), consumed)
+		}
+		_, n, err := boxer.Next()
+		if err != nil {
+			t.Fatalf("Next failed: %v", err)
+		}
+		consumed += n
+	}
+	if consumed != total || boxer.Pos() != total {
+		t.Fatalf("consumed %d and Pos() %d, want %d", consumed, boxer.Pos(), total)
+	}
+
+	b, _, err := NewSimpleBoxer([]*Content{{text: "ab"}}, drawer).Next()
+	if err != nil {
+		t.Fatalf("Next failed: %v", err)
+	}
+	boxer.Push(b)
+	if boxer.Pos() != total-2 || !boxer.HasNext() {
+		t.Errorf("after Push Pos() = %d HasNext() = %v", boxer.Pos(), boxer.HasNext())
+	}
+	boxer.Shift()
+	if boxer.Pos() != total {
+		t.Errorf("after Shift Pos() = %d, want %d", boxer.Pos(), total)
+	}
+
+	for _, back := range []int{1, 7, 40} {
+		boxer.Back(back)
+		if boxer.Pos() != total-back {
+			t.Errorf("after Back(%d) Pos() = %d, want %d", back, boxer.Pos(), total-back)
+		}
+		rest := 0
+		for boxer.HasNext() {
+			_, n, err := boxer.Next()
+			if err != nil {
+				t.Fatalf("Next failed: %v", err)
+			}
+			rest += n
+		}
+		if rest != back {
+			t.Errorf("after Back(%d) read %d runes", back, rest)
+		}
+	}
+	boxer.Back(total + 10)
+	if boxer.Pos() != 0 {
+		t.Errorf("Back past the start Pos() = %d", boxer.Pos())
+	}
+}
+
+func benchmarkBoxing(b *testing.B, contents func() []*Content) {
+	gr, err := util.OpenFont("goregular")
+	if err != nil {
+		b.Fatalf("Error opening font: %s", err)
+	}
+	drawer := &font.Drawer{Face: util.GetFontFace(16, 75, gr), Src: image.NewUniform(color.Black)}
+	cs := contents()
+	mc := NewMeasureCache(0)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		boxer := NewSimpleBoxer(cs, drawer, mc)
+		for boxer.HasNext() {
+			if _, _, err := boxer.Next(); err != nil {
+				b.Fatalf("Next failed: %v", err)
+			}
+			_ = boxer.Pos()
+		}
+	}
+}
+
+func BenchmarkSimpleBoxer_PlainText1MB(b *testing.B) {
+	benchmarkBoxing(b, func() []*Content {
+		text := strings.Repeat("The quick brown fox jumps over the lazy dog. ", 1<<20/45)
+		return []*Content{{text: text}}
+	})
+}
+
+func BenchmarkSimpleBoxer_RichRuns10k(b *testing.B) {
+	benchmarkBoxing(b, func() []*Content {
+		contents := make([]*Content, 0, 10000)
+		for i := 0; i < 10000; i++ {
+			contents = append(contents, &Content{text: "run " + strconv.Itoa(i) + " "})
+		}
+		return contents
+	})
+}
+
+func TestSimpleTextBox_LenCountsRunes(t *testing.T) {
+	fontFace := FontFace16DPI180ForTest(t)
+	drawer := &font.Drawer{Face: fontFace, Src: image.NewUniform(color.Black)}
+	for _, text := range []string{"abc", "héllo", "日本語", "👍🏽"} {
+		b, err := NewSimpleTextBox(drawer, text)
+		if err != nil {
+			t.Fatalf("NewSimpleTextBox failed: %v", err)
+		}
+		if got, want := b.Len(), len([]rune(text)); got != want {
+			t.Errorf("Len() of %q = %d, want %d runes not %d bytes", text, got, want, len(text))
+		}
+	}
+	// Lengths add up to the boxer's position, which is in runes
+	boxer := NewSimpleBoxer([]*Content{{text: "héllo wörld"}}, drawer)
+	n := 0
+	for boxer.HasNext() {
+		b, _, err := boxer.Next()
+		if err != nil {
+			t.Fatalf("Next failed: %v", err)
+		}
+		n += b.Len()
+	}
+	if n != boxer.Pos() {
+		t.Errorf("box lengths add up to %d, Pos() = %d", n, boxer.Pos())
+	}
+}
@@ -0,0 +1,170 @@
+package wordwrap
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"golang.org/x/image/font"
+)
+
+// BoxList is the output of a Boxer recorded once so it can be folded any number of times, at any width or height,
+// without tokenizing or measuring the text again. The list isn't modified by folding, each fold reads it through its
+// own cursor from Boxer.
+type BoxList struct {
+	boxes []Box
+	// starts is the rune offset each box starts at
+	starts []int
+	// end is the rune offset after the last box
+	end        int
+	fontDrawer *font.Drawer
+}
+
+// NewBoxList records every remaining box from boxer
+func NewBoxList(boxer Boxer) (*BoxList, error) {
+	return newBoxList(context.Background(), boxer, 0)
+}
+
+// newBoxList records every remaining box from boxer, giving up if the context is done or there are more than maxBoxes
+// boxes when maxBoxes is set
+func newBoxList(ctx context.Context, boxer Boxer, maxBoxes int) (*BoxList, error) {
+	bl := &BoxList{
+		fontDrawer: boxer.FontDrawer(),
+	}
+	for boxer.HasNext() {
+		if len(bl.boxes)%1024 == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, fmt.Errorf("boxing box %d: %w", len(bl.boxes), err)
+			}
+		}
+		start := boxer.Pos()
+		b, n, err := boxer.Next()
+		if err != nil {
+			return nil, fmt.Errorf("boxing box %d: %w", len(bl.boxes), err)
+		}
+		if b == nil {
+			if n == 0 {
+				break
+			}
+			continue
+		}
+		bl.boxes = append(bl.boxes, b)
+		bl.starts = append(bl.starts, start)
+		if err := checkLimit("boxes", maxBoxes, len(bl.boxes)); err != nil {
+			return nil, err
+		}
+	}
+	bl.end = boxer.Pos()
+	return bl, nil
+}
+
+// Boxes the recorded boxes, which must not be modified
+func (bl *BoxList) Boxes() []Box {
+	return bl.boxes
+}
+
+// Len the number of boxes
+func (bl *BoxList) Len() int {
+	return len(bl.boxes)
+}
+
+// Boxer creates a new cursor over the list starting at the first box
+func (bl *BoxList) Boxer() *BoxListBoxer {
+	return &BoxListBoxer{
+		list:       bl,
+		fontDrawer: bl.fontDrawer,
+	}
+}
+
+// BoxListBoxer is a Boxer that reads from a BoxList. Boxes pushed back on to it are held by the cursor, not the list.
+type BoxListBoxer struct {
+	list       *BoxList
+	i          int
+	queue      []Box
+	fontDrawer *font.Drawer
+}
+
+// Ensures that BoxListBoxer fits model
+var _ Boxer = (*BoxListBoxer)(nil)
+
+// index the index of the next box that will be returned
+func (sb *BoxListBoxer) index() int {
+	return sb.i - len(sb.queue)
+}
+
+// Next returns the next box
+func (sb *BoxListBoxer) Next() (Box, int, error) {
+	if len(sb.queue) > 0 {
+		return sb.Shift(), 0, nil
+	}
+	if sb.i >= len(sb.list.boxes) {
+		return nil, 0, nil
+	}
+	b := sb.list.boxes[sb.i]
+	sb.i++
+	return b, b.Len(), nil
+}
+
+// SetFontDrawer Changes the default font
+func (sb *BoxListBoxer) SetFontDrawer(face *font.Drawer) {
+	sb.fontDrawer = face
+}
+
+// FontDrawer the default font
+func (sb *BoxListBoxer) FontDrawer() *font.Drawer {
+	return sb.fontDrawer
+}
+
+// Back goes back to the box containing the rune i runes before the current position
+func (sb *BoxListBoxer) Back(i int) {
+	pos := sb.Pos() - i
+	starts := sb.list.starts
+	sb.queue = nil
+	sb.i = sort.Search(len(starts), func(n int) bool { return starts[n] > pos }) - 1
+	if sb.i < 0 {
+		sb.i = 0
+	}
+}
+
+// HasNext are there more boxes
+func (sb *BoxListBoxer) HasNext() bool {
+	return len(sb.queue) > 0 || sb.i < len(sb.list.boxes)
+}
+
+// Push puts a box back on to the cache stack
+func (sb *BoxListBoxer) Push(box ...Box) {
+	sb.queue = append(sb.queue, box...)
+}
+
+// Pos the rune offset of the next box
+func (sb *BoxListBoxer) Pos() int {
+	pos := sb.list.end
+	if sb.i < len(sb.list.starts) {
+		pos = sb.list.starts[sb.i]
+	}
+	for _, e := range sb.queue {
+		pos -= e.Len()
+	}
+	return pos
+}
+
+// Unshift basically is Push but to the start
+func (sb *BoxListBoxer) Unshift(box ...Box) {
+	sb.queue = append(append(make([]Box, 0, len(box)+len(sb.queue)), box...), sb.queue...)
+}
+
+// Shift removes the first box of the cache
+func (sb *BoxListBoxer) Shift() Box {
+	if len(sb.queue) > 0 {
+		cb := sb.queue[0]
+		sb.queue = sb.queue[1:]
+		return cb
+	}
+	return nil
+}
+
+// Reset restarts from the first box
+func (sb *BoxListBoxer) Reset() {
+	sb.i = 0
+	sb.queue = nil
+}
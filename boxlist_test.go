@@ -0,0 +1,106 @@
+package wordwrap
+
+import (
+	"image"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func lineTexts(ls []Line) []string {
+	r := make([]string, 0, len(ls))
+	for _, l := range ls {
+		r = append(r, l.TextValue())
+	}
+	return r
+}
+
+func TestBoxList_FoldsLikeBoxer(t *testing.T) {
+	fontFace := FontFace16DPI180ForTest(t)
+	text := strings.Repeat("The quick brown fox jumps over the lazy dog. ", 10)
+	bl, err := NewBoxList(NewRichBoxer(fontFace, text))
+	if err != nil {
+		t.Fatalf("NewBoxList failed: %v", err)
+	}
+	for _, width := range []int{150, 300, 600} {
+		r := image.Rect(0, 0, width, 10000)
+		want, _, err := NewRichWrapper(fontFace, text).TextToRect(r)
+		if err != nil {
+			t.Fatalf("TextToRect failed: %v", err)
+		}
+		sw := NewRichWrapper(fontFace, bl.Boxer())
+		got, _, err := sw.TextToRect(r)
+		if err != nil {
+			t.Fatalf("TextToRect from box list failed: %v", err)
+		}
+		if s := cmp.Diff(lineTexts(want), lineTexts(got)); s != "" {
+			t.Errorf("width %d lines differ:\n%s", width, s)
+		}
+		for i := range got {
+			if w, g := linePositionStats(want[i]).RuneOffset, linePositionStats(got[i]).RuneOffset; w != g {
+				t.Errorf("width %d line %d RuneOffset = %d, want %d", width, i, g, w)
+			}
+		}
+	}
+}
+
+func TestTextToSpecs_BoxesOnce(t *testing.T) {
+	fontFace := FontFace16DPI180ForTest(t)
+	calls := 0
+	tokenizer := Tokenizer(func(text []rune) (int, []rune, int) {
+		calls++
+		return LatinTokenizer(text)
+	})
+	text := strings.Repeat("The quick brown fox jumps over the lazy dog. ", 10)
+	sw := NewRichWrapper(fontFace, text, tokenizer)
+	lr, err := sw.TextToSpecs(Width(Fixed(300)), Height(Fixed(100)))
+	if err != nil {
+		t.Fatalf("TextToSpecs failed: %v", err)
+	}
+	want, _, err := NewRichWrapper(fontFace, text).TextToRect(image.Rect(0, 0, 300, 100))
+	if err != nil {
+		t.Fatalf("TextToRect failed: %v", err)
+	}
+	if s := cmp.Diff(lineTexts(want), lineTexts(lr.Lines)); s != "" {
+		t.Errorf("lines differ:\n%s", s)
+	}
+	once := calls
+	calls = 0
+	if _, _, err := NewRichWrapper(fontFace, text, tokenizer).TextToRect(image.Rect(0, 0, 300, 10000)); err != nil {
+		t.Fatalf("TextToRect failed: %v", err)
+	}
+	if once != calls {
+		t.Errorf("TextToSpecs tokenized %d times, a single pass tokenizes %d times", once, calls)
+	}
+}
+
+func TestTextToSpecs_ThenTextToRect(t *testing.T) {
+	fontFace := FontFace16DPI180ForTest(t)
+	text := strings.Repeat("The quick brown fox jumps over the lazy dog. ", 10)
+	sw := NewRichWrapper(fontFace, text)
+	if _, err := sw.TextToSpecs(Width(Fixed(300)), Height(Fixed(100))); err != nil {
+		t.Fatalf("TextToSpecs failed: %v", err)
+	}
+	if _, ok := sw.boxer.(*BoxListBoxer); ok {
+		t.Fatalf("TextToSpecs left the wrapper boxing from its box list")
+	}
+	got, _, err := sw.TextToRect(image.Rect(0, 0, 300, 100))
+	if err != nil {
+		t.Fatalf("TextToRect failed: %v", err)
+	}
+	ref := NewRichWrapper(fontFace, text)
+	if _, _, err := ref.TextToRect(image.Rect(0, 0, 300, 100)); err != nil {
+		t.Fatalf("TextToRect failed: %v", err)
+	}
+	want, _, err := ref.TextToRect(image.Rect(0, 0, 300, 100))
+	if err != nil {
+		t.Fatalf("TextToRect failed: %v", err)
+	}
+	if len(want) == 0 {
+		t.Fatalf("expected a second page")
+	}
+	if s := cmp.Diff(lineTexts(want), lineTexts(got)); s != "" {
+		t.Errorf("TextToRect after TextToSpecs doesn't carry on from the page it laid out:\n%s", s)
+	}
+}
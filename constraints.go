@@ -1,6 +1,7 @@
 package wordwrap
 
 import (
+	"context"
 	"fmt"
 	"image"
 	"image/color"
@@ -20,6 +21,9 @@ type SpecConfig struct {
 	HeightFn       SizeFunction
 	Margin         SpecMargin
 	PageBackground color.Color
+	// Headers and Footers are the variants of the header and footer, the band for each page being picked by bandFor
+	Headers []PageBand
+	Footers []PageBand
 }
 
 type SpecMargin struct {
@@ -137,11 +141,79 @@ type LayoutResult struct {
 	ContentStart   image.Point
 	Margin         SpecMargin
 	PageBackground color.Color
+	// ContentSize is the size of the area the lines were laid out in, the page less the margin, header and footer
+	ContentSize image.Point
+	// Page is the page number, counting from 1
+	Page int
+	// Header and Footer are the header and footer of the page, nil if it has none
+	Header *BandLayout
+	Footer *BandLayout
+	// wrapper that produced the lines, used to position them
+	wrapper *SimpleWrapper
+}
+
+// Render draws the lines, header and footer of the page onto i, which is the page
+func (lr *LayoutResult) Render(i Image, options ...DrawOption) error {
+	origin := i.Bounds().Min
+	content := image.Rectangle{Min: lr.ContentStart, Max: lr.ContentStart.Add(lr.ContentSize)}.Add(origin)
+	sw := lr.wrapper
+	if sw == nil {
+		sw = &SimpleWrapper{}
+	}
+	if err := sw.RenderLines(i.SubImage(content).(Image), lr.Lines, content.Min, options...); err != nil {
+		return err
+	}
+	for _, bl := range []*BandLayout{lr.Header, lr.Footer} {
+		if bl == nil {
+			continue
+		}
+		if err := bl.Render(i, options...); err != nil {
+			return fmt.Errorf("drawing band: %w", err)
+		}
+	}
+	return nil
 }
 
 // TextToSpecs performs layout based on complex constraints.
 // It returns the layout result containing lines, page size, and offsets.
 func (sw *SimpleWrapper) TextToSpecs(opts ...SpecOption) (*LayoutResult, error) {
+	return sw.TextToSpecsContext(context.Background(), opts...)
+}
+
+// TextToSpecsContext is TextToSpecs which gives up with the context's error if it is done before the layout is
+// finished. The context is checked while boxing and between lines.
+func (sw *SimpleWrapper) TextToSpecsContext(ctx context.Context, opts ...SpecOption) (*LayoutResult, error) {
+	// The text is laid out from the start, so only this call's pages count
+	sw.pageCount = 0
+	if err := sw.startPage(); err != nil {
+		return nil, err
+	}
+	pages, err := sw.textToSpecs(ctx, false, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return pages[0], nil
+}
+
+// TextToSpecPages lays out the whole text as pages the size TextToSpecs picks for the first page, each with the header
+// and footer for it. The height needs to be bounded, such as with Height(Fixed(n)), for there to be more than one page.
+func (sw *SimpleWrapper) TextToSpecPages(opts ...SpecOption) ([]*LayoutResult, error) {
+	return sw.TextToSpecPagesContext(context.Background(), opts...)
+}
+
+// TextToSpecPagesContext is TextToSpecPages which gives up with the context's error if it is done before the layout is
+// finished. Each page counts against the page limit.
+func (sw *SimpleWrapper) TextToSpecPagesContext(ctx context.Context, opts ...SpecOption) ([]*LayoutResult, error) {
+	// The text is laid out from the start, so only this call's pages count
+	sw.pageCount = 0
+	if err := sw.startPage(); err != nil {
+		return nil, err
+	}
+	return sw.textToSpecs(ctx, true, opts...)
+}
+
+// textToSpecs lays out the first page, or every page if paginate is set
+func (sw *SimpleWrapper) textToSpecs(ctx context.Context, paginate bool, opts ...SpecOption) ([]*LayoutResult, error) {
 	config := SpecConfig{
 		WidthFn:  Unbounded(),
 		HeightFn: Unbounded(),
@@ -149,11 +221,30 @@ func (sw *SimpleWrapper) TextToSpecs(opts ...SpecOption) (*LayoutResult, error)
 	for _, opt := range opts {
 		opt.ApplySpec(&config)
 	}
+	marginH := config.Margin.Left + config.Margin.Right
+	marginV := config.Margin.Top + config.Margin.Bottom
+	// The smallest page the sizes allow, before any work is done laying out
+	if err := sw.checkPixelArea(max(config.WidthFn(marginH), marginH+1), max(config.HeightFn(marginV), marginV+1)); err != nil {
+		return nil, err
+	}
 
-	sw.boxer.Reset()
+	// Box once, every pass below folds the same boxes
+	sw.restart()
+	sw.fields.pageCountUsed = false
+	bl, err := newBoxList(ctx, sw.boxer, sw.limits.MaxBoxes)
+	if err != nil {
+		return nil, fmt.Errorf("boxing failed: %w", err)
+	}
+	// The passes fold from a cursor over the list, the wrapper's own boxer is left where the last pass stopped
+	boxer, cursor := sw.boxer, bl.Boxer()
+	sw.boxer = cursor
+	defer func() {
+		sw.boxer = boxer
+		boxer.Back(bl.end - cursor.Pos())
+	}()
 
 	inf := 1000000
-	lines, _, err := sw.TextToRect(image.Rect(0, 0, inf, inf))
+	lines, _, err := sw.textToRect(ctx, image.Rect(0, 0, inf, inf))
 	if err != nil {
 		return nil, fmt.Errorf("measure pass failed: %w", err)
 	}
@@ -164,9 +255,8 @@ func (sw *SimpleWrapper) TextToSpecs(opts ...SpecOption) (*LayoutResult, error)
 			naturalContentWidth = s.Dx()
 		}
 	}
-
-	marginH := config.Margin.Left + config.Margin.Right
-	marginV := config.Margin.Top + config.Margin.Bottom
+	// The gutter is part of the content
+	naturalContentWidth += sw.gutterWidth()
 
 	// Pass 2: Layout with width constraints and unconstrained height
 	targetPageWidth := config.WidthFn(naturalContentWidth + marginH)
@@ -175,36 +265,133 @@ func (sw *SimpleWrapper) TextToSpecs(opts ...SpecOption) (*LayoutResult, error)
 	}
 	targetContentWidth := targetPageWidth - marginH
 
+	sw.restart()
+	header, footer, err := sw.layoutBands(ctx, &config, 1, targetContentWidth)
+	if err != nil {
+		return nil, err
+	}
+	bandsV := header.Height() + footer.Height()
+
 	// We use a large height for layout to detect natural height after wrapping
 	// Then we apply HeightFn to constraint the final PageSize
 	layoutHeight := 1000000
 
-	sw.boxer.Reset()
-	lines2, p, err := sw.TextToRect(image.Rect(0, 0, targetContentWidth, layoutHeight))
+	sw.restart()
+	lines2, p, err := sw.textToRect(ctx, image.Rect(0, 0, targetContentWidth, layoutHeight))
 	if err != nil {
 		return nil, fmt.Errorf("layout pass failed: %w", err)
 	}
 	naturalContentHeight := p.Y
 
-	targetPageHeight := config.HeightFn(naturalContentHeight + marginV)
-	if targetPageHeight < marginV+1 {
-		targetPageHeight = marginV + 1
+	targetPageHeight := config.HeightFn(naturalContentHeight + marginV + bandsV)
+	if targetPageHeight < marginV+bandsV+1 {
+		targetPageHeight = marginV + bandsV + 1
+	}
+	targetContentHeight := targetPageHeight - marginV - bandsV
+	if err := sw.checkPixelArea(targetPageWidth, targetPageHeight); err != nil {
+		return nil, err
+	}
+	pageSize := image.Point{X: targetPageWidth, Y: targetPageHeight}
+
+	if !paginate {
+		if targetContentHeight < naturalContentHeight {
+			sw.restart()
+			lines2, _, err = sw.textToRect(ctx, image.Rect(0, 0, targetContentWidth, targetContentHeight))
+			if err != nil {
+				return nil, fmt.Errorf("final layout pass failed: %w", err)
+			}
+		}
+		return []*LayoutResult{sw.specPage(&config, 1, lines2, pageSize, header, footer)}, nil
+	}
+
+	pageCount := sw.pageCount
+	pages, err := sw.paginate(ctx, &config, pageSize, targetContentWidth)
+	if err != nil || sw.fields.values.Pages > 0 || !sw.fields.pageCountUsed {
+		return pages, err
+	}
+	// Page count fields were laid out before the number of pages was known, so the pages are laid out again with it
+	// until it stops changing
+	defer func() {
+		sw.fields.values.Pages = 0
+	}()
+	for pass := 0; pass < 3 && sw.fields.values.Pages != len(pages); pass++ {
+		sw.fields.values.Pages = len(pages)
+		sw.pageCount = pageCount
+		if pages, err = sw.paginate(ctx, &config, pageSize, targetContentWidth); err != nil {
+			return nil, err
+		}
 	}
-	targetContentHeight := targetPageHeight - marginV
+	return pages, nil
+}
 
-	if targetContentHeight < naturalContentHeight {
-		sw.boxer.Reset()
-		lines2, _, err = sw.TextToRect(image.Rect(0, 0, targetContentWidth, targetContentHeight))
+// paginate lays out the text from the start as pages of the size, each with its header and footer
+func (sw *SimpleWrapper) paginate(ctx context.Context, config *SpecConfig, pageSize image.Point, contentWidth int) ([]*LayoutResult, error) {
+	m := config.Margin
+	sw.restart()
+	var pages []*LayoutResult
+	for page := 1; page == 1 || sw.HasNext(); page++ {
+		if page > 1 {
+			if err := sw.startPage(); err != nil {
+				return nil, err
+			}
+		}
+		header, footer, err := sw.layoutBands(ctx, config, page, contentWidth)
+		if err != nil {
+			return nil, err
+		}
+		contentHeight := pageSize.Y - m.Top - m.Bottom - header.Height() - footer.Height()
+		ls, _, err := sw.textToRect(ctx, image.Rect(0, 0, contentWidth, max(contentHeight, 1)))
 		if err != nil {
-			return nil, fmt.Errorf("final layout pass failed: %w", err)
+			return nil, fmt.Errorf("laying out page %d: %w", page, err)
 		}
+		if len(ls) == 0 && sw.HasNext() {
+			return nil, fmt.Errorf("laying out page %d: %w", page, ErrNoProgress)
+		}
+		pages = append(pages, sw.specPage(config, page, ls, pageSize, header, footer))
 	}
+	return pages, nil
+}
 
+// layoutBands lays out the header and footer for the page, which the wrapper is at the top of
+func (sw *SimpleWrapper) layoutBands(ctx context.Context, config *SpecConfig, page int, width int) (*BandLayout, *BandLayout, error) {
+	section, err := sw.pageSection()
+	if err != nil {
+		return nil, nil, fmt.Errorf("boxing section: %w", err)
+	}
+	header, err := sw.layoutBand(ctx, bandFor(config.Headers, page), page, section, width)
+	if err != nil {
+		return nil, nil, fmt.Errorf("header: %w", err)
+	}
+	footer, err := sw.layoutBand(ctx, bandFor(config.Footers, page), page, section, width)
+	if err != nil {
+		return nil, nil, fmt.Errorf("footer: %w", err)
+	}
+	return header, footer, nil
+}
+
+// specPage places the lines, header and footer of a page within its margin
+func (sw *SimpleWrapper) specPage(config *SpecConfig, page int, ls []Line, pageSize image.Point, header, footer *BandLayout) *LayoutResult {
+	m := config.Margin
+	contentStart := image.Point{X: m.Left, Y: m.Top + header.Height()}
+	if header != nil {
+		header.Rect = header.Rect.Add(image.Pt(m.Left, m.Top))
+	}
+	if footer != nil {
+		footer.Rect = footer.Rect.Add(image.Pt(m.Left, pageSize.Y-m.Bottom-footer.Height()))
+	}
 	return &LayoutResult{
-		Lines:          lines2,
-		PageSize:       image.Point{X: targetPageWidth, Y: targetPageHeight},
-		ContentStart:   image.Point{X: config.Margin.Left, Y: config.Margin.Top},
-		Margin:         config.Margin,
+		Lines:          ls,
+		PageSize:       pageSize,
+		ContentStart:   contentStart,
+		Margin:         m,
 		PageBackground: config.PageBackground,
-	}, nil
+		ContentSize: image.Point{
+			X: pageSize.X - m.Left - m.Right,
+			Y: pageSize.Y - m.Top - m.Bottom - header.Height() - footer.Height(),
+		},
+		Page:    page,
+		Header:  header,
+		Footer:  footer,
+		wrapper: sw,
+	}
 }
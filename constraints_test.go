@@ -1,6 +1,7 @@
 package wordwrap
 
 import (
+	"bytes"
 	"image"
 	"image/color"
 	"testing"
@@ -132,6 +133,30 @@ func TestSimpleWrapper_TextToSpecs(t *testing.T) {
 	}
 }
 
+func TestLayoutResult_Render(t *testing.T) {
+	font := FontFace16DPI180ForTest(t)
+	res, err := NewSimpleWrapper([]*Content{{text: "Hello World"}}, font).TextToSpecs()
+	if err != nil {
+		t.Fatalf("TextToSpecs failed: %v", err)
+	}
+	want := image.NewRGBA(image.Rectangle{Max: res.PageSize})
+	if err := res.Render(want); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+
+	t.Run("Without a wrapper", func(t *testing.T) {
+		lr := *res
+		lr.wrapper = nil
+		got := image.NewRGBA(image.Rectangle{Max: lr.PageSize})
+		if err := lr.Render(got); err != nil {
+			t.Fatalf("Render failed: %v", err)
+		}
+		if !bytes.Equal(got.Pix, want.Pix) {
+			t.Errorf("expected the same drawing as with the wrapper")
+		}
+	})
+}
+
 func TestSizeFunctions(t *testing.T) {
 	n := 100
 
@@ -3,6 +3,7 @@ package wordwrap
 import (
 	"image"
 	"image/color"
+	"unicode/utf8"
 
 	"golang.org/x/image/font"
 	"golang.org/x/image/math/fixed"
@@ -27,6 +28,11 @@ type Content struct {
 	imageScale float64
 	decorators []func(Box) Box
 	children   []*Content
+	table      *tableContent
+	list       *listContent
+	footnote   *footnoteContent
+	field      *FieldContent
+	section    *sectionContent
 }
 
 // Style defines the visual properties of content.
@@ -45,6 +51,7 @@ type Style struct {
 	BorderImage     image.Image
 	Decorators      []func(Box) Box
 	MinSize         fixed.Point26_6
+	WhiteSpace      WhiteSpace
 }
 
 // WithMinSize sets the minimum size of the content
@@ -179,6 +186,29 @@ func (c *Content) ID() interface{} {
 	return c.id
 }
 
+// runeLen the number of runes the content occupies in the source, including that of any children
+func (c *Content) runeLen() int {
+	n := utf8.RuneCountInString(c.text)
+	for _, child := range c.children {
+		n += child.runeLen()
+	}
+	if c.table != nil {
+		n += c.table.runeLen()
+	}
+	if c.list != nil {
+		n += c.list.runeLen()
+	}
+	if c.footnote != nil {
+		n += c.footnote.runeLen()
+	}
+	return n
+}
+
+// boxedWhole true if the content is boxed as a whole rather than from its text
+func (c *Content) boxedWhole() bool {
+	return len(c.children) > 0 || c.image != nil || c.table != nil || c.list != nil || c.footnote != nil || c.field != nil || c.section != nil
+}
+
 // WithFixedBackground sets whether the background is "fixed" (global coordinates)
 func WithFixedBackground(fixed bool) ContentOption {
 	return func(c *Content) {
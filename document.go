@@ -0,0 +1,403 @@
+package wordwrap
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Document is an editable sequence of contents laid out to a fixed width. Edits re-box only the contents they touch and
+// re-fold lines only until the line breaks line up with the previous layout again.
+type Document struct {
+	sw        *SimpleWrapper
+	tokenizer Tokenizer
+	width     int
+	contents  []*Content
+	// length is the rune length of the contents when they were last boxed
+	length int
+	// boxes and boxStarts hold the boxes of every content in order and the rune offset each starts at
+	boxes     []Box
+	boxStarts []int
+	// contentBoxes is the number of boxes produced by each content
+	contentBoxes []int
+	lines        []Line
+	// lineStarts is the index into boxes that each line starts at
+	lineStarts []int
+}
+
+// DocumentChange describes which lines an edit replaced
+type DocumentChange struct {
+	// Start is the index of the first line that was re-folded
+	Start int
+	// Removed is the number of lines of the previous layout that were replaced
+	Removed int
+	// Inserted is the number of lines that replaced them
+	Inserted int
+}
+
+// ErrEditInContainer is returned when an edit falls inside content which can't be edited as text, such as a container,
+// table, list or footnote
+var ErrEditInContainer = errors.New("edit inside container content")
+
+// NewDocument creates a document laid out to the given width. Accepts the same arguments as NewRichWrapper.
+func NewDocument(width int, args ...interface{}) (*Document, error) {
+	contents, drawer, wrapperOptions, boxerOptions, _, tokenizer := ProcessRichArgs(args...)
+	sw := &SimpleWrapper{
+		fontDrawer:    drawer,
+		wrapperConfig: wrapperConfig{boxerOptions: boxerOptions},
+	}
+	sw.ApplyOptions(wrapperOptions...)
+	d := &Document{
+		sw:        sw,
+		tokenizer: tokenizer,
+		width:     width,
+		contents:  contents,
+	}
+	cs := 0
+	for _, c := range contents {
+		boxes, starts, err := d.boxContent(c, cs)
+		if err != nil {
+			return nil, err
+		}
+		d.boxes = append(d.boxes, boxes...)
+		d.boxStarts = append(d.boxStarts, starts...)
+		d.contentBoxes = append(d.contentBoxes, len(boxes))
+		cs += c.runeLen()
+	}
+	d.length = cs
+	lines, starts, _, err := d.fold(0, -1, 0)
+	if err != nil {
+		return nil, err
+	}
+	d.lines = lines
+	d.lineStarts = starts
+	d.restat(0)
+	return d, nil
+}
+
+// Lines returns the current layout
+func (d *Document) Lines() []Line {
+	return d.lines
+}
+
+// Contents returns the contents of the document
+func (d *Document) Contents() []*Content {
+	return d.contents
+}
+
+// Text returns the text of the document, including that of its tables, lists and footnotes, so rune offsets such as
+// those of a Selection index into it
+func (d *Document) Text() string {
+	var sb strings.Builder
+	for _, c := range d.contents {
+		c.writeText(&sb)
+	}
+	return sb.String()
+}
+
+// RenderLines draws the document's lines, see SimpleWrapper.RenderLines
+func (d *Document) RenderLines(i Image, at image.Point, options ...DrawOption) error {
+	return d.sw.RenderLines(i, d.lines, at, options...)
+}
+
+// Insert adds text at the rune offset. Text inserted on the boundary of two contents takes the style of the first.
+func (d *Document) Insert(offset int, text string) (*DocumentChange, error) {
+	ci, cs, err := d.contentAt(offset)
+	if err != nil {
+		return nil, err
+	}
+	c := d.contents[ci]
+	rs := []rune(c.text)
+	c.text = string(rs[:offset-cs]) + text + string(rs[offset-cs:])
+	return d.rebox(ci, ci)
+}
+
+// Delete removes the runes from start (inclusive) to end (exclusive)
+func (d *Document) Delete(start, end int) (*DocumentChange, error) {
+	if start >= end {
+		return &DocumentChange{}, nil
+	}
+	if start < 0 || end > d.textLen() {
+		return nil, fmt.Errorf("delete %d-%d out of range", start, end)
+	}
+	// Checked before anything is deleted so a failed delete leaves the document as it was
+	cs := 0
+	for _, c := range d.contents {
+		l := c.runeLen()
+		if l > 0 && cs+l > start && cs < end && !c.editable() {
+			return nil, ErrEditInContainer
+		}
+		cs += l
+	}
+	first, last := -1, -1
+	cs = 0
+	for ci, c := range d.contents {
+		l := c.runeLen()
+		ce := cs + l
+		if l > 0 && ce > start && cs < end {
+			rs := []rune(c.text)
+			from, to := start-cs, end-cs
+			if from < 0 {
+				from = 0
+			}
+			if to > l {
+				to = l
+			}
+			c.text = string(rs[:from]) + string(rs[to:])
+			if first == -1 {
+				first = ci
+			}
+			last = ci
+		}
+		cs = ce
+	}
+	return d.rebox(first, last)
+}
+
+// textLen the total rune length of the document
+func (d *Document) textLen() int {
+	n := 0
+	for _, c := range d.contents {
+		n += c.runeLen()
+	}
+	return n
+}
+
+// contentAt finds the text content an insert at offset should go into, and the offset that content starts at
+func (d *Document) contentAt(offset int) (int, int, error) {
+	cs := 0
+	found := -1
+	foundStart := 0
+	for ci, c := range d.contents {
+		if offset < cs {
+			break
+		}
+		l := c.runeLen()
+		if offset <= cs+l {
+			if !c.editable() {
+				if offset > cs && offset < cs+l {
+					return 0, 0, ErrEditInContainer
+				}
+			} else if found == -1 {
+				found = ci
+				foundStart = cs
+			}
+		}
+		cs += l
+	}
+	if found == -1 {
+		if offset < 0 || offset > d.textLen() {
+			return 0, 0, fmt.Errorf("no text content at offset %d", offset)
+		}
+		// There is no text to insert into at the offset so a text content is made for it
+		return d.addContent(offset), offset, nil
+	}
+	return found, foundStart, nil
+}
+
+// addContent puts an empty text content in at the rune offset, which falls between contents, returning its index
+func (d *Document) addContent(offset int) int {
+	ci, cs := 0, 0
+	for ci < len(d.contents) && cs < offset {
+		cs += d.contents[ci].runeLen()
+		ci++
+	}
+	d.contents = append(d.contents[:ci], append([]*Content{NewContent("")}, d.contents[ci:]...)...)
+	d.contentBoxes = append(d.contentBoxes[:ci], append([]int{0}, d.contentBoxes[ci:]...)...)
+	return ci
+}
+
+// boxContent boxes a single content which starts at the rune offset start
+func (d *Document) boxContent(c *Content, start int) ([]Box, []int, error) {
+	sb := NewSimpleBoxer([]*Content{c}, d.sw.fontDrawer, d.sw.boxerOptions...)
+	if d.tokenizer != nil {
+		sb.Tokenizer = d.tokenizer
+	}
+	var boxes []Box
+	var starts []int
+	for sb.HasNext() {
+		b, _, err := sb.Next()
+		if err != nil {
+			return nil, nil, err
+		}
+		if b == nil {
+			continue
+		}
+		boxes = append(boxes, b)
+		starts = append(starts, start+sb.Pos()-b.Len())
+	}
+	return boxes, starts, nil
+}
+
+// rebox re-boxes the contents first to last, whose text changed, then re-folds the affected lines.
+func (d *Document) rebox(first, last int) (*DocumentChange, error) {
+	if first == -1 {
+		return &DocumentChange{}, nil
+	}
+	boxFrom := 0
+	for ci := 0; ci < first; ci++ {
+		boxFrom += d.contentBoxes[ci]
+	}
+	oldCount := 0
+	for ci := first; ci <= last; ci++ {
+		oldCount += d.contentBoxes[ci]
+	}
+	cs := 0
+	for ci := 0; ci < first; ci++ {
+		cs += d.contents[ci].runeLen()
+	}
+	var newBoxes []Box
+	var newStarts []int
+	for ci := first; ci <= last; ci++ {
+		boxes, starts, err := d.boxContent(d.contents[ci], cs)
+		if err != nil {
+			return nil, err
+		}
+		newBoxes = append(newBoxes, boxes...)
+		newStarts = append(newStarts, starts...)
+		d.contentBoxes[ci] = len(boxes)
+		cs += d.contents[ci].runeLen()
+	}
+	length := d.textLen()
+	runeDelta := length - d.length
+	d.length = length
+	// Boxes at either end of the edited contents that haven't changed are kept, so only the lines holding the
+	// changed boxes are re-folded
+	oldBoxes := d.boxes[boxFrom : boxFrom+oldCount]
+	oldStarts := d.boxStarts[boxFrom : boxFrom+oldCount]
+	prefix := 0
+	for prefix < len(oldBoxes) && prefix < len(newBoxes) && oldStarts[prefix] == newStarts[prefix] && sameBox(oldBoxes[prefix], newBoxes[prefix]) {
+		newBoxes[prefix] = oldBoxes[prefix]
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(oldBoxes)-prefix && suffix < len(newBoxes)-prefix {
+		oi, ni := len(oldBoxes)-1-suffix, len(newBoxes)-1-suffix
+		if oldStarts[oi]+runeDelta != newStarts[ni] || !sameBox(oldBoxes[oi], newBoxes[ni]) {
+			break
+		}
+		newBoxes[ni] = oldBoxes[oi]
+		suffix++
+	}
+	tailStarts := d.boxStarts[boxFrom+oldCount:]
+	for i := range tailStarts {
+		tailStarts[i] += runeDelta
+	}
+	d.boxes = append(append(append([]Box{}, d.boxes[:boxFrom]...), newBoxes...), d.boxes[boxFrom+oldCount:]...)
+	d.boxStarts = append(append(append([]int{}, d.boxStarts[:boxFrom]...), newStarts...), tailStarts...)
+	boxDelta := len(newBoxes) - oldCount
+
+	// The line before the edit is re-folded too, as the edited word may now fit at its end
+	startLine := sort.Search(len(d.lineStarts), func(i int) bool { return d.lineStarts[i] > boxFrom+prefix }) - 2
+	if startLine < 0 {
+		startLine = 0
+	}
+	from := 0
+	if startLine < len(d.lineStarts) {
+		from = d.lineStarts[startLine]
+	}
+	lines, starts, resync, err := d.fold(from, boxFrom+len(newBoxes)-suffix, boxDelta)
+	if err != nil {
+		return nil, err
+	}
+	for i := resync; i < len(d.lineStarts); i++ {
+		d.lineStarts[i] += boxDelta
+	}
+	change := &DocumentChange{
+		Start:    startLine,
+		Removed:  resync - startLine,
+		Inserted: len(lines),
+	}
+	d.lines = append(append(append([]Line{}, d.lines[:startLine]...), lines...), d.lines[resync:]...)
+	d.lineStarts = append(append(append([]int{}, d.lineStarts[:startLine]...), starts...), d.lineStarts[resync:]...)
+	d.restat(startLine)
+	return change, nil
+}
+
+// fold folds the boxes from the index from into lines, returning them with the box index each starts at. Once past
+// the index stable, folding stops at the first line that starts where a line of the previous layout started (offset by
+// delta boxes) and the index of that previous line is returned. Otherwise, including when stable is -1, everything is
+// folded and the number of previous lines is returned.
+func (d *Document) fold(from int, stable int, delta int) ([]Line, []int, int, error) {
+	sb := (&BoxList{boxes: d.boxes, starts: d.boxStarts, end: d.length}).Boxer()
+	sb.SetFontDrawer(d.sw.fontDrawer)
+	sb.i = from
+	sf := NewSimpleFolder(sb, image.Rect(0, 0, d.width, math.MaxInt32), d.sw.fontDrawer, d.sw.folderOptions...)
+	var lines []Line
+	var starts []int
+	for sb.HasNext() {
+		start := sb.index()
+		if stable >= 0 && start >= stable && len(lines) > 0 {
+			i := sort.SearchInts(d.lineStarts, start-delta)
+			if i < len(d.lineStarts) && d.lineStarts[i] == start-delta {
+				return lines, starts, i, nil
+			}
+		}
+		l, err := sf.Next(math.MaxInt32)
+		if err != nil {
+			return nil, nil, 0, fmt.Errorf("folding line %d: %w", len(lines), err)
+		}
+		if l == nil {
+			break
+		}
+		lines = append(lines, l)
+		starts = append(starts, start)
+	}
+	return lines, starts, len(d.lineStarts), nil
+}
+
+// restat updates the position stats of the lines from the index from onwards
+func (d *Document) restat(from int) {
+	end := d.length
+	for i := from; i < len(d.lines); i++ {
+		runeOffset := end
+		if d.lineStarts[i] < len(d.boxStarts) {
+			runeOffset = d.boxStarts[d.lineStarts[i]]
+		}
+		d.lines[i].setStats(i, 0, d.lineStarts[i], d.lineStarts[i], runeOffset)
+	}
+}
+
+// sameBox reports whether two boxes produced from the same content have the same text and measurements
+func sameBox(a, b Box) bool {
+	return reflect.TypeOf(a) == reflect.TypeOf(b) && a.TextValue() == b.TextValue() && a.AdvanceRect() == b.AdvanceRect()
+}
+
+// editable true if the content is plain text which can be edited, rather than content boxed as a whole such as an
+// image, table, list, footnote, field or section
+func (c *Content) editable() bool {
+	return !c.boxedWhole()
+}
+
+// writeText writes the text of the content and everything in it, in the order runeLen counts it
+func (c *Content) writeText(sb *strings.Builder) {
+	sb.WriteString(c.text)
+	for _, child := range c.children {
+		child.writeText(sb)
+	}
+	if c.table != nil {
+		for _, row := range c.table.rows {
+			for _, cell := range row {
+				for _, cc := range cell.contents {
+					cc.writeText(sb)
+				}
+			}
+		}
+	}
+	if c.list != nil {
+		for _, item := range c.list.items {
+			for _, ic := range item.contents {
+				ic.writeText(sb)
+			}
+		}
+	}
+	if c.footnote != nil {
+		for _, fc := range c.footnote.contents {
+			fc.writeText(sb)
+		}
+	}
+}
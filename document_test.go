@@ -0,0 +1,250 @@
+package wordwrap
+
+import (
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func documentLineTexts(d *Document) []string {
+	var r []string
+	for _, l := range d.Lines() {
+		r = append(r, l.TextValue())
+	}
+	return r
+}
+
+func TestDocument_Edits(t *testing.T) {
+	fontFace := FontFace16DPI180ForTest(t)
+	red := color.RGBA{255, 0, 0, 255}
+	paragraph := strings.Repeat("The quick brown fox jumps over the lazy dog. ", 20)
+	width := SpaceFor(fontFace, "The quick brown fox jumps").Dx()
+
+	tests := []struct {
+		name  string
+		edits func(t *testing.T, d *Document)
+	}{
+		{
+			name: "Insert a word in the middle",
+			edits: func(t *testing.T, d *Document) {
+				if _, err := d.Insert(100, "extraordinarily "); err != nil {
+					t.Fatalf("Insert failed: %v", err)
+				}
+			},
+		},
+		{
+			name: "Insert at the end",
+			edits: func(t *testing.T, d *Document) {
+				if _, err := d.Insert(len([]rune(d.Text())), " The end."); err != nil {
+					t.Fatalf("Insert failed: %v", err)
+				}
+			},
+		},
+		{
+			name: "Delete across contents",
+			edits: func(t *testing.T, d *Document) {
+				if _, err := d.Delete(len(paragraph)-10, len(paragraph)+10); err != nil {
+					t.Fatalf("Delete failed: %v", err)
+				}
+			},
+		},
+		{
+			name: "Type then backspace",
+			edits: func(t *testing.T, d *Document) {
+				for i, r := range "hello " {
+					if _, err := d.Insert(50+i, string(r)); err != nil {
+						t.Fatalf("Insert failed: %v", err)
+					}
+				}
+				for i := 0; i < 6; i++ {
+					if _, err := d.Delete(55-i, 56-i); err != nil {
+						t.Fatalf("Delete failed: %v", err)
+					}
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := NewDocument(width, fontFace, paragraph, Color(red), paragraph)
+			if err != nil {
+				t.Fatalf("NewDocument failed: %v", err)
+			}
+			tt.edits(t, d)
+			var args []interface{}
+			args = append(args, fontFace)
+			for _, c := range d.Contents() {
+				args = append(args, c)
+			}
+			fresh, err := NewDocument(width, args...)
+			if err != nil {
+				t.Fatalf("NewDocument failed: %v", err)
+			}
+			if s := cmp.Diff(documentLineTexts(fresh), documentLineTexts(d)); s != "" {
+				t.Errorf("incremental layout differs from a fresh layout:\n%s", s)
+			}
+			for i, l := range d.Lines() {
+				want := linePositionStats(fresh.Lines()[i]).RuneOffset
+				if got := linePositionStats(l).RuneOffset; got != want {
+					t.Errorf("line %d RuneOffset = %d, want %d", i, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestDocument_ChangeIsLocal(t *testing.T) {
+	fontFace := FontFace16DPI180ForTest(t)
+	paragraph := strings.Repeat("The quick brown fox jumps over the lazy dog. ", 50)
+	d, err := NewDocument(SpaceFor(fontFace, "The quick brown fox jumps").Dx(), fontFace, paragraph)
+	if err != nil {
+		t.Fatalf("NewDocument failed: %v", err)
+	}
+	total := len(d.Lines())
+	change, err := d.Insert(len(paragraph)/2, "x")
+	if err != nil {
+		t.Fatalf("Insert failed: %v", err)
+	}
+	if change.Removed > 3 || change.Inserted > 3 {
+		t.Errorf("expected only a few lines to change, got %+v of %d", change, total)
+	}
+	if change.Start == 0 {
+		t.Errorf("expected the change to start mid document, got %+v", change)
+	}
+	if len(d.Lines()) != total-change.Removed+change.Inserted {
+		t.Errorf("line count %d inconsistent with change %+v", len(d.Lines()), change)
+	}
+}
+
+func TestDocument_ContainerEdit(t *testing.T) {
+	fontFace := FontFace16DPI180ForTest(t)
+	d, err := NewDocument(1000, fontFace, "Before ", Container("boxed"), " after")
+	if err != nil {
+		t.Fatalf("NewDocument failed: %v", err)
+	}
+	if _, err := d.Insert(9, "x"); err != ErrEditInContainer {
+		t.Errorf("expected ErrEditInContainer got %v", err)
+	}
+	if _, err := d.Insert(7, "x"); err != nil {
+		t.Errorf("insert before a container failed: %v", err)
+	}
+	if d.Text() != "Before xboxed after" {
+		t.Errorf("Text() = %q", d.Text())
+	}
+}
+
+func TestDocument_StructuredContentEdit(t *testing.T) {
+	fontFace := FontFace16DPI180ForTest(t)
+	tests := []struct {
+		name string
+		arg  interface{}
+	}{
+		{name: "Table", arg: Table(Row(Cell("one"), Cell("two")))},
+		{name: "List", arg: List(Item("one"), Item("two"))},
+		{name: "Footnote", arg: Footnote("a note")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := NewDocument(1000, fontFace, "ab", tt.arg, "cd")
+			if err != nil {
+				t.Fatalf("NewDocument failed: %v", err)
+			}
+			if _, err := d.Insert(3, "x"); err != ErrEditInContainer {
+				t.Errorf("expected ErrEditInContainer inserting got %v", err)
+			}
+			if _, err := d.Delete(1, 3); err != ErrEditInContainer {
+				t.Errorf("expected ErrEditInContainer deleting got %v", err)
+			}
+			if _, err := d.Insert(2, "x"); err != nil {
+				t.Errorf("insert before the content failed: %v", err)
+			}
+			if got := d.Contents()[0].text; got != "abx" {
+				t.Errorf("expected the insert in the text before got %q", got)
+			}
+		})
+	}
+
+	d, err := NewDocument(1000, fontFace, PageNumber(), "cd", Section("Intro"))
+	if err != nil {
+		t.Fatalf("NewDocument failed: %v", err)
+	}
+	if _, err := d.Insert(0, "x"); err != nil {
+		t.Fatalf("insert failed: %v", err)
+	}
+	if _, err := d.Insert(5, "y"); err != nil {
+		t.Fatalf("insert failed: %v", err)
+	}
+	var texts []string
+	for _, c := range d.Contents() {
+		if !c.editable() && c.text != "" {
+			t.Errorf("expected fields and section marks not to be edited got %q", c.text)
+		}
+		texts = append(texts, c.text)
+	}
+	if s := cmp.Diff([]string{"", "xcd", "", "Inytro"}, texts); s != "" {
+		t.Errorf("contents differ:\n%s", s)
+	}
+}
+
+func TestDocument_InsertWithoutText(t *testing.T) {
+	fontFace := FontFace16DPI180ForTest(t)
+	tests := []struct {
+		name   string
+		args   []interface{}
+		offset int
+		want   string
+	}{
+		{name: "Empty document", offset: 0, want: "x"},
+		{name: "Before a container", args: []interface{}{Container("boxed")}, offset: 0, want: "xboxed"},
+		{name: "After a container", args: []interface{}{Container("boxed")}, offset: 5, want: "boxedx"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := NewDocument(1000, append([]interface{}{fontFace}, tt.args...)...)
+			if err != nil {
+				t.Fatalf("NewDocument failed: %v", err)
+			}
+			if _, err := d.Insert(tt.offset, "x"); err != nil {
+				t.Fatalf("Insert failed: %v", err)
+			}
+			if d.Text() != tt.want {
+				t.Errorf("Text() = %q, want %q", d.Text(), tt.want)
+			}
+			if len(d.Lines()) != 1 {
+				t.Errorf("expected 1 line got %d", len(d.Lines()))
+			}
+			if _, err := d.Insert(d.textLen()+1, "y"); err == nil {
+				t.Errorf("expected an error inserting past the end")
+			}
+		})
+	}
+}
+
+func TestDocument_TextMatchesOffsets(t *testing.T) {
+	fontFace := FontFace16DPI180ForTest(t)
+	d, err := NewDocument(1000, fontFace, "ab", Footnote("note"), " cd", Table(Row(Cell("x"), Cell("y"))), "ef")
+	if err != nil {
+		t.Fatalf("NewDocument failed: %v", err)
+	}
+	if got, want := d.Text(), "abnote cdxyef"; got != want {
+		t.Fatalf("Text() = %q, want %q", got, want)
+	}
+	bounds := image.Rect(0, 0, 1000, 1000)
+	start := strings.Index(d.Text(), "ef")
+	rs := d.sw.SelectionRects(d.Lines(), bounds, bounds.Min, start, start+2)
+	if len(rs) != 1 {
+		t.Fatalf("expected 1 rect got %v", rs)
+	}
+	lrs := d.sw.lineRects(d.Lines(), bounds, bounds.Min)
+	for li, l := range d.Lines() {
+		boxes := l.Boxes()
+		for bi, br := range boxRects(boxes, lrs[li]) {
+			if boxes[bi].TextValue() == "ef" && br != rs[0] {
+				t.Errorf("selection %v not over the box of ef %v", rs[0], br)
+			}
+		}
+	}
+}
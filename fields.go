@@ -0,0 +1,331 @@
+package wordwrap
+
+import (
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+// FieldKind is what a field is resolved to
+type FieldKind int
+
+const (
+	// PageNumberField the number of the page the field is on, counting from 1
+	PageNumberField FieldKind = iota
+	// PageCountField the number of pages
+	PageCountField
+	// DateField the date of the layout, FieldValues.Time or now if not set
+	DateField
+	// SectionField the title of the section the field is in
+	SectionField
+	// VariableField a variable from FieldValues.Variables
+	VariableField
+)
+
+// FieldContent is text resolved when it is laid out, such as the page number, returned by PageNumber, PageCount, Date,
+// SectionTitle and Variable. It is drawn in the font and colour in effect.
+type FieldContent struct {
+	Kind FieldKind
+	// Name is the name of the variable for a VariableField, or the time layout for a DateField
+	Name string
+}
+
+// PageNumber returns a field of the number of the page it is on, counting from 1
+func PageNumber() FieldContent {
+	return FieldContent{Kind: PageNumberField}
+}
+
+// PageCount returns a field of the number of pages. TextToSpecPages lays the pages out again if it wasn't known, other
+// layouts use FieldValues.Pages or the number of the current page until it is.
+func PageCount() FieldContent {
+	return FieldContent{Kind: PageCountField}
+}
+
+// Date returns a field of the date formatted with the time layout, time.DateOnly if it is empty
+func Date(layout string) FieldContent {
+	return FieldContent{Kind: DateField, Name: layout}
+}
+
+// SectionTitle returns a field of the title of the section it is in, or for a header or footer the section at the top
+// of the page
+func SectionTitle() FieldContent {
+	return FieldContent{Kind: SectionField}
+}
+
+// Variable returns a field of the named variable from FieldValues.Variables
+func Variable(name string) FieldContent {
+	return FieldContent{Kind: VariableField, Name: name}
+}
+
+// SectionGroup is the content of a section, returned by Section
+type SectionGroup Group
+
+// Section returns a SectionGroup. Its args are rich content, shown as is, whose text becomes the title of the section
+// for SectionTitle fields from where it starts.
+func Section(args ...interface{}) interface{} {
+	return SectionGroup{Args: args}
+}
+
+// FieldValues is a WrapperOption giving the values fields resolve to
+type FieldValues struct {
+	// Pages is the number of pages, 0 if it isn't known
+	Pages int
+	// Time is the time of Date fields, now if it is zero
+	Time time.Time
+	// Variables are the values of Variable fields
+	Variables map[string]string
+}
+
+// Reports interface adherence
+var _ WrapperOption = FieldValues{}
+
+// ApplyWrapperConfig installs the field values
+func (fv FieldValues) ApplyWrapperConfig(wr interface{}) {
+	if wr, ok := wr.(interface{ setFieldValues(FieldValues) }); ok {
+		wr.setFieldValues(fv)
+	} else {
+		log.Printf("can't apply")
+	}
+}
+
+// setFieldValues sets the field values
+func (sw *SimpleWrapper) setFieldValues(fv FieldValues) {
+	sw.fields.values = fv
+}
+
+// fieldState what fields resolve to where they are laid out
+type fieldState struct {
+	values FieldValues
+	// page is the page being laid out counting from 1
+	page int
+	// section is the title of the section being laid out
+	section string
+	// pageCountUsed is set when a page count field was resolved without the page count being known
+	pageCountUsed bool
+}
+
+// resolve the text of the field
+func (fs *fieldState) resolve(f FieldContent) string {
+	switch f.Kind {
+	case PageNumberField:
+		return strconv.Itoa(fs.page)
+	case PageCountField:
+		if fs.values.Pages > 0 {
+			return strconv.Itoa(fs.values.Pages)
+		}
+		fs.pageCountUsed = true
+		return strconv.Itoa(fs.page)
+	case DateField:
+		t := fs.values.Time
+		if t.IsZero() {
+			t = time.Now()
+		}
+		layout := f.Name
+		if layout == "" {
+			layout = time.DateOnly
+		}
+		return t.Format(layout)
+	case SectionField:
+		return fs.section
+	case VariableField:
+		return fs.values.Variables[f.Name]
+	}
+	return ""
+}
+
+// resolveFields returns the box with the fields in it resolved, copying rather than changing any box with a field in
+// it so a box list can be laid out again
+func (fs *fieldState) resolveFields(b Box) (Box, error) {
+	switch b := b.(type) {
+	case *FieldBox:
+		return b.resolved(fs.resolve(b.Field))
+	case *RowBox:
+		var boxes []Box
+		for i, child := range b.Boxes {
+			rc, err := fs.resolveFields(child)
+			if err != nil {
+				return nil, err
+			}
+			if rc != child && boxes == nil {
+				boxes = append([]Box(nil), b.Boxes...)
+			}
+			if boxes != nil {
+				boxes[i] = rc
+			}
+		}
+		if boxes == nil {
+			return b, nil
+		}
+		return &RowBox{Boxes: boxes}, nil
+	}
+	return b, nil
+}
+
+// FieldBox is a field, its size being that of the text it was last resolved to. Lines are folded with the resolved
+// text so a field whose width changes is folded again.
+type FieldBox struct {
+	// Field is what the box resolves to
+	Field FieldContent
+	// drawer is the font the text is drawn in
+	drawer *font.Drawer
+	// text is the resolved text
+	text Box
+	// decorate wraps the resolved text in the boxes text of the field's content is wrapped in, nil if it isn't
+	decorate func(Box) Box
+}
+
+// Interface enforcement
+var _ Box = (*FieldBox)(nil)
+
+// NewFieldBox constructs a FieldBox, such as for a page break box, drawn with the drawer's font and colour
+func NewFieldBox(drawer *font.Drawer, f FieldContent) (*FieldBox, error) {
+	return newFieldBox(drawer, f, nil)
+}
+
+// newFieldBox constructs a FieldBox whose resolved text is wrapped with decorate, if it isn't nil
+func newFieldBox(drawer *font.Drawer, f FieldContent, decorate func(Box) Box) (*FieldBox, error) {
+	fb := &FieldBox{
+		Field:    f,
+		drawer:   drawer,
+		decorate: decorate,
+	}
+	return fb.resolved("")
+}
+
+// resolved a copy of the box resolved to the text
+func (fb *FieldBox) resolved(s string) (*FieldBox, error) {
+	if fb.text != nil && fb.text.TextValue() == s {
+		return fb, nil
+	}
+	tb, err := NewSimpleTextBox(fb.drawer, s)
+	if err != nil {
+		return nil, err
+	}
+	var text Box = tb
+	if fb.decorate != nil {
+		text = fb.decorate(text)
+	}
+	cp := *fb
+	cp.text = text
+	return &cp, nil
+}
+
+// AdvanceRect width of the resolved text
+func (fb *FieldBox) AdvanceRect() fixed.Int26_6 {
+	return fb.text.AdvanceRect()
+}
+
+// MetricsRect all other font details of the resolved text
+func (fb *FieldBox) MetricsRect() font.Metrics {
+	return fb.text.MetricsRect()
+}
+
+// Whitespace if this is a white space or not
+func (fb *FieldBox) Whitespace() bool {
+	return false
+}
+
+// DrawBox draws the resolved text
+func (fb *FieldBox) DrawBox(i Image, y fixed.Int26_6, dc *DrawConfig) {
+	fb.text.DrawBox(i, y, dc)
+}
+
+// FontDrawer font used
+func (fb *FieldBox) FontDrawer() *font.Drawer {
+	return fb.drawer
+}
+
+// Len fields don't stand for any of the source
+func (fb *FieldBox) Len() int {
+	return 0
+}
+
+// TextValue the resolved text
+func (fb *FieldBox) TextValue() string {
+	return fb.text.TextValue()
+}
+
+func (fb *FieldBox) MinSize() (fixed.Int26_6, fixed.Int26_6) {
+	return 0, 0
+}
+
+func (fb *FieldBox) MaxSize() (fixed.Int26_6, fixed.Int26_6) {
+	return 0, 0
+}
+
+// sectionContent marks the start of a section
+type sectionContent struct {
+	title string
+}
+
+// sectionBox marks where a section starts, it takes no space
+type sectionBox struct {
+	title string
+}
+
+// Interface enforcement
+var _ Box = (*sectionBox)(nil)
+
+func (sb *sectionBox) AdvanceRect() fixed.Int26_6 {
+	return 0
+}
+
+func (sb *sectionBox) MetricsRect() font.Metrics {
+	return font.Metrics{}
+}
+
+func (sb *sectionBox) Whitespace() bool {
+	return false
+}
+
+func (sb *sectionBox) DrawBox(i Image, y fixed.Int26_6, dc *DrawConfig) {}
+
+func (sb *sectionBox) FontDrawer() *font.Drawer {
+	return nil
+}
+
+func (sb *sectionBox) Len() int {
+	return 0
+}
+
+func (sb *sectionBox) TextValue() string {
+	return ""
+}
+
+func (sb *sectionBox) MinSize() (fixed.Int26_6, fixed.Int26_6) {
+	return 0, 0
+}
+
+func (sb *sectionBox) MaxSize() (fixed.Int26_6, fixed.Int26_6) {
+	return 0, 0
+}
+
+// contentText the text of the contents and their children
+func contentText(contents []*Content) string {
+	var b strings.Builder
+	for _, c := range contents {
+		b.WriteString(c.text)
+		b.WriteString(contentText(c.children))
+	}
+	return b.String()
+}
+
+// pageSection the title of the section at the top of the next page, which is the one starting there if any
+func (sw *SimpleWrapper) pageSection() (string, error) {
+	b, _, err := sw.boxer.Next()
+	if err != nil {
+		return "", err
+	}
+	if b == nil {
+		return sw.fields.section, nil
+	}
+	sw.boxer.Unshift(b)
+	if sb, ok := b.(*sectionBox); ok {
+		return sb.title, nil
+	}
+	return sw.fields.section, nil
+}
@@ -0,0 +1,175 @@
+package wordwrap
+
+import (
+	"image"
+	"image/color"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestFieldResolve(t *testing.T) {
+	fs := &fieldState{
+		values: FieldValues{
+			Time:      time.Date(2024, 3, 9, 0, 0, 0, 0, time.UTC),
+			Variables: map[string]string{"title": "Report"},
+		},
+		page:    3,
+		section: "Intro",
+	}
+	tests := []struct {
+		field FieldContent
+		want  string
+	}{
+		{field: PageNumber(), want: "3"},
+		{field: Date(""), want: "2024-03-09"},
+		{field: Date("Jan 2006"), want: "Mar 2024"},
+		{field: SectionTitle(), want: "Intro"},
+		{field: Variable("title"), want: "Report"},
+		{field: Variable("missing"), want: ""},
+	}
+	for _, tt := range tests {
+		if got := fs.resolve(tt.field); got != tt.want {
+			t.Errorf("resolve(%v) = %q want %q", tt.field, got, tt.want)
+		}
+	}
+	if fs.pageCountUsed {
+		t.Errorf("page count not used yet")
+	}
+	if got := fs.resolve(PageCount()); got != "3" || !fs.pageCountUsed {
+		t.Errorf("expected the current page as an estimate got %q", got)
+	}
+	fs.values.Pages = 12
+	if got := fs.resolve(PageCount()); got != "12" {
+		t.Errorf("expected the given page count got %q", got)
+	}
+}
+
+func TestFields(t *testing.T) {
+	fontFace := FontFace16DPI180ForTest(t)
+	text := strings.Repeat("The quick brown fox jumps over the lazy dog. ", 12)
+
+	t.Run("Resolved in text", func(t *testing.T) {
+		sw := NewRichWrapper(fontFace, "Page ", PageNumber(), " of ", Variable("n"), FieldValues{Variables: map[string]string{"n": "many"}})
+		var got []string
+		for sw.HasNext() {
+			ls, _, err := sw.TextToRect(image.Rect(0, 0, 600, 600))
+			if err != nil {
+				t.Fatalf("TextToRect failed: %v", err)
+			}
+			got = append(got, lineTexts(ls)...)
+		}
+		if s := cmp.Diff([]string{"Page 1 of many"}, got); s != "" {
+			t.Errorf("lines differ:\n%s", s)
+		}
+	})
+
+	t.Run("Folded at the resolved width", func(t *testing.T) {
+		long := strings.Repeat("x", 40)
+		sw := NewRichWrapper(fontFace, "Start ", Variable("v"), FieldValues{Variables: map[string]string{"v": long}})
+		ls, _, err := sw.TextToRect(image.Rect(0, 0, 300, 600))
+		if err != nil {
+			t.Fatalf("TextToRect failed: %v", err)
+		}
+		if s := cmp.Diff([]string{"Start ", long}, lineTexts(ls)); s != "" {
+			t.Errorf("lines differ:\n%s", s)
+		}
+	})
+
+	t.Run("Page of pages in the footer", func(t *testing.T) {
+		sw := NewRichWrapper(fontFace, text)
+		pages, err := sw.TextToSpecPages(Width(Fixed(900)), Height(Fixed(300)),
+			Footer(CenterSlot("Page ", PageNumber(), " of ", PageCount())),
+		)
+		if err != nil {
+			t.Fatalf("TextToSpecPages failed: %v", err)
+		}
+		if len(pages) < 3 {
+			t.Fatalf("expected several pages got %d", len(pages))
+		}
+		for _, lr := range pages {
+			want := "Page " + strconv.Itoa(lr.Page) + " of " + strconv.Itoa(len(pages))
+			if got := bandText(lr.Footer, BandCenter); got != want {
+				t.Errorf("page %d footer %q want %q", lr.Page, got, want)
+			}
+		}
+		if sw.fields.values.Pages != 0 {
+			t.Errorf("expected the page count to be worked out again next time")
+		}
+	})
+
+	t.Run("Section in the header", func(t *testing.T) {
+		sw := NewRichWrapper(fontFace, Section("Intro"), " "+text, Section("Method"), " "+text)
+		pages, err := sw.TextToSpecPages(Width(Fixed(600)), Height(Fixed(300)), Header(SectionTitle()))
+		if err != nil {
+			t.Fatalf("TextToSpecPages failed: %v", err)
+		}
+		var headers []string
+		var body []string
+		for _, lr := range pages {
+			headers = append(headers, bandText(lr.Header, BandLeft))
+			body = append(body, lineTexts(lr.Lines)...)
+		}
+		if headers[0] != "Intro" || headers[len(headers)-1] != "Method" {
+			t.Errorf("headers %q", headers)
+		}
+		for i := 1; i < len(headers); i++ {
+			if headers[i] == "Intro" && headers[i-1] == "Method" {
+				t.Errorf("headers went back a section %q", headers)
+			}
+		}
+		if s := cmp.Diff(strings.Fields("Intro "+text+"Method "+text), strings.Fields(strings.Join(body, ""))); s != "" {
+			t.Errorf("text differs:\n%s", s)
+		}
+	})
+
+	t.Run("Styled and identified", func(t *testing.T) {
+		red := color.RGBA{R: 255, A: 255}
+		sw := NewRichWrapper(fontFace, "Page ", ID("pn", BgColor(red, PageNumber())))
+		bounds := image.Rect(0, 0, 600, 200)
+		ls, _, err := sw.TextToRect(bounds)
+		if err != nil {
+			t.Fatalf("TextToRect failed: %v", err)
+		}
+		boxes := ls[0].Boxes()
+		fb, ok := boxes[len(boxes)-1].(*FieldBox)
+		if !ok || fb.TextValue() != "1" {
+			t.Fatalf("expected the line to end with the field got %#v", boxes[len(boxes)-1])
+		}
+		r := boxRects(boxes, sw.lineRects(ls, bounds, image.Point{})[0])[len(boxes)-1]
+		i := image.NewRGBA(bounds)
+		if err := sw.RenderLines(i, ls, image.Point{}); err != nil {
+			t.Fatalf("RenderLines failed: %v", err)
+		}
+		if c := i.RGBAAt(r.Min.X, r.Min.Y); c != red {
+			t.Errorf("expected the field's background got %v", c)
+		}
+		h := sw.HitTest(r.Min.Add(image.Pt(1, 1)), ls, bounds, image.Point{})
+		if h == nil || h.ID != "pn" || h.TextBox == nil || h.TextBox.TextValue() != "1" {
+			t.Errorf("expected the field's ID and text from the hit test got %+v", h)
+		}
+	})
+
+	t.Run("Page break box", func(t *testing.T) {
+		sw := NewRichWrapper(fontFace, text)
+		fb, err := NewFieldBox(sw.fontDrawer, PageNumber())
+		if err != nil {
+			t.Fatalf("NewFieldBox failed: %v", err)
+		}
+		sw.ApplyOptions(NewPageBreakBox(fb))
+		for page := 1; page <= 2; page++ {
+			ls, _, err := sw.TextToRect(image.Rect(0, 0, 600, 150))
+			if err != nil {
+				t.Fatalf("TextToRect failed: %v", err)
+			}
+			boxes := ls[len(ls)-1].Boxes()
+			pbb, ok := boxes[len(boxes)-1].(*PageBreakBox)
+			if !ok || pbb.VisualBox.TextValue() != strconv.Itoa(page) {
+				t.Errorf("page %d ends with %#v", page, boxes[len(boxes)-1])
+			}
+		}
+	})
+}
@@ -0,0 +1,241 @@
+package wordwrap
+
+import (
+	"image"
+
+	"golang.org/x/image/math/fixed"
+)
+
+// FloatSide is the container edge a float is placed against
+type FloatSide int
+
+const (
+	// FloatLeft places the float against the left edge with lines folded to its right
+	FloatLeft FloatSide = iota
+	// FloatRight places the float against the right edge with lines folded to its left
+	FloatRight
+)
+
+// FloatBox is a box which is taken out of the line it's in and placed against the left or right edge of the container
+// at the top of that line. The lines beside it are folded into the narrower width remaining until its bottom is passed.
+type FloatBox struct {
+	Box
+	Side FloatSide
+}
+
+// Interface enforcement
+var _ Box = (*FloatBox)(nil)
+
+// Float returns a Group which floats its images and containers to the given side. Every box in the group floats, so
+// text should be put in a Container first.
+func Float(side FloatSide, args ...interface{}) interface{} {
+	return Group{Args: append([]interface{}{side}, args...)}
+}
+
+// WithFloat floats the content's box to the given side
+func WithFloat(side FloatSide) ContentOption {
+	return func(c *Content) {
+		c.decorators = append([]func(Box) Box{floatDecorator(side)}, c.decorators...)
+	}
+}
+
+// floatDecorator wraps boxes in a FloatBox
+func floatDecorator(side FloatSide) func(Box) Box {
+	return func(b Box) Box {
+		return &FloatBox{
+			Box:  b,
+			Side: side,
+		}
+	}
+}
+
+// FloatGap is a FolderOption that sets the space left between a float and the lines beside and below it
+func FloatGap(px int) WrapperOption {
+	return folderOptionFunc(func(f interface{}) {
+		if f, ok := f.(*SimpleFolder); ok {
+			f.floatGap = px
+		}
+	})
+}
+
+// floatSide reports whether b is a float, looking through any IDBox around it
+func floatSide(b Box) (FloatSide, bool) {
+	for {
+		switch bb := b.(type) {
+		case *FloatBox:
+			return bb.Side, true
+		case *IDBox:
+			b = bb.Box
+		default:
+			return 0, false
+		}
+	}
+}
+
+// placedFloat is a float placed at the top of a line
+type placedFloat struct {
+	Box
+	// x is the position of the float from the left of the container
+	x    int
+	side FloatSide
+	// at is the index of the box on the line the float was taken from the boxer before
+	at int
+}
+
+// size the size of the float in pixels
+func (pf *placedFloat) size() image.Point {
+	m := pf.MetricsRect()
+	return image.Pt(pf.AdvanceRect().Ceil(), (m.Ascent + m.Descent).Ceil())
+}
+
+// activeFloat is a float the folder may still need to fold lines around
+type activeFloat struct {
+	side FloatSide
+	// width is the width taken from the lines beside the float, including the gap
+	width int
+	// bottom is the y position the float ends at, not including the gap
+	bottom int
+}
+
+// floatedLine is a line that may have been narrowed by floats
+type floatedLine interface {
+	// floatOffset returns how far the line is moved right by left floats and the width left for it, ok is false if
+	// no floats are beside the line
+	floatOffset() (xoffset int, width int, ok bool)
+	// placedFloats the floats placed at the top of the line
+	placedFloats() []*placedFloat
+	// containerWidth the width of what the line was folded in, which its offset and width are within
+	containerWidth() int
+}
+
+// Ensures that the interface is filled
+var _ floatedLine = (*SimpleLine)(nil)
+
+// floatOffset returns how far the line is moved right by left floats and the width left for it
+func (l *SimpleLine) floatOffset() (int, int, bool) {
+	return l.xoffset, l.width, l.narrowed
+}
+
+// placedFloats the floats placed at the top of the line
+func (l *SimpleLine) placedFloats() []*placedFloat {
+	return l.floats
+}
+
+// containerWidth the width of what the line was folded in
+func (l *SimpleLine) containerWidth() int {
+	return l.container
+}
+
+// narrow takes the float's width from the line
+func (l *SimpleLine) narrow(f *activeFloat) {
+	l.width -= f.width
+	if f.side == FloatLeft {
+		l.xoffset += f.width
+	}
+	l.narrowed = true
+}
+
+// startLine drops the floats that end above the line and narrows the line for those beside it and the list item it is
+// in
+func (sf *SimpleFolder) startLine(l *SimpleLine) {
+	active := sf.floats[:0]
+	for _, f := range sf.floats {
+		if f.bottom+sf.floatGap > sf.y {
+			active = append(active, f)
+			l.narrow(f)
+		}
+	}
+	sf.floats = active
+	l.indentBy(sf.indent)
+}
+
+// addFloat places the float at the top of the line if it fits beside what is already on the line, returning false if
+// it doesn't. A float leaving no room beside it for text is placed on an otherwise empty line as tall as it, ended is
+// true then, so the lines after go below it.
+func (sf *SimpleFolder) addFloat(b Box, side FloatSide, l *SimpleLine) (placed bool, ended bool) {
+	pf := &placedFloat{Box: b, side: side, at: len(l.boxes)}
+	s := pf.size()
+	used := (l.size.Max.X - l.size.Min.X).Ceil()
+	if len(l.boxes) > 0 && used+s.X+sf.floatGap > l.width {
+		return false, false
+	}
+	pf.x = l.xoffset
+	if s.X+sf.floatGap >= l.width {
+		// The float has the line to itself rather than narrowing the lines beside it to nothing
+		l.floats = append(l.floats, pf)
+		l.size.Max.Y = l.size.Min.Y + fixed.I(s.Y+sf.floatGap)
+		sf.floatBottom = max(sf.floatBottom, sf.y+s.Y)
+		return true, true
+	}
+	if side == FloatRight {
+		pf.x = l.xoffset + l.width - s.X
+	}
+	f := &activeFloat{
+		side:   side,
+		width:  s.X + sf.floatGap,
+		bottom: sf.y + s.Y,
+	}
+	sf.floats = append(sf.floats, f)
+	if f.bottom > sf.floatBottom {
+		sf.floatBottom = f.bottom
+	}
+	l.floats = append(l.floats, pf)
+	l.narrow(f)
+	return true, false
+}
+
+// floatRunesBefore the rune length of the floats on the line that were taken from the boxer just before the box at
+// index i, so walks over the boxes of a line count the runes the floats stand for
+func floatRunesBefore(l Line, i int) int {
+	fl, ok := l.(floatedLine)
+	if !ok {
+		return 0
+	}
+	n := 0
+	for _, pf := range fl.placedFloats() {
+		if pf.at == i {
+			n += pf.Len()
+		}
+	}
+	return n
+}
+
+// floatRect a float and where it is drawn
+type floatRect struct {
+	Box
+	Rect image.Rectangle
+}
+
+// floatRects calculates where RenderLines draws the floats of the lines. Right floats are kept against the right of
+// the width the lines are rendered in.
+func (sw *SimpleWrapper) floatRects(ls []Line, bounds image.Rectangle, at image.Point) []floatRect {
+	var frs []floatRect
+	left := at.X + sw.gutterWidth() + sw.calculateAlignmentOffset(ls, bounds).X
+	width := sw.renderWidth(bounds, at)
+	for li, lr := range sw.lineRects(ls, bounds, at) {
+		fl, ok := ls[li].(floatedLine)
+		if !ok {
+			continue
+		}
+		for _, pf := range fl.placedFloats() {
+			x := pf.x
+			if pf.side == FloatRight {
+				x += width - fl.containerWidth()
+			}
+			min := image.Pt(left+x, lr.Min.Y)
+			frs = append(frs, floatRect{
+				Box:  pf.Box,
+				Rect: image.Rectangle{Min: min, Max: min.Add(pf.size())},
+			})
+		}
+	}
+	return frs
+}
+
+// drawFloats draws the floats of the lines, clipped to the image so a float taller than the page is cut off at its
+// bottom
+func (sw *SimpleWrapper) drawFloats(i Image, ls []Line, at image.Point, dc *DrawConfig) {
+	for _, fr := range sw.floatRects(ls, i.Bounds(), at) {
+		fr.DrawBox(i.SubImage(fr.Rect).(Image), fr.MetricsRect().Ascent, dc)
+	}
+}
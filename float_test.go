@@ -0,0 +1,259 @@
+package wordwrap
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/image/font"
+)
+
+func floatImage(w, h int) image.Image {
+	i := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(i, i.Bounds(), image.NewUniform(color.RGBA{R: 255, A: 255}), image.Point{}, draw.Src)
+	return i
+}
+
+func TestFloats(t *testing.T) {
+	fontFace := FontFace16DPI180ForTest(t)
+	text := strings.Repeat("The quick brown fox jumps over the lazy dog. ", 20)
+	bounds := image.Rect(0, 0, 600, 10000)
+
+	for _, side := range []FloatSide{FloatLeft, FloatRight} {
+		name := map[FloatSide]string{FloatLeft: "Left", FloatRight: "Right"}[side]
+		t.Run(name, func(t *testing.T) {
+			sw := NewRichWrapper(fontFace, Float(side, ImageContent{Image: floatImage(150, 200)}), text, FloatGap(10))
+			ls, _, err := sw.TextToRect(bounds)
+			if err != nil {
+				t.Fatalf("TextToRect failed: %v", err)
+			}
+			frs := sw.floatRects(ls, bounds, image.Point{})
+			if len(frs) != 1 {
+				t.Fatalf("expected 1 float got %d", len(frs))
+			}
+			fr := frs[0].Rect
+			wantX := 0
+			if side == FloatRight {
+				wantX = 600 - 150
+			}
+			if fr.Min.X != wantX || fr.Dx() != 150 || fr.Dy() != 200 {
+				t.Errorf("float placed at %v", fr)
+			}
+			beside, below := 0, 0
+			for li, lr := range sw.lineRects(ls, bounds, image.Point{}) {
+				if lr.Empty() {
+					continue
+				}
+				if lr.Min.Y < fr.Max.Y+10 {
+					beside++
+					if lr.Overlaps(fr) {
+						t.Errorf("line %d %v overlaps the float %v", li, lr, fr)
+					}
+					if _, w, ok := ls[li].(floatedLine).floatOffset(); !ok || w != 600-150-10 {
+						t.Errorf("line %d beside the float has width %d", li, w)
+					}
+				} else {
+					below++
+					if x, _, ok := ls[li].(floatedLine).floatOffset(); ok || x != 0 {
+						t.Errorf("line %d below the float is still narrowed", li)
+					}
+				}
+			}
+			if beside == 0 || below == 0 {
+				t.Errorf("expected lines beside and below the float got %d and %d", beside, below)
+			}
+		})
+	}
+
+	t.Run("Drawn by RenderLines", func(t *testing.T) {
+		sw := NewRichWrapper(fontFace, "Some text ", Float(FloatRight, ImageContent{Image: floatImage(50, 50)}), "more text")
+		ls, _, err := sw.TextToRect(image.Rect(0, 0, 600, 400))
+		if err != nil {
+			t.Fatalf("TextToRect failed: %v", err)
+		}
+		i := image.NewRGBA(image.Rect(0, 0, 600, 400))
+		if err := sw.RenderLines(i, ls, image.Point{}); err != nil {
+			t.Fatalf("RenderLines failed: %v", err)
+		}
+		if c := i.RGBAAt(575, 25); c != (color.RGBA{R: 255, A: 255}) {
+			t.Errorf("expected the float at the right edge got %v", c)
+		}
+		if got := strings.Join(lineTexts(ls), "|"); got != "Some text more text" {
+			t.Errorf("lines = %q", got)
+		}
+	})
+
+	t.Run("Floats past the last line extend the page", func(t *testing.T) {
+		sw := NewRichWrapper(fontFace, "Short", Float(FloatLeft, ImageContent{Image: floatImage(50, 300)}))
+		_, p, err := sw.TextToRect(image.Rect(0, 0, 600, 1000))
+		if err != nil {
+			t.Fatalf("TextToRect failed: %v", err)
+		}
+		if p.Y < 300 {
+			t.Errorf("expected the page to end below the float got %v", p)
+		}
+	})
+
+	t.Run("Too wide for the line starts the next", func(t *testing.T) {
+		sw := NewRichWrapper(fontFace, "Some text on the line", Float(FloatLeft, ImageContent{Image: floatImage(500, 50)}), " after")
+		ls, _, err := sw.TextToRect(image.Rect(0, 0, 600, 1000))
+		if err != nil {
+			t.Fatalf("TextToRect failed: %v", err)
+		}
+		if len(ls) < 2 || len(ls[0].(floatedLine).placedFloats()) != 0 || len(ls[1].(floatedLine).placedFloats()) != 1 {
+			t.Errorf("expected the float on the second line got %q", lineTexts(ls))
+		}
+	})
+	t.Run("Content options without a style", func(t *testing.T) {
+		marked := func(b Box) Box { return &IDBox{Box: b, id: "marked"} }
+		sb := NewSimpleBoxer([]*Content{
+			NewImageContent(floatImage(20, 20), WithFloat(FloatLeft)),
+			NewContainerContent([]*Content{NewContent("Boxed")}, WithFloat(FloatRight), WithDecorators(marked)),
+		}, &font.Drawer{Face: fontFace})
+		var sides []FloatSide
+		for sb.HasNext() {
+			b, _, err := sb.Next()
+			if err != nil {
+				t.Fatalf("Next failed: %v", err)
+			}
+			if side, ok := floatSide(b); ok {
+				sides = append(sides, side)
+			}
+		}
+		if s := cmp.Diff([]FloatSide{FloatLeft, FloatRight}, sides); s != "" {
+			t.Errorf("floats differ:\n%s", s)
+		}
+	})
+
+	t.Run("Right floats rendered wider than folded", func(t *testing.T) {
+		sw := NewRichWrapper(fontFace, Float(FloatRight, ImageContent{Image: floatImage(100, 100)}), text, FloatGap(10), RightLines)
+		ls, _, err := sw.TextToRect(image.Rect(0, 0, 400, 1000))
+		if err != nil {
+			t.Fatalf("TextToRect failed: %v", err)
+		}
+		bounds := image.Rect(0, 0, 600, 1000)
+		fr := sw.floatRects(ls, bounds, image.Point{})[0].Rect
+		if fr.Max.X != 600 {
+			t.Errorf("float placed at %v, want it against the right of the bounds", fr)
+		}
+		for li, lr := range sw.lineRects(ls, bounds, image.Point{}) {
+			if lr.Min.Y >= fr.Max.Y+10 || lr.Empty() {
+				continue
+			}
+			if lr.Overlaps(fr) || lr.Max.X > fr.Min.X-10+1 {
+				t.Errorf("line %d %v isn't kept clear of the float %v", li, lr, fr)
+			}
+		}
+	})
+
+	t.Run("Too tall for the rest of the page starts the next", func(t *testing.T) {
+		sw := NewRichWrapper(fontFace, strings.Repeat("Some text. ", 12), Float(FloatLeft, ImageContent{Image: floatImage(50, 300)}), " after")
+		r := image.Rect(0, 0, 600, 400)
+		ls, p, err := sw.TextToRect(r)
+		if err != nil {
+			t.Fatalf("TextToRect failed: %v", err)
+		}
+		for li, l := range ls {
+			if len(l.(floatedLine).placedFloats()) != 0 {
+				t.Errorf("line %d has the float on the first page", li)
+			}
+		}
+		if p.Y > r.Max.Y {
+			t.Errorf("page ends at %v past the rect", p)
+		}
+		ls, _, err = sw.TextToRect(r)
+		if err != nil {
+			t.Fatalf("TextToRect failed: %v", err)
+		}
+		if len(ls) == 0 || len(ls[0].(floatedLine).placedFloats()) != 1 {
+			t.Errorf("expected the float on the first line of the second page got %q", lineTexts(ls))
+		}
+	})
+
+	for _, mode := range []OverflowMode{DescentOverflow, FullOverflowDuplicate} {
+		name := map[OverflowMode]string{DescentOverflow: "DescentOverflow", FullOverflowDuplicate: "FullOverflowDuplicate"}[mode]
+		t.Run("Kept when the line is rolled back by "+name, func(t *testing.T) {
+			ls, _, err := NewRichWrapper(fontFace, "Some text").TextToRect(image.Rect(0, 0, 600, 1000))
+			if err != nil || len(ls) != 1 {
+				t.Fatalf("TextToRect failed: %v", err)
+			}
+			h := ls[0].Size().Dy()
+			sw := NewRichWrapper(fontFace, "Some text\n", Float(FloatLeft, ImageContent{Image: floatImage(10, 10)}), "more text", YOverflow(mode))
+			// The second line's baseline is below the page so it is carried on to the next
+			r := image.Rect(0, 0, 600, h+h/3)
+			floats := 0
+			for page := 0; sw.boxer.HasNext(); page++ {
+				if page > 5 {
+					t.Fatalf("too many pages")
+				}
+				ls, _, err := sw.TextToRect(r)
+				if err != nil {
+					t.Fatalf("TextToRect failed: %v", err)
+				}
+				if page == 0 {
+					// Duplicated lines are drawn on the page they overflow as well
+					continue
+				}
+				for _, l := range ls {
+					floats += len(l.(floatedLine).placedFloats())
+				}
+			}
+			if floats != 1 {
+				t.Errorf("expected the float placed once on the pages after the first got %d", floats)
+			}
+		})
+	}
+
+	for _, side := range []FloatSide{FloatLeft, FloatRight} {
+		name := map[FloatSide]string{FloatLeft: "Left", FloatRight: "Right"}[side]
+		t.Run(name+" wider than the rect has a line to itself", func(t *testing.T) {
+			sw := NewRichWrapper(fontFace, Float(side, ImageContent{Image: floatImage(700, 50)}), text, FloatGap(10))
+			bounds := image.Rect(0, 0, 600, 1000)
+			ls, _, err := sw.TextToRect(bounds)
+			if err != nil {
+				t.Fatalf("TextToRect failed: %v", err)
+			}
+			frs := sw.floatRects(ls, bounds, image.Point{})
+			if len(frs) != 1 {
+				t.Fatalf("expected 1 float got %d", len(frs))
+			}
+			fr := frs[0].Rect
+			if fr.Min.X != 0 {
+				t.Errorf("float placed at %v, want it at the start of the line", fr)
+			}
+			for li, lr := range sw.lineRects(ls, bounds, image.Point{}) {
+				if _, w, _ := ls[li].(floatedLine).floatOffset(); w < 0 {
+					t.Errorf("line %d narrowed to %d", li, w)
+				}
+				if len(ls[li].Boxes()) > 0 && lr.Min.Y < fr.Max.Y+10 {
+					t.Errorf("line %d %v isn't below the float %v", li, lr, fr)
+				}
+			}
+		})
+	}
+
+	t.Run("Taller than the page is clipped", func(t *testing.T) {
+		sw := NewRichWrapper(fontFace, Float(FloatLeft, ImageContent{Image: floatImage(50, 800)}), "Some text")
+		r := image.Rect(0, 0, 600, 400)
+		ls, p, err := sw.TextToRect(r)
+		if err != nil {
+			t.Fatalf("TextToRect failed: %v", err)
+		}
+		if len(ls) == 0 || len(ls[0].(floatedLine).placedFloats()) != 1 {
+			t.Fatalf("expected the float on the first line got %q", lineTexts(ls))
+		}
+		if p.Y != r.Max.Y {
+			t.Errorf("page ends at %v, want the bottom of the rect", p)
+		}
+		i := image.NewRGBA(r)
+		if err := sw.RenderLines(i, ls, image.Point{}); err != nil {
+			t.Fatalf("RenderLines failed: %v", err)
+		}
+		if c := i.RGBAAt(25, 399); c != (color.RGBA{R: 255, A: 255}) {
+			t.Errorf("expected the float down to the bottom of the page got %v", c)
+		}
+	})
+}
@@ -24,7 +24,7 @@ type Line interface {
 	// PopSpaceFor will push box at the end, if there isn't enough width, it will make width space.
 	PopSpaceFor(sf *SimpleFolder, r image.Rectangle, box Box) (int, error)
 	// setStats Sets the page stats
-	setStats(lineNumber int, pageNumber int, boxOffset int, currentPageBoxOffset int)
+	setStats(lineNumber int, pageNumber int, boxOffset int, currentPageBoxOffset int, runeOffset int)
 }
 
 // Folder is the literal line sizer & producer function
@@ -42,6 +42,32 @@ type SimpleLine struct {
 	fontDrawer             *font.Drawer
 	stats                  *LinePositionStats
 	horizontalLinePosition HorizontalLinePosition
+	// width is the width available to the line, the container's less any floats beside it and the list indent
+	width int
+	// container is the width of what the line was folded in, which xoffset and width are within
+	container int
+	// xoffset is how far the line is moved right by left floats and the list indent
+	xoffset int
+	// narrowed is set if floats are beside the line or it is indented
+	narrowed bool
+	// floats are the floats placed at the top of the line
+	floats []*placedFloat
+	// top is the line's distance from the top of the shape it was folded into
+	top int
+	// shaped is set if the line was folded into a shape
+	shaped bool
+	// footnote is set if the line is part of the footnotes at the bottom of a page
+	footnote bool
+	// hardBreak is set if the line ends with a line break rather than being wrapped
+	hardBreak bool
+	// source is the source line the line is part of counting from 1, 0 if it isn't numbered
+	source int
+	// number is drawn in the gutter beside the line, nil if nothing is
+	number *GutterBox
+	// hangLeft and hangRight are how far the line protrudes into the margins
+	hangLeft, hangRight fixed.Int26_6
+	// grid is how far the baseline was moved down onto the baseline grid
+	grid fixed.Int26_6
 }
 
 // Ensures that the interface is filled
@@ -58,15 +84,38 @@ func (l *SimpleLine) getHorizontalLinePosition() HorizontalLinePosition {
 }
 
 // setStats Sets the page stats
-func (l *SimpleLine) setStats(lineNumber int, pageNumber int, boxOffset int, currentPageBoxOffset int) {
+func (l *SimpleLine) setStats(lineNumber int, pageNumber int, boxOffset int, currentPageBoxOffset int, runeOffset int) {
 	l.stats = &LinePositionStats{
 		LineNumber:    lineNumber,
 		PageNumber:    pageNumber,
 		PageBoxOffset: currentPageBoxOffset,
 		WordOffset:    boxOffset,
+		RuneOffset:    runeOffset,
 	}
 }
 
+// PositionStats returns the page stats
+func (l *SimpleLine) PositionStats() *LinePositionStats {
+	return l.stats
+}
+
+// positionedLine is a line which knows where it is in the wider document
+type positionedLine interface {
+	// PositionStats returns the position of the line in the wider document, nil until the line is placed
+	PositionStats() *LinePositionStats
+}
+
+// Ensures that the interface is filled
+var _ positionedLine = (*SimpleLine)(nil)
+
+// linePositionStats the position of l in the wider document, nil if it hasn't been placed or doesn't know
+func linePositionStats(l Line) *LinePositionStats {
+	if pl, ok := l.(positionedLine); ok {
+		return pl.PositionStats()
+	}
+	return nil
+}
+
 // PopSpaceFor will push box at the end, if there isn't enough width, it will make width space.
 func (l *SimpleLine) PopSpaceFor(sf *SimpleFolder, r image.Rectangle, box Box) (int, error) {
 	ar := box.AdvanceRect()
@@ -165,8 +214,12 @@ func (l *SimpleLine) DrawLine(i Image, options ...DrawOption) error {
 		Max: bounds.Min,
 	}
 	config := NewDrawConfig(options...)
+	if config.Selection != nil {
+		drawSelection(i, l, config)
+	}
 	r.Max.Y = bounds.Max.Y
-	var fi = fixed.I(r.Min.X)
+	// The image starts at the whole pixel left of the text's protrusion
+	var fi = fixed.I(r.Min.X+l.hangLeft.Ceil()) - l.hangLeft
 	for bi, b := range l.boxes {
 		fi += b.AdvanceRect()
 		r.Max.X = fi.Round()
@@ -187,7 +240,7 @@ func (l *SimpleLine) DrawLine(i Image, options ...DrawOption) error {
 	if l.boxLine {
 		DrawBox(i, bounds, config)
 	}
-	return nil
+	return config.err
 }
 
 // OverflowMode Ways of describing overflow
@@ -211,6 +264,31 @@ type SimpleFolder struct {
 	yOverflow      OverflowMode
 	// Last object on the last line before a page break if it isn't the last page
 	pageBreakBox Box
+	// y is the height of the lines folded so far
+	y int
+	// floats are the floats lines may still need to be folded around
+	floats []*activeFloat
+	// floatBottom is the lowest point of any float placed
+	floatBottom int
+	floatGap    int
+	// shape is the shape lines are folded into, if any
+	shape Shape
+	// band is the band of the shape lines are being folded into
+	band *shapeBand
+	// widestSpan folds only into the widest span of each band
+	widestSpan bool
+	// taken are the boxes taken from the boxer for the line being folded into a shape, so it can be rolled back
+	taken []Box
+	// indent is how far lines are indented from the container by the list item they are in
+	indent int
+	// fields are what fields resolve to, nil leaves them unresolved
+	fields *fieldState
+	// overflowWrap is the hyphen words too wide for a line are broken with, nil leaves them to overflow
+	overflowWrap *string
+	// broken is the last word broken by breakWord, so it can be put back whole if its line is rolled back
+	broken *brokenWord
+	// opticalMargin is how far characters at the edges of lines hang into the margins, nil if they don't
+	opticalMargin *OpticalMargin
 }
 
 // NewSimpleFolder constructs a SimpleFolder applies options provided.
@@ -228,15 +306,122 @@ func NewSimpleFolder(boxer Boxer, container image.Rectangle, lastFontDrawer *fon
 
 // Next generates the next life if space
 func (sf *SimpleFolder) Next(yspace int) (Line, error) {
+	if sf.shape != nil {
+		return sf.nextInShape()
+	}
 	r := sf.NewLine()
+	sf.startLine(r)
+	if ok, err := sf.fill(r, yspace); err != nil {
+		return r, err
+	} else if !ok {
+		return nil, nil
+	}
+	if len(r.boxes) == 0 && len(r.floats) == 0 {
+		return nil, nil
+	}
+	if leb, ok := r.lastBox().(*listEndBox); ok {
+		// The lines after a list go back to the indent before it
+		sf.indent = leb.indent
+	}
+	r.trimEnd()
+	for _, option := range sf.lineOptions {
+		option(r)
+	}
+	sf.y += r.Size().Dy()
+	return r, nil
+}
+
+// fill adds boxes to the line until it is full, returning false if the line was rolled back for not fitting in yspace
+func (sf *SimpleFolder) fill(r *SimpleLine, yspace int) (bool, error) {
 	for {
 		b, i, err := sf.boxer.Next()
 		if err != nil {
-			return nil, fmt.Errorf("boxing at pos %d: %w", sf.boxer.Pos()-i, err)
+			return false, fmt.Errorf("boxing at pos %d: %w", sf.boxer.Pos()-i, err)
 		}
 		if b == nil {
 			break
 		}
+		if sf.shape != nil {
+			sf.taken = append(sf.taken, b)
+		}
+		if side, ok := floatSide(b); ok {
+			if sf.y > 0 && (&placedFloat{Box: b}).size().Y > yspace {
+				// Too tall for the rest of the page so it starts the next, any line before it ending here
+				sf.boxer.Unshift(b)
+				if len(r.boxes) == 0 && len(r.floats) == 0 {
+					return false, nil
+				}
+				sf.untake()
+				break
+			}
+			if placed, ended := sf.addFloat(b, side, r); ended {
+				return true, nil
+			} else if placed {
+				continue
+			}
+			// Doesn't fit beside this line so it starts the next
+			sf.boxer.Unshift(b)
+			sf.untake()
+			break
+		}
+		if _, ok := b.(blockBox); ok && (len(r.boxes) > 0 || len(r.floats) > 0) {
+			// Blocks start a line of their own
+			sf.boxer.Unshift(b)
+			sf.untake()
+			break
+		}
+		if lib, ok := b.(*ListItemBox); ok {
+			if len(r.boxes) > 0 {
+				// Items start a line of their own
+				sf.boxer.Unshift(b)
+				sf.untake()
+				break
+			}
+			sf.setIndent(r, lib.indent)
+		}
+		if leb, ok := b.(*listEndBox); ok {
+			if len(r.boxes) == 0 {
+				sf.setIndent(r, leb.indent)
+				continue
+			}
+			// Kept on the line so it goes back with it if the line is rolled back
+			r.Push(b, 0)
+			return true, nil
+		}
+		if trb, ok := b.(*TableRowBox); ok && sf.y == 0 {
+			headers, err := trb.headers(r.width, yspace)
+			if err != nil {
+				return false, fmt.Errorf("repeating headers at pos %d: %w", sf.boxer.Pos(), err)
+			}
+			if len(headers) > 0 {
+				// A table carried on to a new page repeats its header rows
+				sf.boxer.Unshift(append(headers, b)...)
+				sf.untake()
+				continue
+			}
+		}
+		if sf.fields != nil {
+			if sb, ok := b.(*sectionBox); ok {
+				sf.fields.section = sb.title
+			}
+			// Resolved before fitting so the line is folded with the text the field resolves to here
+			if b, err = sf.fields.resolveFields(b); err != nil {
+				return false, fmt.Errorf("resolving field at pos %d: %w", sf.boxer.Pos(), err)
+			}
+		}
+		if wf, ok := b.(widthFitter); ok {
+			if b, err = wf.fitWidth(r.width); err != nil {
+				return false, fmt.Errorf("fitting box at pos %d: %w", sf.boxer.Pos(), err)
+			}
+		}
+		var placed, ended bool
+		if b, placed, ended = sf.collapseSpaceBox(b, r); placed {
+			if ended {
+				return true, nil
+			}
+			continue
+		}
+		b = sf.breakWord(b, r)
 
 		if r.Size().Dy() < b.MetricsRect().Height.Ceil() {
 			rollbackLine := false
@@ -250,26 +435,41 @@ func (sf *SimpleFolder) Next(yspace int) (Line, error) {
 				}
 			}
 			if rollbackLine {
-				sf.boxer.Push(r.boxes...)
-				sf.boxer.Push(b)
-				return nil, nil
+				sf.unfold(r, b)
+				return false, nil
 			}
 		}
 		done, err := sf.fitAddBox(i, b, r)
 		if err != nil {
-			return r, err
+			return false, err
 		}
 		if done {
-			break
+			if _, ok := b.(*LineBreakBox); ok {
+				r.hardBreak = true
+			} else {
+				// fitAddBox pushed it back for the next line
+				sf.untake()
+			}
+			return true, nil
+		}
+		if _, ok := b.(blockBox); ok {
+			return true, nil
 		}
 	}
-	if len(r.boxes) == 0 {
-		return nil, nil
-	}
-	for _, option := range sf.lineOptions {
-		option(r)
+	return true, nil
+}
+
+// unfold rolls the line back, putting its floats and boxes followed by boxes back on the front of the queue as they
+// were taken from it before anything still in it, with any word broken across them whole again
+func (sf *SimpleFolder) unfold(l Line, boxes ...Box) {
+	var all []Box
+	if fl, ok := l.(floatedLine); ok {
+		for _, f := range fl.placedFloats() {
+			all = append(all, f.Box)
+		}
 	}
-	return r, nil
+	all = append(append(all, l.Boxes()...), boxes...)
+	sf.boxer.Unshift(sf.unbreak(all)...)
 }
 
 // NewLine constructs a new simple line. (Later to be a factory proxy)
@@ -278,6 +478,8 @@ func (sf *SimpleFolder) NewLine() *SimpleLine {
 		boxes:      []Box{},
 		size:       fixed.R(0, 0, 0, 0),
 		fontDrawer: sf.lastFontDrawer,
+		width:      sf.container.Dx(),
+		container:  sf.container.Dx(),
 	}
 }
 
@@ -297,7 +499,7 @@ func (sf *SimpleFolder) fitAddBox(i int, b Box, l *SimpleLine) (bool, error) {
 		// irdx (Integers) is not precise enough for strict accumulation
 		currentWidthFixed := l.size.Max.X - l.size.Min.X
 		newTotalWidthFixed := currentWidthFixed + a
-		if newTotalWidthFixed.Ceil() > sf.container.Dx() {
+		if newTotalWidthFixed.Ceil() > l.width && !noWrap(b) && !sf.hangsInMargin(l, b, newTotalWidthFixed) {
 			if b.Whitespace() {
 				b = &LineBreakBox{
 					Box: b,
@@ -307,7 +509,7 @@ func (sf *SimpleFolder) fitAddBox(i int, b Box, l *SimpleLine) (bool, error) {
 				// If line is empty, we must add the box even if it overflows to prevent infinite loop/dropping.
 				// We do nothing here, falling through to l.Push(b, a) works.
 			} else {
-				sf.boxer.Push(b)
+				sf.boxer.Unshift(b)
 				done = true
 				return done, nil
 			}
@@ -315,9 +517,9 @@ func (sf *SimpleFolder) fitAddBox(i int, b Box, l *SimpleLine) (bool, error) {
 	case *ImageBox:
 		irdx := a.Ceil()
 		szdx := (l.size.Max.X - l.size.Min.X).Ceil()
-		cdx := sf.container.Dx()
+		cdx := l.width
 		if irdx+szdx >= cdx {
-			sf.boxer.Push(b)
+			sf.boxer.Unshift(b)
 			done = true
 			return done, nil
 		}
@@ -326,12 +528,15 @@ func (sf *SimpleFolder) fitAddBox(i int, b Box, l *SimpleLine) (bool, error) {
 	return done, nil
 }
 
-// Size is the size consumed of the line
+// Size is the size consumed of the line. Where the line hangs into the left margin it starts left of 0, so 0 is the left
+// edge of the text. What hangs into the right margin is left out, so a line folded to hang fits its width.
 func (l *SimpleLine) Size() image.Rectangle {
 	return image.Rectangle{
-		Min: image.Point{},
+		Min: image.Point{
+			X: -l.hangLeft.Ceil(),
+		},
 		Max: image.Point{
-			X: (l.size.Max.X - l.size.Min.X).Ceil(),
+			X: (l.size.Max.X - l.size.Min.X - l.hangLeft - l.hangRight).Ceil(),
 			Y: (l.size.Max.Y - l.size.Min.Y).Ceil(),
 		},
 	}
@@ -0,0 +1,364 @@
+package wordwrap
+
+import (
+	"fmt"
+	"image"
+	"image/draw"
+	"strconv"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+// FootnoteGroup is a footnote, returned by Footnote
+type FootnoteGroup Group
+
+// Footnote returns a FootnoteGroup. Where it is a superscript number refers to the footnote, its args are the footnote's
+// body, any rich content, which is placed at the bottom of the page the reference is on. Footnotes are numbered in the
+// order they are given from 1.
+func Footnote(args ...interface{}) interface{} {
+	return FootnoteGroup{Args: args}
+}
+
+// footnoteContent is the body of a footnote
+type footnoteContent struct {
+	number   int
+	contents []*Content
+}
+
+// runeLen the number of runes in the footnote's body
+func (fc *footnoteContent) runeLen() int {
+	n := 0
+	for _, c := range fc.contents {
+		n += c.runeLen()
+	}
+	return n
+}
+
+// FootnoteBox is the superscript number referring to a footnote. It stands for the runes of the footnote's body, which
+// the wrapper places at the bottom of the page the reference is on.
+type FootnoteBox struct {
+	// Number is the footnote's number
+	Number int
+	// text is the number
+	text Box
+	// raise is how far the number is raised above the baseline
+	raise fixed.Int26_6
+	// gap is the space after the number, before the body of a footnote
+	gap fixed.Int26_6
+	// body is the boxes of the footnote's body, nil for the number before the body
+	body  *BoxList
+	runes int
+}
+
+// Interface enforcement
+var _ Box = (*FootnoteBox)(nil)
+
+// AdvanceRect width of the number
+func (fb *FootnoteBox) AdvanceRect() fixed.Int26_6 {
+	return fb.text.AdvanceRect() + fb.gap
+}
+
+// MetricsRect the metrics of the number raised above the baseline
+func (fb *FootnoteBox) MetricsRect() font.Metrics {
+	m := fb.text.MetricsRect()
+	m.Ascent += fb.raise
+	m.Descent = max(m.Descent-fb.raise, 0)
+	m.Height = m.Ascent + m.Descent
+	return m
+}
+
+// Whitespace if this is a white space or not
+func (fb *FootnoteBox) Whitespace() bool {
+	return false
+}
+
+// DrawBox draws the number raised above the baseline
+func (fb *FootnoteBox) DrawBox(i Image, y fixed.Int26_6, dc *DrawConfig) {
+	fb.text.DrawBox(i, y-fb.raise, dc)
+}
+
+// FontDrawer font used
+func (fb *FootnoteBox) FontDrawer() *font.Drawer {
+	return fb.text.FontDrawer()
+}
+
+// Len the length of the footnote's body
+func (fb *FootnoteBox) Len() int {
+	return fb.runes
+}
+
+// TextValue the number
+func (fb *FootnoteBox) TextValue() string {
+	return fb.text.TextValue()
+}
+
+func (fb *FootnoteBox) MinSize() (fixed.Int26_6, fixed.Int26_6) {
+	return 0, 0
+}
+
+func (fb *FootnoteBox) MaxSize() (fixed.Int26_6, fixed.Int26_6) {
+	return 0, 0
+}
+
+// footnoteBox boxes the reference to a footnote and the footnote's body, which starts with the number again
+func (sb *SimpleBoxer) footnoteBox(c *Content) (*FootnoteBox, error) {
+	fc := c.footnote
+	drawer := sb.contentDrawer(c)
+	text, err := NewSimpleTextBox(drawer, strconv.Itoa(fc.number))
+	if err != nil {
+		return nil, err
+	}
+	fb := &FootnoteBox{
+		Number: fc.number,
+		text:   text,
+		raise:  drawer.Face.Metrics().Ascent / 3,
+	}
+	child := sb.child(fc.contents)
+	body, err := NewBoxList(child)
+	if err != nil {
+		return nil, err
+	}
+	number := *fb
+	_, number.gap = drawer.BoundString(" ")
+	body.boxes = append([]Box{&number}, body.boxes...)
+	body.starts = append([]int{0}, body.starts...)
+	fb.body = body
+	fb.runes = body.end
+	return fb, nil
+}
+
+// footnotesIn the footnotes referred to on the line and the rune offset of each, for a line starting at runeOffset
+func footnotesIn(l Line, runeOffset int) []*pendingNote {
+	var notes []*pendingNote
+	pos := runeOffset
+	for bi, b := range l.Boxes() {
+		pos += floatRunesBefore(l, bi)
+		if fb, ok := b.(*FootnoteBox); ok && fb.body != nil {
+			notes = append(notes, &pendingNote{
+				boxer: fb.body.Boxer(),
+				start: pos,
+			})
+		}
+		pos += b.Len()
+	}
+	return notes
+}
+
+// pendingNote a footnote yet to be placed, or the rest of one carried on from the last page
+type pendingNote struct {
+	boxer *BoxListBoxer
+	// start is the rune offset of the footnote's body
+	start int
+	// heights are the heights of the note's lines at the width of the page
+	heights []int
+}
+
+// footnoteArea the footnotes placed at the bottom of a page
+type footnoteArea struct {
+	sw    *SimpleWrapper
+	width int
+	notes []*pendingNote
+}
+
+// newFootnoteArea the footnote area of a page width wide, starting with the footnotes carried on from the last page
+func (sw *SimpleWrapper) newFootnoteArea(width int) (*footnoteArea, error) {
+	fa := &footnoteArea{
+		sw:    sw,
+		width: width,
+	}
+	if err := fa.add(sw.footnotes); err != nil {
+		return nil, err
+	}
+	return fa, nil
+}
+
+// add measures the notes and adds them to the page
+func (fa *footnoteArea) add(notes []*pendingNote) error {
+	for _, n := range notes {
+		cp := *n.boxer
+		cp.queue = append([]Box(nil), n.boxer.queue...)
+		sf := fa.folder(&cp)
+		n.heights = nil
+		for {
+			l, err := sf.Next(unboundedCell)
+			if err != nil {
+				return fmt.Errorf("footnote: %w", err)
+			}
+			if l == nil {
+				break
+			}
+			n.heights = append(n.heights, l.Size().Dy())
+		}
+		fa.notes = append(fa.notes, n)
+	}
+	return nil
+}
+
+// folder folds the boxes of a note to the width of the page
+func (fa *footnoteArea) folder(boxer Boxer) *SimpleFolder {
+	sf := NewSimpleFolder(boxer, image.Rect(0, 0, fa.width, unboundedCell), fa.sw.fontDrawer, fa.sw.folderOptions...)
+	sf.fields = &fa.sw.fields
+	return sf
+}
+
+// rule the separator between the text of the page and its footnotes
+func (fa *footnoteArea) rule() *SimpleLine {
+	h := fa.sw.fontDrawer.Face.Metrics().Height / 2
+	l := &SimpleLine{
+		fontDrawer: fa.sw.fontDrawer,
+		width:      fa.width,
+		container:  fa.width,
+		footnote:   true,
+	}
+	rb := &footnoteRuleBox{
+		width:  fa.width / 3,
+		height: h,
+		src:    fa.sw.fontDrawer.Src,
+	}
+	l.Push(rb, rb.AdvanceRect())
+	return l
+}
+
+// ruleHeight the height of the separator
+func (fa *footnoteArea) ruleHeight() int {
+	return (fa.sw.fontDrawer.Face.Metrics().Height / 2).Ceil()
+}
+
+// height the space the footnotes take with all the lines of the notes before from placed, and only the first line of
+// the rest
+func (fa *footnoteArea) height(from int) int {
+	if len(fa.notes) == 0 {
+		return 0
+	}
+	h := fa.ruleHeight()
+	for ni, n := range fa.notes {
+		for li, lh := range n.heights {
+			if ni >= from && li > 0 {
+				break
+			}
+			h += lh
+		}
+	}
+	return h
+}
+
+// drop removes the notes from from
+func (fa *footnoteArea) drop(from int) {
+	fa.notes = fa.notes[:from]
+}
+
+// place folds as many lines of the notes as fit in space, the first always being placed so the page isn't empty if
+// force is set, returning the lines, the rune offset each starts at and the notes left over for the next page
+func (fa *footnoteArea) place(space int, force bool) ([]Line, []int, []*pendingNote, error) {
+	if len(fa.notes) == 0 {
+		return nil, nil, nil, nil
+	}
+	space -= fa.ruleHeight()
+	ls := []Line{fa.rule()}
+	offsets := []int{fa.notes[0].start + fa.notes[0].boxer.Pos()}
+	for ni, n := range fa.notes {
+		sf := fa.folder(n.boxer)
+		for _, lh := range n.heights {
+			if lh > space && !(force && len(ls) == 1) {
+				if len(ls) == 1 {
+					// No room for even the rule and a line
+					return nil, nil, fa.notes[ni:], nil
+				}
+				return ls, offsets, fa.notes[ni:], nil
+			}
+			pos := n.start + n.boxer.Pos()
+			l, err := sf.Next(unboundedCell)
+			if err != nil {
+				return nil, nil, nil, fmt.Errorf("footnote: %w", err)
+			}
+			if l == nil {
+				break
+			}
+			l.(*SimpleLine).footnote = true
+			ls = append(ls, l)
+			offsets = append(offsets, pos)
+			space -= lh
+		}
+	}
+	return ls, offsets, nil, nil
+}
+
+// footnoteRuleBox the rule separating footnotes from the text of the page
+type footnoteRuleBox struct {
+	width  int
+	height fixed.Int26_6
+	src    image.Image
+}
+
+// Interface enforcement
+var _ Box = (*footnoteRuleBox)(nil)
+
+func (rb *footnoteRuleBox) AdvanceRect() fixed.Int26_6 {
+	return fixed.I(rb.width)
+}
+
+func (rb *footnoteRuleBox) MetricsRect() font.Metrics {
+	return font.Metrics{
+		Height:  rb.height,
+		Ascent:  rb.height / 2,
+		Descent: rb.height - rb.height/2,
+	}
+}
+
+func (rb *footnoteRuleBox) Whitespace() bool {
+	return false
+}
+
+// DrawBox draws a one pixel rule at the middle of the box
+func (rb *footnoteRuleBox) DrawBox(i Image, y fixed.Int26_6, dc *DrawConfig) {
+	b := i.Bounds()
+	y0 := b.Min.Y + y.Floor()
+	draw.Draw(i, image.Rect(b.Min.X, y0, b.Max.X, y0+1), rb.src, image.Point{}, draw.Over)
+}
+
+func (rb *footnoteRuleBox) FontDrawer() *font.Drawer {
+	return nil
+}
+
+func (rb *footnoteRuleBox) Len() int {
+	return 0
+}
+
+func (rb *footnoteRuleBox) TextValue() string {
+	return ""
+}
+
+func (rb *footnoteRuleBox) MinSize() (fixed.Int26_6, fixed.Int26_6) {
+	return 0, 0
+}
+
+func (rb *footnoteRuleBox) MaxSize() (fixed.Int26_6, fixed.Int26_6) {
+	return 0, 0
+}
+
+// footnoteLine is a line of the footnotes at the bottom of a page
+type footnoteLine interface {
+	isFootnote() bool
+}
+
+// Ensures that the interface is filled
+var _ footnoteLine = (*SimpleLine)(nil)
+
+// isFootnote true if the line is part of the footnotes at the bottom of the page
+func (l *SimpleLine) isFootnote() bool {
+	return l.footnote
+}
+
+// footnotesHeight the height of the footnote lines, which come after the rest of the lines of a page
+func footnotesHeight(ls []Line) (int, int) {
+	h := 0
+	for i := len(ls) - 1; i >= 0; i-- {
+		fl, ok := ls[i].(footnoteLine)
+		if !ok || !fl.isFootnote() {
+			return i + 1, h
+		}
+		h += ls[i].Size().Dy()
+	}
+	return 0, h
+}
@@ -0,0 +1,164 @@
+package wordwrap
+
+import (
+	"image"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func footnoteLines(ls []Line) []string {
+	var texts []string
+	for _, l := range ls {
+		if l.(*SimpleLine).footnote {
+			texts = append(texts, l.TextValue())
+		}
+	}
+	return texts
+}
+
+func TestFootnote(t *testing.T) {
+	fontFace := FontFace16DPI180ForTest(t)
+	text := strings.Repeat("The quick brown fox jumps over the lazy dog. ", 12)
+
+	t.Run("Placed at the bottom", func(t *testing.T) {
+		sw := NewRichWrapper(fontFace, "Text", Footnote("First note"), " and", Footnote("Second note"), " more")
+		bounds := image.Rect(0, 0, 600, 600)
+		ls, p, err := sw.TextToRect(bounds)
+		if err != nil {
+			t.Fatalf("TextToRect failed: %v", err)
+		}
+		if s := cmp.Diff([]string{"Text1 and2 more", "", "1First note", "2Second note"}, lineTexts(ls)); s != "" {
+			t.Fatalf("lines differ:\n%s", s)
+		}
+		if s := cmp.Diff([]string{"", "1First note", "2Second note"}, footnoteLines(ls)); s != "" {
+			t.Errorf("footnotes differ:\n%s", s)
+		}
+		fb := ls[0].Boxes()[1].(*FootnoteBox)
+		if fb.Number != 1 || fb.MetricsRect().Ascent <= fb.text.MetricsRect().Ascent {
+			t.Errorf("expected footnote 1 raised above the text got %d", fb.Number)
+		}
+		rs := sw.lineRects(ls, bounds, image.Point{})
+		if rs[3].Max.Y != 600 || rs[1].Min.Y <= rs[0].Max.Y {
+			t.Errorf("expected the footnotes at the bottom got %v", rs)
+		}
+		if p.Y != rs[0].Dy()+rs[1].Dy()+rs[2].Dy()+rs[3].Dy() {
+			t.Errorf("expected the end to include the footnotes got %v", p)
+		}
+		if stats := linePositionStats(ls[2]); stats.RuneOffset != 4 || linePositionStats(ls[3]).RuneOffset != 4+10+4 {
+			t.Errorf("footnote rune offsets %d %d", stats.RuneOffset, linePositionStats(ls[3]).RuneOffset)
+		}
+	})
+
+	t.Run("Reserves space", func(t *testing.T) {
+		bounds := image.Rect(0, 0, 600, 400)
+		plain, _, err := NewRichWrapper(fontFace, text).TextToRect(bounds)
+		if err != nil {
+			t.Fatalf("TextToRect failed: %v", err)
+		}
+		sw := NewRichWrapper(fontFace, "Start", Footnote("A note"), " "+text)
+		ls, _, err := sw.TextToRect(bounds)
+		if err != nil {
+			t.Fatalf("TextToRect failed: %v", err)
+		}
+		notes := len(footnoteLines(ls))
+		if notes != 2 || len(ls)-notes >= len(plain) {
+			t.Errorf("expected fewer lines of text than the %d without footnotes got %d and %d footnote lines", len(plain), len(ls)-notes, notes)
+		}
+		h := 0
+		for _, l := range ls {
+			h += l.Size().Dy()
+		}
+		if h > 400 {
+			t.Errorf("lines and footnotes take %d, more than the page", h)
+		}
+	})
+
+	t.Run("Line carried on for its footnote keeps its float", func(t *testing.T) {
+		ls, _, err := NewRichWrapper(fontFace, "Some text").TextToRect(image.Rect(0, 0, 600, 1000))
+		if err != nil || len(ls) != 1 {
+			t.Fatalf("TextToRect failed: %v", err)
+		}
+		h := ls[0].Size().Dy()
+		sw := NewRichWrapper(fontFace, "Some text\n", Float(FloatLeft, ImageContent{Image: floatImage(10, 10)}), "more", Footnote("A note"), " text")
+		// The second line fits but its footnote doesn't fit below it
+		bounds := image.Rect(0, 0, 600, 2*h+h/2)
+		floats := 0
+		var body []string
+		for page := 0; sw.HasNext(); page++ {
+			if page > 5 {
+				t.Fatalf("too many pages")
+			}
+			ls, _, err := sw.TextToRect(bounds)
+			if err != nil {
+				t.Fatalf("TextToRect failed: %v", err)
+			}
+			for _, l := range ls {
+				floats += len(l.(floatedLine).placedFloats())
+				if !l.(*SimpleLine).footnote {
+					body = append(body, l.TextValue())
+				}
+			}
+		}
+		if floats != 1 {
+			t.Errorf("expected the float placed once got %d", floats)
+		}
+		if s := cmp.Diff([]string{"Some text\n", "more1 text"}, body); s != "" {
+			t.Errorf("lines differ:\n%s", s)
+		}
+	})
+
+	t.Run("Long footnotes carry on", func(t *testing.T) {
+		sw := NewRichWrapper(fontFace, "Start", Footnote(text), " "+text)
+		bounds := image.Rect(0, 0, 600, 400)
+		var body, notes []string
+		for page := 0; sw.HasNext(); page++ {
+			ls, _, err := sw.TextToRect(bounds)
+			if err != nil {
+				t.Fatalf("TextToRect failed: %v", err)
+			}
+			h := 0
+			for _, l := range ls {
+				h += l.Size().Dy()
+				if l.(*SimpleLine).footnote {
+					notes = append(notes, l.TextValue())
+				} else {
+					body = append(body, l.TextValue())
+				}
+			}
+			if h > 400 {
+				t.Errorf("page %d takes %d", page, h)
+			}
+			if page > 10 {
+				t.Fatalf("too many pages")
+			}
+		}
+		if s := cmp.Diff(strings.Fields("Start1 "+text), strings.Fields(strings.Join(body, ""))); s != "" {
+			t.Errorf("text differs:\n%s", s)
+		}
+		if s := cmp.Diff(strings.Fields("1"+text), strings.Fields(strings.Join(notes, ""))); s != "" {
+			t.Errorf("footnote differs:\n%s", s)
+		}
+	})
+
+	t.Run("Draws the rule", func(t *testing.T) {
+		sw := NewRichWrapper(fontFace, "Text", Footnote("Note"))
+		ls, _, err := sw.TextToRect(image.Rect(0, 0, 600, 300))
+		if err != nil {
+			t.Fatalf("TextToRect failed: %v", err)
+		}
+		i := image.NewRGBA(image.Rect(0, 0, 600, 300))
+		if err := sw.RenderLines(i, ls, image.Point{}); err != nil {
+			t.Fatalf("RenderLines failed: %v", err)
+		}
+		r := sw.lineRects(ls, i.Bounds(), image.Point{})[1]
+		y := r.Min.Y + ls[1].YValue()
+		if i.RGBAAt(10, y).A == 0 || i.RGBAAt(190, y).A == 0 {
+			t.Errorf("expected a rule at %d", y)
+		}
+		if i.RGBAAt(210, y).A != 0 {
+			t.Errorf("expected the rule to take a third of the width")
+		}
+	})
+}
@@ -0,0 +1,116 @@
+package wordwrap
+
+import (
+	"context"
+	"fmt"
+	"image"
+)
+
+// Frame is one of the rectangles, or shapes, a FrameChain flows a story through
+type Frame struct {
+	// Rect is the rectangle the frame's lines are folded into
+	Rect image.Rectangle
+	// Shape if set is folded into instead of Rect
+	Shape Shape
+	// Page is the page the frame is on, for the caller to pick which frames to render onto which image
+	Page int
+	// Options apply to this frame only, such as HorizontalLinePosition, HorizontalBlockPosition or VerticalBlockPosition
+	Options []WrapperOption
+}
+
+// Bounds the rectangle of the frame
+func (f Frame) Bounds() image.Rectangle {
+	if f.Shape != nil {
+		return f.Shape.Bounds()
+	}
+	return f.Rect
+}
+
+// FrameLayout is the lines laid out in a frame
+type FrameLayout struct {
+	Frame Frame
+	Lines []Line
+	// End is the point the frame's lines ended
+	End image.Point
+}
+
+// FrameChain flows one story through a sequence of frames of any size, each one continuing from where the last
+// finished, like the linked text frames of a magazine layout
+type FrameChain struct {
+	wrapper *SimpleWrapper
+	Frames  []Frame
+}
+
+// NewFrameChain creates a FrameChain flowing the wrapper's text through the frames in order
+func NewFrameChain(sw *SimpleWrapper, frames ...Frame) *FrameChain {
+	return &FrameChain{
+		wrapper: sw,
+		Frames:  frames,
+	}
+}
+
+// Layout lays out the frames in order, returning the lines of each and whether there was text left over once the last
+// frame was full
+func (fc *FrameChain) Layout(ops ...FitterOption) ([]FrameLayout, bool, error) {
+	return fc.LayoutContext(context.Background(), ops...)
+}
+
+// LayoutContext is Layout which gives up with the context's error if it is done before the layout is finished
+func (fc *FrameChain) LayoutContext(ctx context.Context, ops ...FitterOption) ([]FrameLayout, bool, error) {
+	sw := fc.wrapper
+	fls := make([]FrameLayout, 0, len(fc.Frames))
+	for fi, f := range fc.Frames {
+		fl := FrameLayout{
+			Frame: f,
+			End:   f.Bounds().Min,
+		}
+		if sw.HasNext() {
+			var err error
+			restore := sw.applyScoped(f.Options)
+			if f.Shape != nil {
+				fl.Lines, fl.End, err = sw.TextToShapeContext(ctx, f.Shape, ops...)
+			} else {
+				fl.Lines, fl.End, err = sw.TextToRectContext(ctx, f.Rect, ops...)
+			}
+			restore()
+			if err != nil {
+				return nil, false, fmt.Errorf("frame %d: %w", fi, err)
+			}
+		}
+		fls = append(fls, fl)
+	}
+	return fls, sw.HasNext(), nil
+}
+
+// Render draws the lines of each frame at the frame, with the frame's options. Pass only the layouts of the frames on
+// the page being drawn.
+func (fc *FrameChain) Render(i Image, fls []FrameLayout, options ...DrawOption) error {
+	sw := fc.wrapper
+	for fi, fl := range fls {
+		b := fl.Frame.Bounds()
+		restore := sw.applyScoped(fl.Frame.Options)
+		err := sw.RenderLines(i.SubImage(b).(Image), fl.Lines, b.Min, options...)
+		restore()
+		if err != nil {
+			return fmt.Errorf("frame %d: %w", fi, err)
+		}
+	}
+	return nil
+}
+
+// applyScoped applies the options until the returned function is called, other than boxer options which only apply
+// to boxers created while they are in place
+func (sw *SimpleWrapper) applyScoped(ops []WrapperOption) (restore func()) {
+	if len(ops) == 0 {
+		return func() {}
+	}
+	config, values := sw.wrapperConfig, sw.fields.values
+	// Capped so options appended while in place don't write into the arrays of the options restored
+	config.folderOptions = config.folderOptions[:len(config.folderOptions):len(config.folderOptions)]
+	config.boxerOptions = config.boxerOptions[:len(config.boxerOptions):len(config.boxerOptions)]
+	sw.wrapperConfig = config
+	sw.ApplyOptions(ops...)
+	return func() {
+		sw.wrapperConfig, sw.fields.values = config, values
+	}
+}
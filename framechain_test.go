@@ -0,0 +1,130 @@
+package wordwrap
+
+import (
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func frameWords(fls []FrameLayout) []string {
+	var words []string
+	for _, fl := range fls {
+		words = append(words, strings.Fields(strings.Join(lineTexts(fl.Lines), ""))...)
+	}
+	return words
+}
+
+func TestFrameChain(t *testing.T) {
+	fontFace := FontFace16DPI180ForTest(t)
+	text := strings.Repeat("The quick brown fox jumps over the lazy dog. ", 8)
+	frames := []Frame{
+		{Rect: image.Rect(0, 0, 300, 150)},
+		{Rect: image.Rect(350, 0, 800, 200), Options: []WrapperOption{RightLines, BottomBlock}},
+		{Shape: Circle(image.Pt(300, 600), 300), Page: 1},
+	}
+
+	t.Run("Flows through the frames", func(t *testing.T) {
+		sw := NewRichWrapper(fontFace, text)
+		fls, overflow, err := NewFrameChain(sw, frames...).Layout()
+		if err != nil {
+			t.Fatalf("Layout failed: %v", err)
+		}
+		if overflow {
+			t.Errorf("expected the text to fit")
+		}
+		if len(fls) != 3 {
+			t.Fatalf("expected 3 frame layouts got %d", len(fls))
+		}
+		for fi, fl := range fls {
+			if len(fl.Lines) == 0 {
+				t.Errorf("frame %d has no lines", fi)
+			}
+		}
+		if s := cmp.Diff(strings.Fields(text), frameWords(fls)); s != "" {
+			t.Errorf("words differ:\n%s", s)
+		}
+		if sw.horizontalBlockPosition != LeftBLock || sw.verticalBlockPosition != TopBLock || len(sw.folderOptions) != 0 {
+			t.Errorf("frame options were left on the wrapper")
+		}
+
+		i := image.NewRGBA(image.Rect(0, 0, 800, 1200))
+		if err := NewFrameChain(sw, frames...).Render(i, fls[:2]); err != nil {
+			t.Fatalf("Render failed: %v", err)
+		}
+		inked := func(r image.Rectangle) bool {
+			for y := r.Min.Y; y < r.Max.Y; y++ {
+				for x := r.Min.X; x < r.Max.X; x++ {
+					if i.At(x, y) != (color.RGBA{}) {
+						return true
+					}
+				}
+			}
+			return false
+		}
+		if !inked(frames[0].Rect) || !inked(frames[1].Rect) {
+			t.Errorf("expected both frames on the page to be drawn")
+		}
+		if inked(image.Rect(300, 0, 350, 200)) || inked(image.Rect(0, 200, 800, 1200)) {
+			t.Errorf("expected nothing drawn outside the frames")
+		}
+		// The second frame is aligned to its own bottom right
+		lr := sw.lineRects(fls[1].Lines, frames[1].Rect, frames[1].Rect.Min)
+		if last := lr[len(lr)-1]; last.Max.X < 790 {
+			t.Errorf("expected the second frame's lines right aligned got %v", last)
+		}
+	})
+
+	t.Run("Frame options stay with their frame", func(t *testing.T) {
+		scoped := []Frame{
+			{Rect: image.Rect(0, 0, 300, 150), Options: []WrapperOption{BaselineGrid(37), VerticalJustify{}, LineNumbers{Width: 40}}},
+			{Rect: image.Rect(350, 0, 800, 200)},
+		}
+		sw := NewRichWrapper(fontFace, text)
+		fls, _, err := NewFrameChain(sw, scoped...).Layout()
+		if err != nil {
+			t.Fatalf("Layout failed: %v", err)
+		}
+		if len(fls[0].Lines) == 0 || len(fls[1].Lines) == 0 {
+			t.Fatalf("expected lines in both frames got %v", fls)
+		}
+		if fls[0].Lines[0].(*SimpleLine).width != scoped[0].Rect.Dx()-40 {
+			t.Errorf("expected the first frame folded beside the gutter got width %d", fls[0].Lines[0].(*SimpleLine).width)
+		}
+		for li, l := range fls[1].Lines {
+			if sl := l.(*SimpleLine); sl.grid != 0 || sl.width != scoped[1].Rect.Dx() {
+				t.Errorf("line %d of the second frame was snapped by %v or folded to %d", li, sl.grid, sl.width)
+			}
+		}
+		if sw.gutter != nil || sw.baselineGrid != 0 || sw.verticalBlockPosition != TopBLock || sw.verticalJustify != (VerticalJustify{}) {
+			t.Errorf("frame options were left on the wrapper")
+		}
+	})
+
+	t.Run("Overflow", func(t *testing.T) {
+		sw := NewRichWrapper(fontFace, text)
+		fls, overflow, err := NewFrameChain(sw, frames[:2]...).Layout()
+		if err != nil {
+			t.Fatalf("Layout failed: %v", err)
+		}
+		if !overflow {
+			t.Errorf("expected text left over")
+		}
+		if len(fls) != 2 {
+			t.Errorf("expected 2 frame layouts got %d", len(fls))
+		}
+	})
+
+	t.Run("Frames after the text are empty", func(t *testing.T) {
+		sw := NewRichWrapper(fontFace, "Short")
+		fls, overflow, err := NewFrameChain(sw, frames...).Layout()
+		if err != nil {
+			t.Fatalf("Layout failed: %v", err)
+		}
+		if overflow || len(fls) != 3 || len(fls[0].Lines) != 1 || len(fls[1].Lines) != 0 || len(fls[2].Lines) != 0 {
+			t.Errorf("unexpected layout %v %v", fls, overflow)
+		}
+	})
+}
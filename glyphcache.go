@@ -0,0 +1,168 @@
+package wordwrap
+
+import (
+	"container/list"
+	"image"
+	"image/draw"
+	"sync"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+// GlyphCache is a DrawOption that caches rasterized glyph masks so repeatedly rendered text doesn't rasterize every
+// glyph again. Glyphs are keyed by face, rune, and the sub pixel offset they were drawn at. The cache is safe for
+// concurrent use and can be shared between any number of boxes and renders. Glyphs are rasterized outside of its lock,
+// so a face drawn with from several goroutines has to be safe for that itself, like a LockedFace. When full the least
+// recently used glyph is dropped. Faces which can't be compared, and so can't be keys, are drawn without the cache.
+type GlyphCache struct {
+	mu      sync.Mutex
+	size    int
+	entries map[glyphKey]*list.Element
+	order   *list.List
+}
+
+// glyphKey identifies a glyph mask
+type glyphKey struct {
+	face font.Face
+	r    rune
+	// fx and fy are the fractional part of the dot the glyph was drawn at
+	fx, fy fixed.Int26_6
+}
+
+// glyphEntry a cached glyph mask
+type glyphEntry struct {
+	key glyphKey
+	// dr is the glyph's rectangle relative to the integer part of the dot
+	dr      image.Rectangle
+	mask    *image.Alpha
+	advance fixed.Int26_6
+}
+
+// DefaultGlyphCacheSize is the number of glyphs NewGlyphCache holds if given a size of 0 or less
+const DefaultGlyphCacheSize = 4096
+
+// NewGlyphCache creates a glyph cache holding at most size glyphs
+func NewGlyphCache(size int) *GlyphCache {
+	if size <= 0 {
+		size = DefaultGlyphCacheSize
+	}
+	return &GlyphCache{
+		size:    size,
+		entries: map[glyphKey]*list.Element{},
+		order:   list.New(),
+	}
+}
+
+// Apply installs the glyph cache
+func (gc *GlyphCache) Apply(config *DrawConfig) {
+	config.GlyphCache = gc
+}
+
+// Interface enforcement
+var _ DrawOption = (*GlyphCache)(nil)
+
+// Len the number of glyphs currently cached
+func (gc *GlyphCache) Len() int {
+	gc.mu.Lock()
+	defer gc.mu.Unlock()
+	return gc.order.Len()
+}
+
+// DrawString draws s the same way as d.DrawString, using cached glyph masks where possible. d.Dot is advanced past the
+// string.
+func (gc *GlyphCache) DrawString(d *font.Drawer, s string) {
+	gc.drawString(d, d.Face, s)
+}
+
+// drawString draws s with d, whose face is key as mapped by the draw config. Glyphs are cached against key so they are
+// shared by every face it is mapped to, such as the LockedFace or copy of it each RenderPages worker draws with.
+func (gc *GlyphCache) drawString(d *font.Drawer, key font.Face, s string) {
+	if !comparableFace(key) {
+		d.DrawString(s)
+		return
+	}
+	prevC := rune(-1)
+	for _, c := range s {
+		if prevC >= 0 {
+			d.Dot.X += d.Face.Kern(prevC, c)
+		}
+		e := gc.glyph(key, d.Face, d.Dot, c)
+		if e.mask != nil {
+			dr := e.dr.Add(image.Pt(d.Dot.X.Floor(), d.Dot.Y.Floor()))
+			draw.DrawMask(d.Dst, dr, d.Src, image.Point{}, e.mask, e.mask.Rect.Min, draw.Over)
+		}
+		d.Dot.X += e.advance
+		prevC = c
+	}
+}
+
+// glyph returns the cached glyph of key for r drawn at dot, rasterizing it with face, which draws the same as key, if it
+// isn't cached
+func (gc *GlyphCache) glyph(key, face font.Face, dot fixed.Point26_6, r rune) *glyphEntry {
+	k := glyphKey{
+		face: key,
+		r:    r,
+		fx:   dot.X - fixed.I(dot.X.Floor()),
+		fy:   dot.Y - fixed.I(dot.Y.Floor()),
+	}
+	if e := gc.lookup(k); e != nil {
+		return e
+	}
+	// Rasterized without holding the lock so other renders aren't held up by a miss
+	e := &glyphEntry{
+		key: k,
+	}
+	keep := func(dr image.Rectangle, mask image.Image, maskp image.Point, advance fixed.Int26_6, _ bool) {
+		e.dr = dr
+		e.advance = advance
+		if !dr.Empty() && mask != nil {
+			// Faces reuse their mask buffers, so a copy is kept
+			e.mask = image.NewAlpha(image.Rectangle{Max: dr.Size()})
+			draw.Draw(e.mask, e.mask.Rect, mask, maskp, draw.Src)
+		}
+	}
+	// Rasterize at the same sub pixel offset from the origin so the mask can be reused at any integer position
+	dot = fixed.Point26_6{X: k.fx, Y: k.fy}
+	if lf, ok := face.(*LockedFace); ok {
+		// Copied once while locked rather than by LockedFace.Glyph and again here
+		lf.withGlyph(dot, r, keep)
+	} else {
+		keep(face.Glyph(dot, r))
+	}
+	gc.mu.Lock()
+	defer gc.mu.Unlock()
+	if el, ok := gc.entries[k]; ok {
+		// Another render rasterized it in the meantime
+		gc.order.MoveToFront(el)
+		return el.Value.(*glyphEntry)
+	}
+	gc.entries[k] = gc.order.PushFront(e)
+	for gc.order.Len() > gc.size {
+		oldest := gc.order.Back()
+		gc.order.Remove(oldest)
+		delete(gc.entries, oldest.Value.(*glyphEntry).key)
+	}
+	return e
+}
+
+// lookup returns the cached glyph for k, marking it as the most recently used, or nil if it isn't cached
+func (gc *GlyphCache) lookup(k glyphKey) *glyphEntry {
+	gc.mu.Lock()
+	defer gc.mu.Unlock()
+	if el, ok := gc.entries[k]; ok {
+		gc.order.MoveToFront(el)
+		return el.Value.(*glyphEntry)
+	}
+	return nil
+}
+
+// drawString draws s with the glyph cache from the draw config if there is one, d's face being face as mapped by the
+// draw config
+func drawString(d *font.Drawer, face font.Face, s string, dc *DrawConfig) {
+	if dc != nil && dc.GlyphCache != nil {
+		dc.GlyphCache.drawString(d, face, s)
+		return
+	}
+	d.DrawString(s)
+}
@@ -0,0 +1,133 @@
+package wordwrap
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+func TestGlyphCache(t *testing.T) {
+	fontFace := FontFace16DPI180ForTest(t)
+	text := "The quick brown fox jumps over the lazy dog. Pack my box with five dozen liquor jugs."
+	render := func(t *testing.T, options ...DrawOption) *image.RGBA {
+		sw := NewRichWrapper(fontFace, text, Color(color.RGBA{200, 30, 30, 255}, " Red text."))
+		img := image.NewRGBA(image.Rect(0, 0, 600, 600))
+		lines, _, err := sw.TextToRect(img.Bounds())
+		if err != nil {
+			t.Fatalf("TextToRect failed: %v", err)
+		}
+		if err := sw.RenderLines(img, lines, img.Bounds().Min, options...); err != nil {
+			t.Fatalf("RenderLines failed: %v", err)
+		}
+		return img
+	}
+	want := render(t)
+
+	t.Run("Identical to uncached rendering", func(t *testing.T) {
+		gc := NewGlyphCache(0)
+		for i := 0; i < 2; i++ {
+			got := render(t, gc)
+			if !bytes.Equal(want.Pix, got.Pix) {
+				t.Fatalf("render %d with glyph cache differs from uncached render", i)
+			}
+		}
+		if gc.Len() == 0 {
+			t.Errorf("expected glyphs to be cached")
+		}
+	})
+
+	t.Run("Bounded", func(t *testing.T) {
+		gc := NewGlyphCache(5)
+		got := render(t, gc)
+		if !bytes.Equal(want.Pix, got.Pix) {
+			t.Errorf("render with small glyph cache differs from uncached render")
+		}
+		if gc.Len() != 5 {
+			t.Errorf("Len() = %d, want 5", gc.Len())
+		}
+	})
+
+	t.Run("Face that can't be a key", func(t *testing.T) {
+		gc := NewGlyphCache(0)
+		face := sliceFace{Face: fontFace, tags: []string{"not comparable"}}
+		dst := image.NewRGBA(image.Rect(0, 0, 200, 50))
+		d := &font.Drawer{Dst: dst, Src: image.Black, Face: face, Dot: fixed.P(0, 30)}
+		gc.DrawString(d, "abc")
+		if d.Dot.X != font.MeasureString(fontFace, "abc") {
+			t.Errorf("dot advanced to %v, want %v", d.Dot.X, font.MeasureString(fontFace, "abc"))
+		}
+		if gc.Len() != 0 {
+			t.Errorf("Len() = %d, want it drawn without caching", gc.Len())
+		}
+	})
+
+	t.Run("Concurrent use", func(t *testing.T) {
+		gc := NewGlyphCache(20)
+		// Workers share the face through a LockedFace as RenderPages does
+		face := NewLockedFace(fontFace)
+		var wg sync.WaitGroup
+		for i := 0; i < 4; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				for j := 0; j < 200; j++ {
+					dot := fixed.Point26_6{X: fixed.Int26_6(j % 64), Y: fixed.I(i)}
+					if e := gc.glyph(fontFace, face, dot, rune('a'+j%26)); e.advance == 0 {
+						t.Errorf("missing advance for %c", rune('a'+j%26))
+					}
+				}
+			}(i)
+		}
+		wg.Wait()
+		if gc.Len() != 20 {
+			t.Errorf("Len() = %d, want 20", gc.Len())
+		}
+	})
+
+	t.Run("Hits while another glyph is rasterized", func(t *testing.T) {
+		gc := NewGlyphCache(0)
+		gc.glyph(fontFace, fontFace, fixed.Point26_6{}, 'a')
+		face := &blockingFace{Face: fontFace, started: make(chan struct{}), release: make(chan struct{})}
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			gc.glyph(face, face, fixed.Point26_6{}, 'b')
+		}()
+		<-face.started
+		hit := make(chan struct{})
+		go func() {
+			defer close(hit)
+			gc.glyph(fontFace, fontFace, fixed.Point26_6{}, 'a')
+		}()
+		select {
+		case <-hit:
+		case <-time.After(5 * time.Second):
+			t.Errorf("cache hit waited on another glyph being rasterized")
+		}
+		close(face.release)
+		<-done
+		<-hit
+		if gc.Len() != 2 {
+			t.Errorf("Len() = %d, want 2", gc.Len())
+		}
+	})
+}
+
+// blockingFace is a face whose glyphs aren't rasterized until it is released
+type blockingFace struct {
+	font.Face
+	started chan struct{}
+	release chan struct{}
+}
+
+func (bf *blockingFace) Glyph(dot fixed.Point26_6, r rune) (image.Rectangle, image.Image, image.Point, fixed.Int26_6, bool) {
+	close(bf.started)
+	<-bf.release
+	return bf.Face.Glyph(dot, r)
+}
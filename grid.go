@@ -0,0 +1,53 @@
+package wordwrap
+
+import (
+	"log"
+
+	"golang.org/x/image/math/fixed"
+)
+
+// BaselineGrid is a WrapperOption which snaps the baseline of each line down to the next multiple of it from the top
+// of the rect, so baselines line up across columns and pages even where lines are in larger fonts. Lines folded into a
+// shape and footnotes are left as they are.
+type BaselineGrid int
+
+// Reports interface adherence
+var _ WrapperOption = BaselineGrid(0)
+
+// ApplyWrapperConfig sets the grid
+func (bg BaselineGrid) ApplyWrapperConfig(wr interface{}) {
+	if wr, ok := wr.(interface{ setBaselineGrid(BaselineGrid) }); ok {
+		wr.setBaselineGrid(bg)
+	} else {
+		log.Printf("can't apply")
+	}
+}
+
+// setBaselineGrid sets the grid
+func (sw *SimpleWrapper) setBaselineGrid(bg BaselineGrid) {
+	sw.baselineGrid = bg
+}
+
+// griddedLine is a line whose baseline can be moved down onto the baseline grid
+type griddedLine interface {
+	// snapBaseline moves the baseline down to the next multiple of grid, given how far the top of the line is from the
+	// top of the grid, returning how far it was moved
+	snapBaseline(top int, grid BaselineGrid) fixed.Int26_6
+}
+
+// Ensures that the interface is filled
+var _ griddedLine = (*SimpleLine)(nil)
+
+// snapBaseline moves the baseline down to the next multiple of grid from the top of the grid, taking out any space added
+// by snapping it before so it can be snapped again where the line is placed again, returning the space now above it
+func (l *SimpleLine) snapBaseline(top int, grid BaselineGrid) fixed.Int26_6 {
+	l.size.Min.Y += l.grid
+	l.yoffset -= l.grid
+	g := fixed.I(int(grid))
+	baseline := fixed.I(top) + l.yoffset
+	l.grid = (baseline+g-1)/g*g - baseline
+	// The space is added above the line so it is part of its height
+	l.size.Min.Y -= l.grid
+	l.yoffset += l.grid
+	return l.grid
+}
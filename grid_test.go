@@ -0,0 +1,106 @@
+package wordwrap
+
+import (
+	"image"
+	"strings"
+	"testing"
+)
+
+func TestBaselineGrid(t *testing.T) {
+	big := FontFace16DPI180ForTest(t)
+	small := FontFace16DPI75ForTest(t)
+	args := []interface{}{big, "Heading\n", small, strings.Repeat("Body text on the grid. ", 12), big, "\nAnother\n"}
+
+	t.Run("Snaps baselines to the grid", func(t *testing.T) {
+		bounds := image.Rect(0, 10, 400, 2000)
+		sw := NewRichWrapper(append(args, BaselineGrid(30))...)
+		ls, p, err := sw.TextToRect(bounds)
+		if err != nil {
+			t.Fatalf("TextToRect failed: %v", err)
+		}
+		if len(ls) < 4 {
+			t.Fatalf("expected several lines got %q", lineTexts(ls))
+		}
+		height := 0
+		moved := false
+		for li, lr := range sw.lineRects(ls, bounds, bounds.Min) {
+			if baseline := lr.Min.Y - bounds.Min.Y + ls[li].YValue(); baseline%30 != 0 {
+				t.Errorf("line %d %q baseline at %d", li, ls[li].TextValue(), baseline)
+			}
+			if ls[li].(*SimpleLine).grid > 0 {
+				moved = true
+			}
+			height += ls[li].Size().Dy()
+		}
+		if !moved {
+			t.Errorf("expected lines to be moved onto the grid")
+		}
+		if p.Y-bounds.Min.Y != height {
+			t.Errorf("expected the lines to take %d got %d", height, p.Y-bounds.Min.Y)
+		}
+	})
+
+	t.Run("Lines that no longer fit go to the next page", func(t *testing.T) {
+		plain, p, err := NewRichWrapper(args[:2]...).TextToRect(image.Rect(0, 0, 400, 2000))
+		if err != nil {
+			t.Fatalf("TextToRect failed: %v", err)
+		}
+		if len(plain) != 1 {
+			t.Fatalf("expected a line got %q", lineTexts(plain))
+		}
+		sw := NewRichWrapper(append(args[:2:2], BaselineGrid(p.Y+1))...)
+		ls, _, err := sw.TextToRect(image.Rect(0, 0, 400, p.Y))
+		if err != nil {
+			t.Fatalf("TextToRect failed: %v", err)
+		}
+		if len(ls) != 1 {
+			t.Errorf("expected the first line to be kept got %q", lineTexts(ls))
+		}
+		sw = NewRichWrapper(append(args, BaselineGrid(p.Y+1))...)
+		ls, _, err = sw.TextToRect(image.Rect(0, 0, 400, 2*p.Y))
+		if err != nil {
+			t.Fatalf("TextToRect failed: %v", err)
+		}
+		if len(ls) != 1 || !sw.HasNext() {
+			t.Errorf("expected the second line to go to the next page got %q", lineTexts(ls))
+		}
+	})
+
+	t.Run("Lines going to the next page keep their floats", func(t *testing.T) {
+		sw := NewRichWrapper(big, "Some text\n", Float(FloatLeft, ImageContent{Image: floatImage(10, 10)}), "more text\nend", BaselineGrid(50))
+		floats := 0
+		for page := 0; sw.HasNext(); page++ {
+			if page > 5 {
+				t.Fatalf("too many pages")
+			}
+			ls, _, err := sw.TextToRect(image.Rect(0, 0, 600, 100))
+			if err != nil {
+				t.Fatalf("TextToRect failed: %v", err)
+			}
+			for _, l := range ls {
+				floats += len(l.(floatedLine).placedFloats())
+			}
+		}
+		if floats != 1 {
+			t.Errorf("expected the float placed once got %d", floats)
+		}
+	})
+
+	t.Run("Spec heights include the grid space", func(t *testing.T) {
+		plain, err := NewRichWrapper(args...).TextToSpecs(Width(Fixed(400)))
+		if err != nil {
+			t.Fatalf("TextToSpecs failed: %v", err)
+		}
+		res, err := NewRichWrapper(append(args, BaselineGrid(30))...).TextToSpecs(Width(Fixed(400)))
+		if err != nil {
+			t.Fatalf("TextToSpecs failed: %v", err)
+		}
+		height := 0
+		for _, l := range res.Lines {
+			height += l.Size().Dy()
+		}
+		if res.PageSize.Y < height || res.PageSize.Y <= plain.PageSize.Y {
+			t.Errorf("expected the page to fit the %d of lines got %d, %d without the grid", height, res.PageSize.Y, plain.PageSize.Y)
+		}
+	})
+}
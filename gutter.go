@@ -0,0 +1,218 @@
+package wordwrap
+
+import (
+	"fmt"
+	"image"
+	"image/draw"
+	"log"
+	"strconv"
+	"strings"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+// LineNumbers is a WrapperOption which numbers the source lines, those ended by a line break, in a gutter left of the
+// text, such as for code listings. The text is folded into the width left beside the gutter.
+type LineNumbers struct {
+	// Face the numbers are drawn in, the wrapper's font if nil
+	Face font.Face
+	// Color the numbers and rule are drawn in, the wrapper's font colour if nil
+	Color image.Image
+	// Width of the gutter, including the gaps either side of the rule, wide enough for three digits if 0
+	Width int
+	// Rule is the width of the separator rule down the right of the gutter, none if 0
+	Rule int
+	// Start is the number of the first line, 1 if 0
+	Start int
+	// Continuation is drawn in the gutter of the lines a wrapped source line carries on to, nothing if empty
+	Continuation string
+}
+
+// Reports interface adherence
+var _ WrapperOption = LineNumbers{}
+
+// ApplyWrapperConfig installs the gutter
+func (ln LineNumbers) ApplyWrapperConfig(wr interface{}) {
+	if wr, ok := wr.(interface{ setLineNumbers(LineNumbers) }); ok {
+		wr.setLineNumbers(ln)
+	} else {
+		log.Printf("can't apply")
+	}
+}
+
+// setLineNumbers sets the gutter
+func (sw *SimpleWrapper) setLineNumbers(ln LineNumbers) {
+	if sw.fontDrawer == nil && ln.Face == nil {
+		log.Printf("can't apply")
+		return
+	}
+	sw.gutter = newGutter(ln, sw.fontDrawer)
+}
+
+// gutter the line number gutter, its font and sizes worked out as it is set up so they don't change with the wrapper's
+// font, and are only read while laying out and rendering
+type gutter struct {
+	LineNumbers
+	drawer *font.Drawer
+	width  int
+	// gap is the space either side of the rule
+	gap int
+}
+
+// newGutter works out the gutter's font and sizes, starting from the wrapper's font
+func newGutter(ln LineNumbers, base *font.Drawer) *gutter {
+	g := &gutter{
+		LineNumbers: ln,
+		drawer:      &font.Drawer{Src: image.Black},
+	}
+	if base != nil {
+		g.drawer.Src, g.drawer.Face = base.Src, base.Face
+	}
+	if g.Face != nil {
+		g.drawer.Face = g.Face
+	}
+	if g.Color != nil {
+		g.drawer.Src = g.Color
+	}
+	_, gap := g.drawer.BoundString(" ")
+	g.gap = gap.Ceil()
+	g.width = g.Width
+	if g.width == 0 {
+		digits := max(len(strconv.Itoa(g.start())), 3)
+		_, a := g.drawer.BoundString(strings.Repeat("0", digits))
+		g.width = a.Ceil() + 2*g.gap + g.Rule
+	}
+	return g
+}
+
+// start the number of the first line
+func (g *gutter) start() int {
+	if g.Start == 0 {
+		return 1
+	}
+	return g.Start
+}
+
+// gutterWidth the width the gutter takes from the text, 0 if there isn't one
+func (sw *SimpleWrapper) gutterWidth() int {
+	if sw.gutter != nil {
+		return sw.gutter.width
+	}
+	return 0
+}
+
+// GutterBox is a line number, or continuation marker, drawn in the gutter. The BoxRecorder is given one for each line
+// with something in the gutter, with the position stats of the line.
+type GutterBox struct {
+	Box
+	// Number is the number of the source line
+	Number int
+	// Continued is set for the lines a wrapped source line carries on to
+	Continued bool
+}
+
+// numberedLine is a line which may be numbered in the gutter
+type numberedLine interface {
+	// endsSourceLine true if the line ends with a line break rather than being wrapped
+	endsSourceLine() bool
+	// setSourceLine sets the index of the source line the line is part of and what is drawn in the gutter beside it
+	setSourceLine(n int, number *GutterBox)
+	// sourceLine the index of the source line the line is part of, -1 if it isn't numbered, what is drawn in the gutter
+	// beside it, nil if nothing is, and the line's baseline
+	sourceLine() (int, *GutterBox, fixed.Int26_6)
+}
+
+// Ensures that the interface is filled
+var _ numberedLine = (*SimpleLine)(nil)
+
+// endsSourceLine true if the line ends with a line break
+func (l *SimpleLine) endsSourceLine() bool {
+	return l.hardBreak
+}
+
+// setSourceLine sets the index of the source line the line is part of, stored from 1 so 0 is unnumbered
+func (l *SimpleLine) setSourceLine(n int, number *GutterBox) {
+	l.source = n + 1
+	l.number = number
+}
+
+// sourceLine the index of the source line the line is part of, what is drawn in the gutter beside it and its baseline
+func (l *SimpleLine) sourceLine() (int, *GutterBox, fixed.Int26_6) {
+	return l.source - 1, l.number, l.yoffset
+}
+
+// numberLine records the source line the line is part of, lines after a line break starting the next one. The box
+// drawn in the gutter is made here so it is measured while laying out rather than while rendering.
+func (sw *SimpleWrapper) numberLine(l Line) error {
+	nl, ok := l.(numberedLine)
+	if !ok {
+		return nil
+	}
+	if fl, ok := l.(footnoteLine); ok && fl.isFootnote() {
+		return nil
+	}
+	g := sw.gutter
+	text := strconv.Itoa(g.start() + sw.sourceLines)
+	if sw.continued {
+		text = g.Continuation
+	}
+	var gb *GutterBox
+	if text != "" {
+		tb, err := NewSimpleTextBox(g.drawer, text)
+		if err != nil {
+			return fmt.Errorf("line number: %w", err)
+		}
+		gb = &GutterBox{
+			Box:       tb,
+			Number:    g.start() + sw.sourceLines,
+			Continued: sw.continued,
+		}
+	}
+	nl.setSourceLine(sw.sourceLines, gb)
+	if nl.endsSourceLine() {
+		sw.sourceLines++
+		sw.continued = false
+	} else {
+		sw.continued = true
+	}
+	return nil
+}
+
+// drawGutter draws the line numbers and the rule in the gutter at the left of the lines
+func (sw *SimpleWrapper) drawGutter(i Image, ls []Line, at image.Point, dc *DrawConfig) {
+	g := sw.gutter
+	if g == nil {
+		return
+	}
+	right := at.X + g.width - 2*g.gap - g.Rule
+	top, bottom := 0, 0
+	for li, lr := range sw.lineRects(ls, i.Bounds(), at) {
+		nl, ok := ls[li].(numberedLine)
+		if !ok {
+			continue
+		}
+		n, gb, y := nl.sourceLine()
+		if n < 0 {
+			continue
+		}
+		if bottom == 0 {
+			top = lr.Min.Y
+		}
+		bottom = lr.Max.Y
+		if gb == nil {
+			continue
+		}
+		r := image.Rect(right-gb.AdvanceRect().Ceil(), lr.Min.Y, right, lr.Max.Y)
+		gb.DrawBox(i.SubImage(r).(Image), y, dc)
+		if dc.BoxRecorder != nil {
+			if stats := linePositionStats(ls[li]); stats != nil {
+				dc.BoxRecorder(gb, r.Min, r.Max, stats.BoxPositionStats(0))
+			}
+		}
+	}
+	if g.Rule > 0 && bottom > top {
+		x := right + g.gap
+		draw.Draw(i, image.Rect(x, top, x+g.Rule, bottom), g.drawer.Src, image.Point{}, draw.Over)
+	}
+}
@@ -0,0 +1,156 @@
+package wordwrap
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestLineNumbers(t *testing.T) {
+	fontFace := FontFace16DPI180ForTest(t)
+	code := "func main() {\n    fmt.Println(x, \"The quick brown fox jumps over the lazy dog\")\n}\n"
+
+	t.Run("Numbers source lines", func(t *testing.T) {
+		sw := NewRichWrapper(fontFace, code, LineNumbers{Start: 10, Continuation: "+"})
+		ls, _, err := sw.TextToRect(image.Rect(0, 0, 600, 1000))
+		if err != nil {
+			t.Fatalf("TextToRect failed: %v", err)
+		}
+		var got []string
+		sw.RenderLines(image.NewRGBA(image.Rect(0, 0, 600, 1000)), ls, image.Point{}, BoxRecorder(func(box Box, min, max image.Point, bps *BoxPositionStats) {
+			if gb, ok := box.(*GutterBox); ok {
+				got = append(got, gb.TextValue())
+			}
+		}))
+		if len(ls) < 4 {
+			t.Fatalf("expected the long line to wrap got %q", lineTexts(ls))
+		}
+		want := []string{"10", "11"}
+		for range ls[2 : len(ls)-1] {
+			want = append(want, "+")
+		}
+		want = append(want, "12")
+		if s := cmp.Diff(want, got); s != "" {
+			t.Errorf("gutter differs:\n%s", s)
+		}
+	})
+
+	t.Run("Text beside the gutter", func(t *testing.T) {
+		bounds := image.Rect(0, 0, 600, 1000)
+		plain, _, err := NewRichWrapper(fontFace, code).TextToRect(bounds)
+		if err != nil {
+			t.Fatalf("TextToRect failed: %v", err)
+		}
+		sw := NewRichWrapper(fontFace, code, LineNumbers{Width: 150})
+		ls, _, err := sw.TextToRect(bounds)
+		if err != nil {
+			t.Fatalf("TextToRect failed: %v", err)
+		}
+		if len(ls) <= len(plain) {
+			t.Errorf("expected the narrower text to take more lines got %d and %d", len(ls), len(plain))
+		}
+		for li, r := range sw.lineRects(ls, bounds, image.Point{}) {
+			if r.Min.X != 150 || r.Max.X > 600 {
+				t.Errorf("line %d at %v", li, r)
+			}
+		}
+	})
+
+	t.Run("Carries on across pages", func(t *testing.T) {
+		text := strings.Repeat("line\n", 30)
+		sw := NewRichWrapper(fontFace, text, LineNumbers{})
+		var numbers []int
+		for page := 0; sw.HasNext(); page++ {
+			ls, _, err := sw.TextToRect(image.Rect(0, 0, 600, 300))
+			if err != nil {
+				t.Fatalf("TextToRect failed: %v", err)
+			}
+			sw.RenderLines(image.NewRGBA(image.Rect(0, 0, 600, 300)), ls, image.Point{}, BoxRecorder(func(box Box, min, max image.Point, bps *BoxPositionStats) {
+				if gb, ok := box.(*GutterBox); ok {
+					numbers = append(numbers, gb.Number)
+				}
+			}))
+			if page > 20 {
+				t.Fatalf("too many pages")
+			}
+		}
+		for i, n := range numbers {
+			if n != i+1 {
+				t.Fatalf("numbers %v", numbers)
+			}
+		}
+		if len(numbers) != 30 {
+			t.Errorf("expected 30 numbers got %d", len(numbers))
+		}
+	})
+
+	t.Run("Draws the rule", func(t *testing.T) {
+		red := image.NewUniform(color.RGBA{R: 255, A: 255})
+		sw := NewRichWrapper(fontFace, "a\nb\n", LineNumbers{Color: red, Rule: 2})
+		ls, _, err := sw.TextToRect(image.Rect(0, 0, 600, 300))
+		if err != nil {
+			t.Fatalf("TextToRect failed: %v", err)
+		}
+		i := image.NewRGBA(image.Rect(0, 0, 600, 300))
+		if err := sw.RenderLines(i, ls, image.Point{}); err != nil {
+			t.Fatalf("RenderLines failed: %v", err)
+		}
+		g := sw.gutter
+		x := g.width - g.gap - 2
+		y := ls[0].Size().Dy() + 1
+		if i.RGBAAt(x, y) != red.C || i.RGBAAt(x+1, y) != red.C {
+			t.Errorf("expected the rule at %d got %v", x, i.RGBAAt(x, y))
+		}
+		if i.RGBAAt(x+2, y).A != 0 || i.RGBAAt(x-1, y).A != 0 {
+			t.Errorf("expected the rule to be 2 wide")
+		}
+	})
+	t.Run("Block alignment beside the gutter", func(t *testing.T) {
+		sw := NewRichWrapper(fontFace, "a\nbb\n", LineNumbers{}, RightBlock)
+		bounds := image.Rect(0, 0, 600, 300)
+		ls, _, err := sw.TextToRect(bounds)
+		if err != nil {
+			t.Fatalf("TextToRect failed: %v", err)
+		}
+		right := 0
+		for _, lr := range sw.lineRects(ls, bounds, image.Point{}) {
+			right = max(right, lr.Max.X)
+		}
+		if right != bounds.Max.X {
+			t.Errorf("widest line ends at %d want the right of the bounds %d", right, bounds.Max.X)
+		}
+	})
+
+	t.Run("Rendered by parallel workers", func(t *testing.T) {
+		pages := func(t *testing.T) (*SimpleWrapper, []Page) {
+			sw := NewRichWrapper(fontFace, strings.Repeat(code, 10), LineNumbers{Continuation: "+"})
+			var ps []Page
+			for sw.HasNext() {
+				img := image.NewRGBA(image.Rect(0, 0, 600, 300))
+				ls, _, err := sw.TextToRect(img.Bounds())
+				if err != nil {
+					t.Fatalf("TextToRect failed: %v", err)
+				}
+				ps = append(ps, Page{Image: img, Lines: ls, At: img.Bounds().Min})
+			}
+			return sw, ps
+		}
+		sw, want := pages(t)
+		if err := sw.RenderPages(want, Workers(1)); err != nil {
+			t.Fatalf("serial RenderPages failed: %v", err)
+		}
+		sw, got := pages(t)
+		if err := sw.RenderPages(got, Workers(4)); err != nil {
+			t.Fatalf("RenderPages failed: %v", err)
+		}
+		for i := range want {
+			if !bytes.Equal(want[i].Image.(*image.RGBA).Pix, got[i].Image.(*image.RGBA).Pix) {
+				t.Errorf("page %d differs from serial rendering", i)
+			}
+		}
+	})
+}
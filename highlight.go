@@ -0,0 +1,556 @@
+package wordwrap
+
+import (
+	"image/color"
+	"strings"
+	"unicode"
+
+	"golang.org/x/image/font"
+)
+
+// TokenClass is the kind of a token of source code, which a Theme gives a style
+type TokenClass int
+
+const (
+	// PlainToken is anything not in another class, including white space
+	PlainToken TokenClass = iota
+	// KeywordToken a keyword of the language, or a literal such as true or null
+	KeywordToken
+	// TypeToken a built in type or function
+	TypeToken
+	// NameToken a key of JSON or YAML, or a variable of shell
+	NameToken
+	// StringToken a quoted string
+	StringToken
+	// NumberToken a number
+	NumberToken
+	// CommentToken a comment
+	CommentToken
+	// PunctuationToken an operator or punctuation
+	PunctuationToken
+)
+
+// Token is a run of source code of one class
+type Token struct {
+	Class TokenClass
+	Text  string
+}
+
+// Lexer splits source code into tokens, which together are the whole of the source
+type Lexer interface {
+	Lex(src string) []Token
+}
+
+// LexerFunc is a function which is a Lexer
+type LexerFunc func(src string) []Token
+
+// Lex calls the function
+func (f LexerFunc) Lex(src string) []Token {
+	return f(src)
+}
+
+var (
+	// GoLexer lexes Go
+	GoLexer Lexer = LexerFunc(lexGo)
+	// JSONLexer lexes JSON
+	JSONLexer Lexer = LexerFunc(lexJSON)
+	// YAMLLexer lexes YAML
+	YAMLLexer Lexer = LexerFunc(lexYAML)
+	// ShellLexer lexes POSIX shell and bash
+	ShellLexer Lexer = LexerFunc(lexShell)
+)
+
+// LexerFor the lexer of the language with the name or file extension given, nil if there isn't one
+func LexerFor(language string) Lexer {
+	switch strings.ToLower(strings.TrimPrefix(language, ".")) {
+	case "go", "golang":
+		return GoLexer
+	case "json":
+		return JSONLexer
+	case "yaml", "yml":
+		return YAMLLexer
+	case "sh", "shell", "bash":
+		return ShellLexer
+	}
+	return nil
+}
+
+// TokenStyle is how a class of token is drawn
+type TokenStyle struct {
+	// Color of the text, the theme's foreground if nil
+	Color color.Color
+	// Background behind the text, none if nil
+	Background color.Color
+	// Bold and Italic pick the face from the Highlighter's faces
+	Bold   bool
+	Italic bool
+}
+
+// Theme gives the classes of token their styles
+type Theme struct {
+	// Foreground is the colour of plain text
+	Foreground color.Color
+	// Background is the colour the theme is meant to be shown on, such as with PageBackground. It isn't drawn by the
+	// highlighted text.
+	Background color.Color
+	Styles     map[TokenClass]TokenStyle
+}
+
+var (
+	// LightTheme is a theme for a light background
+	LightTheme = Theme{
+		Foreground: color.RGBA{R: 0x24, G: 0x29, B: 0x2e, A: 0xff},
+		Background: color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff},
+		Styles: map[TokenClass]TokenStyle{
+			KeywordToken:     {Color: color.RGBA{R: 0xd7, G: 0x3a, B: 0x49, A: 0xff}, Bold: true},
+			TypeToken:        {Color: color.RGBA{R: 0x00, G: 0x5c, B: 0xc5, A: 0xff}},
+			NameToken:        {Color: color.RGBA{R: 0x6f, G: 0x42, B: 0xc1, A: 0xff}},
+			StringToken:      {Color: color.RGBA{R: 0x03, G: 0x2f, B: 0x62, A: 0xff}},
+			NumberToken:      {Color: color.RGBA{R: 0x00, G: 0x5c, B: 0xc5, A: 0xff}},
+			CommentToken:     {Color: color.RGBA{R: 0x6a, G: 0x73, B: 0x7d, A: 0xff}, Italic: true},
+			PunctuationToken: {Color: color.RGBA{R: 0x58, G: 0x60, B: 0x69, A: 0xff}},
+		},
+	}
+	// DarkTheme is a theme for a dark background
+	DarkTheme = Theme{
+		Foreground: color.RGBA{R: 0xd4, G: 0xd4, B: 0xd4, A: 0xff},
+		Background: color.RGBA{R: 0x1e, G: 0x1e, B: 0x1e, A: 0xff},
+		Styles: map[TokenClass]TokenStyle{
+			KeywordToken:     {Color: color.RGBA{R: 0x56, G: 0x9c, B: 0xd6, A: 0xff}, Bold: true},
+			TypeToken:        {Color: color.RGBA{R: 0x4e, G: 0xc9, B: 0xb0, A: 0xff}},
+			NameToken:        {Color: color.RGBA{R: 0x9c, G: 0xdc, B: 0xfe, A: 0xff}},
+			StringToken:      {Color: color.RGBA{R: 0xce, G: 0x91, B: 0x78, A: 0xff}},
+			NumberToken:      {Color: color.RGBA{R: 0xb5, G: 0xce, B: 0xa8, A: 0xff}},
+			CommentToken:     {Color: color.RGBA{R: 0x6a, G: 0x99, B: 0x55, A: 0xff}, Italic: true},
+			PunctuationToken: {Color: color.RGBA{R: 0xd4, G: 0xd4, B: 0xd4, A: 0xff}},
+		},
+	}
+)
+
+// CodeFaces are the monospace faces code is drawn in
+type CodeFaces struct {
+	// Regular is the face of the code, the wrapper's font if nil
+	Regular font.Face
+	// Bold, Italic and BoldItalic are used for the token styles asking for them, Regular if nil
+	Bold       font.Face
+	Italic     font.Face
+	BoldItalic font.Face
+}
+
+// face the face for the style, nil if the code's face is to be kept
+func (cf CodeFaces) face(ts TokenStyle) font.Face {
+	var f font.Face
+	switch {
+	case ts.Bold && ts.Italic:
+		f = cf.BoldItalic
+	case ts.Bold:
+		f = cf.Bold
+	case ts.Italic:
+		f = cf.Italic
+	}
+	return f
+}
+
+// Highlighter turns source code into rich args styled by token class
+type Highlighter struct {
+	// Lexer splits the code into tokens, all of the code is plain if nil
+	Lexer Lexer
+	Theme Theme
+	Faces CodeFaces
+	// TabWidth is the number of columns between tab stops, 4 if 0
+	TabWidth int
+}
+
+// Args returns the code as args for NewRichWrapper. Tabs are expanded to spaces, as the default tokenizer doesn't box
+// them, and each run of white space is kept as is so indentation survives. Lines are still wrapped where they are too
+// wide.
+func (h Highlighter) Args(src string) []interface{} {
+	tabWidth := h.TabWidth
+	if tabWidth <= 0 {
+		tabWidth = 4
+	}
+	src = expandTabs(src, tabWidth)
+	tokens := []Token{{Class: PlainToken, Text: src}}
+	if h.Lexer != nil {
+		tokens = h.Lexer.Lex(src)
+	}
+	var args []interface{}
+	if h.Faces.Regular != nil {
+		args = append(args, h.Faces.Regular)
+	}
+	if h.Theme.Foreground != nil {
+		args = append(args, TextColor(h.Theme.Foreground))
+	}
+	for _, t := range tokens {
+		ts, ok := h.Theme.Styles[t.Class]
+		if !ok || strings.TrimSpace(t.Text) == "" {
+			args = append(args, t.Text)
+			continue
+		}
+		var group []interface{}
+		if f := h.Faces.face(ts); f != nil {
+			group = append(group, f)
+		}
+		if ts.Color != nil {
+			group = append(group, TextColor(ts.Color))
+		}
+		if ts.Background != nil {
+			group = append(group, BgColor(ts.Background))
+		}
+		args = append(args, Group{Args: append(group, t.Text)})
+	}
+	return args
+}
+
+// expandTabs replaces tabs with spaces to the next tab stop
+func expandTabs(src string, width int) string {
+	if !strings.Contains(src, "\t") {
+		return src
+	}
+	var b strings.Builder
+	column := 0
+	for _, r := range src {
+		switch r {
+		case '\t':
+			n := width - column%width
+			b.WriteString(strings.Repeat(" ", n))
+			column += n
+		case '\n', '\r':
+			b.WriteRune(r)
+			column = 0
+		default:
+			b.WriteRune(r)
+			column++
+		}
+	}
+	return b.String()
+}
+
+// codeScanner splits source into tokens, merging neighbouring tokens of the same class
+type codeScanner struct {
+	src    []rune
+	pos    int
+	tokens []Token
+}
+
+// done true at the end of the source
+func (s *codeScanner) done() bool {
+	return s.pos >= len(s.src)
+}
+
+// at the rune i after the position, 0 past the end
+func (s *codeScanner) at(i int) rune {
+	if s.pos+i >= len(s.src) {
+		return 0
+	}
+	return s.src[s.pos+i]
+}
+
+// has true if the source carries on with prefix
+func (s *codeScanner) has(prefix string) bool {
+	i := 0
+	for _, r := range prefix {
+		if s.at(i) != r {
+			return false
+		}
+		i++
+	}
+	return true
+}
+
+// emit the next n runes as a token of the class
+func (s *codeScanner) emit(class TokenClass, n int) {
+	n = min(n, len(s.src)-s.pos)
+	text := string(s.src[s.pos : s.pos+n])
+	s.pos += n
+	if last := len(s.tokens) - 1; last >= 0 && s.tokens[last].Class == class {
+		s.tokens[last].Text += text
+		return
+	}
+	s.tokens = append(s.tokens, Token{Class: class, Text: text})
+}
+
+// span the number of runes from the position while f is true
+func (s *codeScanner) span(f func(rune) bool) int {
+	n := 0
+	for s.pos+n < len(s.src) && f(s.src[s.pos+n]) {
+		n++
+	}
+	return n
+}
+
+// restOfLine the number of runes to the end of the line
+func (s *codeScanner) restOfLine() int {
+	return s.span(func(r rune) bool { return r != '\n' })
+}
+
+// until the number of runes from start up to and including end, or to the end of the source
+func (s *codeScanner) until(start int, end string) int {
+	e := []rune(end)
+	for n := start; s.pos+n+len(e) <= len(s.src); n++ {
+		if string(s.src[s.pos+n:s.pos+n+len(e)]) == end {
+			return n + len(e)
+		}
+	}
+	return len(s.src) - s.pos
+}
+
+// quoted the number of runes of the string starting with quote, a backslash escaping the next rune if escapes is set.
+// Strings end at the end of the line if they aren't closed.
+func (s *codeScanner) quoted(quote rune, escapes bool) int {
+	n := 1
+	for s.pos+n < len(s.src) {
+		r := s.src[s.pos+n]
+		switch {
+		case r == '\\' && escapes:
+			n++
+		case r == quote:
+			return n + 1
+		case r == '\n' && quote != '`':
+			return n
+		}
+		n++
+	}
+	return min(n, len(s.src)-s.pos)
+}
+
+// number the number of runes of the number at the position
+func (s *codeScanner) number() int {
+	return s.span(func(r rune) bool {
+		return unicode.IsDigit(r) || unicode.IsLetter(r) || r == '.' || r == '_'
+	})
+}
+
+// word the number of runes of the identifier at the position
+func (s *codeScanner) word() int {
+	return s.span(isWordRune)
+}
+
+// space emits the white space at the position
+func (s *codeScanner) space() {
+	s.emit(PlainToken, s.span(unicode.IsSpace))
+}
+
+// isWordRune true for the runes of an identifier
+func isWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// wordSet a set of words
+func wordSet(words string) map[string]bool {
+	set := map[string]bool{}
+	for _, w := range strings.Fields(words) {
+		set[w] = true
+	}
+	return set
+}
+
+var (
+	goKeywords = wordSet(`break case chan const continue default defer else fallthrough for func go goto if import
+		interface map package range return select struct switch type var true false nil iota`)
+	goTypes = wordSet(`any bool byte comparable complex64 complex128 error float32 float64 int int8 int16 int32 int64
+		rune string uint uint8 uint16 uint32 uint64 uintptr append cap clear close complex copy delete imag len make max
+		min new panic print println real recover`)
+	shellKeywords = wordSet(`if then else elif fi case esac for select while until do done in function time return
+		exit break continue export local readonly declare set unset shift source alias`)
+	yamlKeywords = wordSet(`true false null yes no on off True False Null TRUE FALSE NULL ~`)
+)
+
+// lexGo lexes Go
+func lexGo(src string) []Token {
+	s := &codeScanner{src: []rune(src)}
+	for !s.done() {
+		r := s.at(0)
+		switch {
+		case s.has("//"):
+			s.emit(CommentToken, s.restOfLine())
+		case s.has("/*"):
+			s.emit(CommentToken, s.until(2, "*/"))
+		case r == '"' || r == '\'':
+			s.emit(StringToken, s.quoted(r, true))
+		case r == '`':
+			s.emit(StringToken, s.quoted(r, false))
+		case unicode.IsDigit(r) || r == '.' && unicode.IsDigit(s.at(1)):
+			s.emit(NumberToken, s.number())
+		case isWordRune(r):
+			n := s.word()
+			w := string(s.src[s.pos : s.pos+n])
+			switch {
+			case goKeywords[w]:
+				s.emit(KeywordToken, n)
+			case goTypes[w]:
+				s.emit(TypeToken, n)
+			default:
+				s.emit(PlainToken, n)
+			}
+		case unicode.IsSpace(r):
+			s.space()
+		default:
+			s.emit(PunctuationToken, 1)
+		}
+	}
+	return s.tokens
+}
+
+// lexJSON lexes JSON, strings followed by a colon being keys
+func lexJSON(src string) []Token {
+	s := &codeScanner{src: []rune(src)}
+	for !s.done() {
+		r := s.at(0)
+		switch {
+		case r == '"':
+			n := s.quoted(r, true)
+			class := StringToken
+			if s.at(n+s.countFrom(n, unicode.IsSpace)) == ':' {
+				class = NameToken
+			}
+			s.emit(class, n)
+		case unicode.IsDigit(r) || r == '-' && unicode.IsDigit(s.at(1)):
+			s.emit(NumberToken, 1+s.countFrom(1, func(r rune) bool {
+				return unicode.IsDigit(r) || strings.ContainsRune(".eE+-", r)
+			}))
+		case unicode.IsLetter(r):
+			s.emit(KeywordToken, s.word())
+		case unicode.IsSpace(r):
+			s.space()
+		default:
+			s.emit(PunctuationToken, 1)
+		}
+	}
+	return s.tokens
+}
+
+// countFrom the number of runes from i after the position while f is true
+func (s *codeScanner) countFrom(i int, f func(rune) bool) int {
+	n := 0
+	for s.pos+i+n < len(s.src) && f(s.src[s.pos+i+n]) {
+		n++
+	}
+	return n
+}
+
+// lexYAML lexes YAML, a line at a time
+func lexYAML(src string) []Token {
+	s := &codeScanner{src: []rune(src)}
+	lineStart := true
+	for !s.done() {
+		r := s.at(0)
+		switch {
+		case r == '\n':
+			s.emit(PlainToken, 1)
+			lineStart = true
+			continue
+		case unicode.IsSpace(r):
+			s.emit(PlainToken, s.span(func(r rune) bool { return r != '\n' && unicode.IsSpace(r) }))
+			continue
+		case lineStart && (s.has("---") || s.has("...")):
+			s.emit(PunctuationToken, 3)
+		case r == '#':
+			s.emit(CommentToken, s.restOfLine())
+		case r == '-' && (s.at(1) == ' ' || s.at(1) == '\n' || s.at(1) == 0):
+			// A sequence entry, which may start with a key
+			s.emit(PunctuationToken, 1)
+			continue
+		case lineStart && s.yamlKey() > 0:
+			s.emit(NameToken, s.yamlKey())
+			s.emit(PunctuationToken, 1)
+		case r == '"':
+			s.emit(StringToken, s.quoted(r, true))
+		case r == '\'':
+			s.emit(StringToken, s.quoted(r, false))
+		case strings.ContainsRune("[]{},:|>&*!", r):
+			s.emit(PunctuationToken, 1)
+		default:
+			n := s.span(func(r rune) bool { return r != '\n' && r != '#' && !strings.ContainsRune(",]}", r) })
+			// Up to a comment, which has to follow a space
+			for n > 0 && s.at(n) == '#' && !unicode.IsSpace(s.at(n-1)) {
+				n += 1 + s.countFrom(n+1, func(r rune) bool { return r != '\n' && r != '#' })
+			}
+			value := strings.TrimRightFunc(string(s.src[s.pos:s.pos+n]), unicode.IsSpace)
+			n = max(len([]rune(value)), 1)
+			switch {
+			case yamlKeywords[value]:
+				s.emit(KeywordToken, n)
+			case isYAMLNumber(value):
+				s.emit(NumberToken, n)
+			default:
+				s.emit(StringToken, n)
+			}
+		}
+		lineStart = false
+	}
+	return s.tokens
+}
+
+// yamlKey the length of the key at the position, a plain scalar followed by a colon then a space or the end of the line,
+// 0 if there isn't one
+func (s *codeScanner) yamlKey() int {
+	for n := 0; s.pos+n < len(s.src); n++ {
+		switch r := s.at(n); {
+		case r == '\n' || r == '#' || r == '"' || r == '\'':
+			return 0
+		case r == ':' && n > 0 && (s.at(n+1) == ' ' || s.at(n+1) == '\n' || s.at(n+1) == 0):
+			return n
+		}
+	}
+	return 0
+}
+
+// isYAMLNumber true if the value is a number
+func isYAMLNumber(value string) bool {
+	if value == "" {
+		return false
+	}
+	for i, r := range value {
+		if !unicode.IsDigit(r) && !strings.ContainsRune("._eE", r) && !(i == 0 && (r == '-' || r == '+')) {
+			return false
+		}
+	}
+	return unicode.IsDigit(rune(value[len(value)-1]))
+}
+
+// lexShell lexes shell, variables being names
+func lexShell(src string) []Token {
+	s := &codeScanner{src: []rune(src)}
+	wordStart := true
+	for !s.done() {
+		r := s.at(0)
+		switch {
+		case r == '#' && wordStart:
+			s.emit(CommentToken, s.restOfLine())
+		case r == '\'':
+			s.emit(StringToken, s.quoted(r, false))
+		case r == '"':
+			s.emit(StringToken, s.quoted(r, true))
+		case r == '$' && s.at(1) == '{':
+			s.emit(NameToken, s.until(2, "}"))
+		case r == '$' && (isWordRune(s.at(1)) || strings.ContainsRune("@*#?$!-", s.at(1))):
+			n := 2
+			if isWordRune(s.at(1)) {
+				n = 1 + s.countFrom(1, isWordRune)
+			}
+			s.emit(NameToken, n)
+		case unicode.IsSpace(r):
+			s.space()
+			wordStart = true
+			continue
+		case isWordRune(r) || strings.ContainsRune("-./", r):
+			n := s.span(func(r rune) bool { return isWordRune(r) || strings.ContainsRune("-./=", r) })
+			w := string(s.src[s.pos : s.pos+n])
+			switch {
+			case wordStart && shellKeywords[w]:
+				s.emit(KeywordToken, n)
+			case wordStart && isYAMLNumber(w):
+				s.emit(NumberToken, n)
+			default:
+				s.emit(PlainToken, n)
+			}
+		default:
+			s.emit(PunctuationToken, 1)
+			wordStart = strings.ContainsRune("|&;()`", r)
+			continue
+		}
+		wordStart = false
+	}
+	return s.tokens
+}
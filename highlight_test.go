@@ -0,0 +1,117 @@
+package wordwrap
+
+import (
+	"image"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// classesOf the non white space tokens as class:text
+func classesOf(tokens []Token) []string {
+	names := []string{"plain", "keyword", "type", "name", "string", "number", "comment", "punct"}
+	var got []string
+	for _, t := range tokens {
+		if strings.TrimSpace(t.Text) == "" {
+			continue
+		}
+		got = append(got, names[t.Class]+":"+strings.TrimSpace(t.Text))
+	}
+	return got
+}
+
+func TestLexers(t *testing.T) {
+	tests := []struct {
+		name  string
+		lexer Lexer
+		src   string
+		want  []string
+	}{
+		{
+			name:  "Go",
+			lexer: GoLexer,
+			src:   "func f(s string) int { // note\n\treturn len(`a`) + 0x1F /* c */\n}",
+			want:  []string{"keyword:func", "plain:f", "punct:(", "plain:s", "type:string", "punct:)", "type:int", "punct:{", "comment:// note", "keyword:return", "type:len", "punct:(", "string:`a`", "punct:)", "punct:+", "number:0x1F", "comment:/* c */", "punct:}"},
+		},
+		{
+			name:  "JSON",
+			lexer: JSONLexer,
+			src:   `{"a": "b\"c", "n": -1.5e3, "t": [true, null]}`,
+			want:  []string{"punct:{", `name:"a"`, "punct::", `string:"b\"c"`, "punct:,", `name:"n"`, "punct::", "number:-1.5e3", "punct:,", `name:"t"`, "punct::", "punct:[", "keyword:true", "punct:,", "keyword:null", "punct:]}"},
+		},
+		{
+			name:  "YAML",
+			lexer: YAMLLexer,
+			src:   "---\nname: app # the name\nitems:\n  - id: 12\n    url: http://x#y\n  - 'quoted'\nok: true\n",
+			want:  []string{"punct:---", "name:name", "punct::", "string:app", "comment:# the name", "name:items", "punct::", "punct:-", "name:id", "punct::", "number:12", "name:url", "punct::", "string:http://x#y", "punct:-", "string:'quoted'", "name:ok", "punct::", "keyword:true"},
+		},
+		{
+			name:  "Shell",
+			lexer: ShellLexer,
+			src:   "# setup\nif [ -n \"$HOME\" ]; then echo ${PATH} $1 # done\nfi",
+			want:  []string{"comment:# setup", "keyword:if", "punct:[", "plain:-n", `string:"$HOME"`, "punct:];", "keyword:then", "plain:echo", "name:${PATH}", "name:$1", "comment:# done", "keyword:fi"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens := tt.lexer.Lex(tt.src)
+			var all strings.Builder
+			for _, tok := range tokens {
+				all.WriteString(tok.Text)
+			}
+			if all.String() != tt.src {
+				t.Errorf("tokens don't make up the source: %q", all.String())
+			}
+			if s := cmp.Diff(tt.want, classesOf(tokens)); s != "" {
+				t.Errorf("tokens differ:\n%s", s)
+			}
+		})
+	}
+	if got := classesOf(LexerFor(".yml").Lex("a: 1")); !cmp.Equal(got, []string{"name:a", "punct::", "number:1"}) {
+		t.Errorf("expected the YAML lexer got %q", got)
+	}
+	if LexerFor("cobol") != nil {
+		t.Errorf("expected no lexer")
+	}
+}
+
+func TestExpandTabs(t *testing.T) {
+	if got := expandTabs("\tab\tc\n\t\td", 4); got != "    ab  c\n        d" {
+		t.Errorf("expandTabs got %q", got)
+	}
+}
+
+func TestHighlighter(t *testing.T) {
+	fontFace := FontFace16DPI180ForTest(t)
+	src := "func main() {\n\tif x {\n\t\treturn\n\t}\n}\n"
+
+	t.Run("Keeps indentation", func(t *testing.T) {
+		h := Highlighter{Lexer: GoLexer, Theme: DarkTheme}
+		sw := NewRichWrapper(append([]interface{}{fontFace}, h.Args(src)...)...)
+		ls, _, err := sw.TextToRect(image.Rect(0, 0, 800, 1000))
+		if err != nil {
+			t.Fatalf("TextToRect failed: %v", err)
+		}
+		want := []string{"func main() {\n", "    if x {\n", "        return\n", "    }\n", "}\n"}
+		if s := cmp.Diff(want, lineTexts(ls)); s != "" {
+			t.Errorf("lines differ:\n%s", s)
+		}
+	})
+
+	t.Run("Styles tokens", func(t *testing.T) {
+		bold := FontFace16DPI75ForTest(t)
+		h := Highlighter{Lexer: GoLexer, Theme: LightTheme, Faces: CodeFaces{Regular: fontFace, Bold: bold}}
+		contents, _, _, _, _, _ := ProcessRichArgs(h.Args("return x")...)
+		if len(contents) != 2 {
+			t.Fatalf("expected 2 contents got %d", len(contents))
+		}
+		kw := contents[0].style
+		if kw.font != bold || kw.FontDrawerSrc.At(0, 0) != LightTheme.Styles[KeywordToken].Color {
+			t.Errorf("expected the keyword in bold and its colour")
+		}
+		if plain := contents[1].style; plain.font != fontFace || plain.FontDrawerSrc.At(0, 0) != LightTheme.Foreground {
+			t.Errorf("expected plain text in the regular face and the foreground colour")
+		}
+	})
+}
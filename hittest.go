@@ -0,0 +1,131 @@
+package wordwrap
+
+import (
+	"image"
+
+	"golang.org/x/image/math/fixed"
+)
+
+// Hit describes the content found under a point by HitTest
+type Hit struct {
+	// Line is the line the point falls on
+	Line Line
+	// LineIndex is the index of Line in the lines passed to HitTest
+	LineIndex int
+	// Box is the top level box in the line under the point
+	Box Box
+	// BoxIndex is the index of Box within Line.Boxes()
+	BoxIndex int
+	// Rect is where Box was rendered
+	Rect image.Rectangle
+	// TextBox is the innermost text box under the point, if there is one
+	TextBox *SimpleTextBox
+	// ID is the ID of the innermost IDBox found while unwrapping Box, nil if there was none
+	ID interface{}
+	// RuneOffset is the rune boundary within TextBox nearest to the point
+	RuneOffset int
+}
+
+// HitTest finds the line and box under p for lines rendered with RenderLines to an image with the given bounds at the
+// given starting point. Points beside a line resolve to the nearest box of that line. Returns nil if p is not on any
+// line.
+func (sw *SimpleWrapper) HitTest(p image.Point, ls []Line, bounds image.Rectangle, at image.Point) *Hit {
+	for li, lr := range sw.lineRects(ls, bounds, at) {
+		if p.Y < lr.Min.Y || p.Y >= lr.Max.Y {
+			continue
+		}
+		boxes := ls[li].Boxes()
+		rects := boxRects(boxes, lr)
+		if len(rects) == 0 {
+			return nil
+		}
+		bi := 0
+		for i, r := range rects {
+			bi = i
+			if p.X < r.Max.X {
+				break
+			}
+		}
+		h := &Hit{
+			Line:      ls[li],
+			LineIndex: li,
+			Box:       boxes[bi],
+			BoxIndex:  bi,
+			Rect:      rects[bi],
+		}
+		h.hitBox(boxes[bi], fixed.I(p.X-rects[bi].Min.X))
+		return h
+	}
+	return nil
+}
+
+// boxRects calculates the rectangle each box occupies when drawn by SimpleLine.DrawLine into r
+func boxRects(boxes []Box, r image.Rectangle) []image.Rectangle {
+	rs := make([]image.Rectangle, 0, len(boxes))
+	fi := fixed.I(r.Min.X)
+	minX := r.Min.X
+	for _, b := range boxes {
+		fi += b.AdvanceRect()
+		rs = append(rs, image.Rect(minX, r.Min.Y, fi.Round(), r.Max.Y))
+		minX = fi.Round()
+	}
+	return rs
+}
+
+// hitBox descends through box wrappers collecting the ID and text box under x, which is relative to the box start.
+func (h *Hit) hitBox(b Box, x fixed.Int26_6) {
+	switch b := b.(type) {
+	case *IDBox:
+		h.ID = b.id
+		h.hitBox(b.Box, x)
+	case *DecorationBox:
+		h.hitBox(b.Box, x-fixed.I(b.Margin.Min.X.Ceil()+b.Padding.Min.X.Ceil()))
+	case *RowBox:
+		var start fixed.Int26_6
+		for i, c := range b.Boxes {
+			a := c.AdvanceRect()
+			if x < start+a || i == len(b.Boxes)-1 {
+				h.hitBox(c, x-start)
+				return
+			}
+			start += a
+		}
+	case *PageBreakBox:
+		if b.VisualBox != nil {
+			h.hitBox(b.VisualBox, x)
+		}
+	case *SimpleTextBox:
+		h.TextBox = b
+		h.RuneOffset = b.RuneOffsetAt(x)
+	default:
+		if inner := innerBox(b); inner != nil {
+			h.hitBox(inner, x)
+		}
+	}
+}
+
+// innerBox returns the box wrapped by one of the decorating boxes, or nil if b doesn't wrap another box at the same
+// position.
+func innerBox(b Box) Box {
+	switch b := b.(type) {
+	case *LineBreakBox:
+		return b.Box
+	case *BackgroundBox:
+		return b.Box
+	case *EffectBox:
+		return b.Box
+	case *AlignedBox:
+		return b.Box
+	case *MinSizeBox:
+		return b.Box
+	case *IDBox:
+		return b.Box
+	case *FloatBox:
+		return b.Box
+	case *whiteSpaceBox:
+		return b.Box
+	case *FieldBox:
+		return b.text
+	}
+	return nil
+}
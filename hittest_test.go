@@ -0,0 +1,116 @@
+package wordwrap
+
+import (
+	"image"
+	"testing"
+)
+
+func TestSimpleWrapper_HitTest(t *testing.T) {
+	fontFace := FontFace16DPI180ForTest(t)
+	sw := NewRichWrapper(fontFace, "Hello ", ID("link", "World"), " again")
+	bounds := image.Rect(0, 0, 1000, 1000)
+	lines, _, err := sw.TextToRect(bounds)
+	if err != nil {
+		t.Fatalf("TextToRect failed: %v", err)
+	}
+	if len(lines) != 1 {
+		t.Fatalf("expected 1 line got %d", len(lines))
+	}
+	at := image.Pt(10, 20)
+	rects := boxRects(lines[0].Boxes(), sw.lineRects(lines, bounds, at)[0])
+
+	t.Run("Start of first word", func(t *testing.T) {
+		h := sw.HitTest(image.Pt(rects[0].Min.X, rects[0].Min.Y+1), lines, bounds, at)
+		if h == nil {
+			t.Fatalf("expected a hit")
+		}
+		if h.BoxIndex != 0 || h.TextBox == nil || h.TextBox.Contents != "Hello" {
+			t.Errorf("unexpected hit %+v", h)
+		}
+		if h.RuneOffset != 0 {
+			t.Errorf("RuneOffset = %d, want 0", h.RuneOffset)
+		}
+		if h.ID != nil {
+			t.Errorf("ID = %v, want nil", h.ID)
+		}
+	})
+
+	t.Run("End of ID word", func(t *testing.T) {
+		h := sw.HitTest(image.Pt(rects[2].Max.X-1, rects[2].Min.Y+1), lines, bounds, at)
+		if h == nil {
+			t.Fatalf("expected a hit")
+		}
+		if h.TextBox == nil || h.TextBox.Contents != "World" {
+			t.Fatalf("unexpected text box %+v", h.TextBox)
+		}
+		if h.ID != "link" {
+			t.Errorf("ID = %v, want link", h.ID)
+		}
+		if h.RuneOffset != 5 {
+			t.Errorf("RuneOffset = %d, want 5", h.RuneOffset)
+		}
+	})
+
+	t.Run("Middle of word uses glyph advances", func(t *testing.T) {
+		tb := lines[0].Boxes()[0].(*SimpleTextBox)
+		x := rects[0].Min.X + tb.PrefixAdvance(2).Round() + 1
+		h := sw.HitTest(image.Pt(x, rects[0].Min.Y+1), lines, bounds, at)
+		if h == nil || h.RuneOffset != 2 {
+			t.Errorf("expected rune offset 2 got %+v", h)
+		}
+	})
+
+	t.Run("Miss below lines", func(t *testing.T) {
+		if h := sw.HitTest(image.Pt(rects[0].Min.X, 900), lines, bounds, at); h != nil {
+			t.Errorf("expected no hit got %+v", h)
+		}
+	})
+}
+
+func TestSimpleWrapper_HitTestWhiteSpaceNormal(t *testing.T) {
+	fontFace := FontFace16DPI180ForTest(t)
+	sw := NewRichWrapper(fontFace, WhiteSpaceMode(WhiteSpaceNormal, "Hello   World"))
+	bounds := image.Rect(0, 0, 1000, 1000)
+	lines, _, err := sw.TextToRect(bounds)
+	if err != nil {
+		t.Fatalf("TextToRect failed: %v", err)
+	}
+	if len(lines) != 1 {
+		t.Fatalf("expected 1 line got %d", len(lines))
+	}
+	at := image.Pt(10, 20)
+	boxes := lines[0].Boxes()
+	rects := boxRects(boxes, sw.lineRects(lines, bounds, at)[0])
+	last := len(boxes) - 1
+
+	t.Run("Text box found", func(t *testing.T) {
+		h := sw.HitTest(image.Pt(rects[last].Max.X-1, rects[last].Min.Y+1), lines, bounds, at)
+		if h == nil {
+			t.Fatalf("expected a hit")
+		}
+		if h.TextBox == nil || h.TextBox.Contents != "World" {
+			t.Fatalf("unexpected text box %+v", h.TextBox)
+		}
+		if h.RuneOffset != 5 {
+			t.Errorf("RuneOffset = %d, want 5", h.RuneOffset)
+		}
+	})
+
+	t.Run("Selection within a word", func(t *testing.T) {
+		start := len("Hello   ")
+		rs := sw.SelectionRects(lines, bounds, at, start+1, start+3)
+		if len(rs) != 1 {
+			t.Fatalf("expected 1 rect got %v", rs)
+		}
+		tb, _ := textBoxWithin(boxes[last])
+		if tb == nil {
+			t.Fatalf("no text box in %T", boxes[last])
+		}
+		if rs[0].Min.X != rects[last].Min.X+tb.PrefixAdvance(1).Floor() {
+			t.Errorf("rect starts at %d", rs[0].Min.X)
+		}
+		if rs[0].Max.X != rects[last].Min.X+tb.PrefixAdvance(3).Ceil() {
+			t.Errorf("rect ends at %d", rs[0].Max.X)
+		}
+	})
+}
@@ -4,6 +4,9 @@ import (
 	"image"
 	"image/color"
 	"image/draw"
+	"log"
+
+	"golang.org/x/image/font"
 )
 
 // BoxRecorder allows recording of the box's position
@@ -63,6 +66,25 @@ func (s SourceImageMapper) Apply(config *DrawConfig) {
 	config.SourceImageMapper = s
 }
 
+// FaceMapper allows substituting the font face text is drawn and measured with while rendering, such as with a face
+// that is safe to use from the current goroutine
+type FaceMapper func(font.Face) font.Face
+
+// Apply installs the face mapper
+func (f FaceMapper) Apply(config *DrawConfig) {
+	if config.FaceMapper != nil {
+		orig := config.FaceMapper
+		config.FaceMapper = func(face font.Face) font.Face {
+			return f(orig(face))
+		}
+		return
+	}
+	config.FaceMapper = f
+}
+
+// Interface enforcement
+var _ DrawOption = (*FaceMapper)(nil)
+
 // Apply installs the image source mapper
 func (s BoxRecorder) Apply(config *DrawConfig) {
 	if config.BoxRecorder != nil {
@@ -81,10 +103,12 @@ var _ DrawOption = (*BoxRecorder)(nil)
 
 // LinePositionStats numbers to use for pin pointing location
 type LinePositionStats struct {
-	LineNumber    int
-	PageBoxOffset int
-	WordOffset    int
-	PageNumber    int
+	LineNumber    int `json:"lineNumber"`
+	PageBoxOffset int `json:"pageBoxOffset"`
+	WordOffset    int `json:"wordOffset"`
+	PageNumber    int `json:"pageNumber"`
+	// RuneOffset is the rune position in the source contents the line starts at
+	RuneOffset int `json:"runeOffset"`
 }
 
 // BoxPositionStats generates object of same name
@@ -127,6 +151,31 @@ type DrawConfig struct {
 	SourceImageMapper SourceImageMapper
 	BoxDrawMap        BoxDrawMap
 	BoxRecorder       BoxRecorder
+	Selection         *Selection
+	GlyphCache        *GlyphCache
+	FaceMapper        FaceMapper
+	// err is the first error drawing a box, which DrawBox can't return itself
+	err error
+}
+
+// fail records the error drawing a box to be returned once the line is drawn. Without a config to record it on the
+// error can only be logged.
+func (c *DrawConfig) fail(err error) {
+	if c == nil {
+		log.Printf("drawing box: %v", err)
+		return
+	}
+	if c.err == nil {
+		c.err = err
+	}
+}
+
+// face returns the face to draw with in place of f
+func (c *DrawConfig) face(f font.Face) font.Face {
+	if c == nil || c.FaceMapper == nil || f == nil {
+		return f
+	}
+	return c.FaceMapper(f)
 }
 
 // ApplyMap applies the box mapping function used for conditionally rendering or modifying the object being rendered
@@ -0,0 +1,89 @@
+package wordwrap
+
+import (
+	"image"
+	"log"
+)
+
+// VerticalGaps is where VerticalJustify spreads the leftover height
+type VerticalGaps int
+
+const (
+	// BetweenLines spreads the leftover height evenly between all the lines
+	BetweenLines VerticalGaps = iota
+	// BetweenParagraphs spreads the leftover height between the lines ending with a line break, leaving the lines of
+	// each paragraph together
+	BetweenParagraphs
+)
+
+// VerticalJustify is a WrapperOption which justifies the block of text vertically, as JustifyBlock does, spreading
+// the height left below the lines between them when they are rendered so they fill the height of the image.
+type VerticalJustify struct {
+	// Between is where the leftover height goes
+	Between VerticalGaps
+	// MaxGap is the most space added to a gap, past which the lines are left at the top, no limit if 0
+	MaxGap int
+}
+
+// Reports interface adherence
+var _ WrapperOption = VerticalJustify{}
+
+// ApplyWrapperConfig sets the vertical justification
+func (vj VerticalJustify) ApplyWrapperConfig(wr interface{}) {
+	if wr, ok := wr.(interface{ setVerticalJustify(VerticalJustify) }); ok {
+		wr.setVerticalJustify(vj)
+	} else {
+		log.Printf("can't apply")
+	}
+}
+
+// setVerticalJustify sets the vertical justification
+func (sw *SimpleWrapper) setVerticalJustify(vj VerticalJustify) {
+	sw.verticalBlockPosition = JustifyBlock
+	sw.verticalJustify = vj
+}
+
+// verticalSpread the space added above each line to justify the lines vertically between at and the bottom of bounds,
+// or nil if they aren't justified. The lines of a shape and footnotes are left where they are.
+func (sw *SimpleWrapper) verticalSpread(ls []Line, bounds image.Rectangle, at image.Point) []int {
+	if sw.verticalBlockPosition != JustifyBlock {
+		return nil
+	}
+	notesStart, notesHeight := footnotesHeight(ls)
+	body := ls[:notesStart]
+	leftover := bounds.Max.Y - at.Y - notesHeight
+	var gaps []int
+	for li, l := range body {
+		if sl, ok := l.(shapedLine); ok {
+			if _, shaped := sl.shapeTop(); shaped {
+				return nil
+			}
+		}
+		leftover -= l.Size().Dy()
+		if li == len(body)-1 {
+			break
+		}
+		if sw.verticalJustify.Between == BetweenParagraphs {
+			if nl, ok := l.(numberedLine); !ok || !nl.endsSourceLine() {
+				continue
+			}
+		}
+		gaps = append(gaps, li+1)
+	}
+	if len(gaps) == 0 || leftover <= 0 {
+		return nil
+	}
+	gap := leftover / len(gaps)
+	if sw.verticalJustify.MaxGap > 0 && (gap > sw.verticalJustify.MaxGap || gap == sw.verticalJustify.MaxGap && leftover%len(gaps) > 0) {
+		return nil
+	}
+	spread := make([]int, len(ls))
+	for gi, li := range gaps {
+		spread[li] = gap
+		// What doesn't divide evenly goes to the first gaps so the last line ends at the bottom
+		if gi < leftover%len(gaps) {
+			spread[li]++
+		}
+	}
+	return spread
+}
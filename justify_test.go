@@ -0,0 +1,88 @@
+package wordwrap
+
+import (
+	"image"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestVerticalJustify(t *testing.T) {
+	fontFace := FontFace16DPI180ForTest(t)
+	bounds := image.Rect(0, 0, 500, 900)
+
+	layout := func(t *testing.T, text string, options ...interface{}) (*SimpleWrapper, []Line, []image.Rectangle) {
+		sw := NewRichWrapper(append([]interface{}{fontFace, text}, options...)...)
+		ls, _, err := sw.TextToRect(bounds)
+		if err != nil {
+			t.Fatalf("TextToRect failed: %v", err)
+		}
+		return sw, ls, sw.lineRects(ls, bounds, image.Point{})
+	}
+
+	t.Run("Spreads lines", func(t *testing.T) {
+		_, ls, rs := layout(t, "one\ntwo\nthree\nfour", JustifyBlock)
+		if len(ls) != 4 {
+			t.Fatalf("expected 4 lines got %q", lineTexts(ls))
+		}
+		if rs[0].Min.Y != 0 || rs[3].Max.Y != bounds.Max.Y {
+			t.Errorf("expected the lines to fill the height got %v", rs)
+		}
+		gap := rs[1].Min.Y - rs[0].Max.Y
+		for li := 1; li < len(rs); li++ {
+			if g := rs[li].Min.Y - rs[li-1].Max.Y; g < gap-1 || g > gap+1 || g <= 0 {
+				t.Errorf("gap %d is %d not %d", li, g, gap)
+			}
+		}
+	})
+
+	t.Run("Spreads paragraphs", func(t *testing.T) {
+		text := strings.Repeat("The quick brown fox jumps over the lazy dog. ", 2) + "\nEnd\n" + "Last"
+		_, ls, rs := layout(t, text, VerticalJustify{Between: BetweenParagraphs})
+		if len(ls) < 4 {
+			t.Fatalf("expected the first paragraph to wrap got %q", lineTexts(ls))
+		}
+		last := len(rs) - 1
+		if rs[0].Min.Y != 0 || rs[last].Max.Y != bounds.Max.Y {
+			t.Errorf("expected the lines to fill the height got %v", rs)
+		}
+		for li := 1; li < last-1; li++ {
+			if rs[li].Min.Y != rs[li-1].Max.Y {
+				t.Errorf("expected line %d to stay with its paragraph", li)
+			}
+		}
+		if rs[last-1].Min.Y <= rs[last-2].Max.Y || rs[last].Min.Y <= rs[last-1].Max.Y {
+			t.Errorf("expected space between the paragraphs got %v", rs)
+		}
+	})
+
+	t.Run("Falls back to the top past the max gap", func(t *testing.T) {
+		_, _, top := layout(t, "one\ntwo")
+		_, _, rs := layout(t, "one\ntwo", VerticalJustify{MaxGap: 100})
+		if s := cmp.Diff(top, rs); s != "" {
+			t.Errorf("expected the lines at the top:\n%s", s)
+		}
+		_, _, rs = layout(t, strings.Repeat("line\n", 9)+"line", VerticalJustify{MaxGap: 100})
+		if rs[len(rs)-1].Max.Y != bounds.Max.Y {
+			t.Errorf("expected the lines to be spread within the max gap got %v", rs)
+		}
+	})
+
+	t.Run("Reports the spread positions", func(t *testing.T) {
+		sw, ls, rs := layout(t, "one\ntwo\nthree", JustifyBlock)
+		var got []int
+		err := sw.RenderLines(image.NewRGBA(bounds), ls, image.Point{}, BoxRecorder(func(box Box, min, max image.Point, bps *BoxPositionStats) {
+			if box.TextValue() != "" && !box.Whitespace() {
+				got = append(got, min.Y)
+			}
+		}))
+		if err != nil {
+			t.Fatalf("RenderLines failed: %v", err)
+		}
+		want := []int{rs[0].Min.Y, rs[1].Min.Y, rs[2].Min.Y}
+		if s := cmp.Diff(want, got); s != "" {
+			t.Errorf("positions differ:\n%s", s)
+		}
+	})
+}
@@ -0,0 +1,152 @@
+package wordwrap
+
+import (
+	"errors"
+	"fmt"
+	"log"
+)
+
+// ErrLimitExceeded is wrapped by every LimitError so callers can check for any limit with errors.Is
+var ErrLimitExceeded = errors.New("layout limit exceeded")
+
+// LimitError is returned when a layout goes past one of the wrapper's Limits
+type LimitError struct {
+	// Limit is the name of the limit exceeded: "lines", "pages", "boxes" or "pixel area"
+	Limit string
+	// Max is the configured maximum
+	Max int
+}
+
+// Error describes the limit
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("%s limit of %d exceeded", e.Limit, e.Max)
+}
+
+// Unwrap returns ErrLimitExceeded
+func (e *LimitError) Unwrap() error {
+	return ErrLimitExceeded
+}
+
+// Limits is a WrapperOption which bounds how much work laying out may do, for instance when the input is untrusted.
+// Zero values are unlimited.
+type Limits struct {
+	// MaxLines is the most lines a single TextToRect or TextToSpecs pass may produce
+	MaxLines int
+	// MaxPages is the most pages the wrapper may lay out, each TextToRect call being a page. TextToSpecs and
+	// TextToSpecPages lay out from the start of the text, so count only the pages of that call.
+	MaxPages int
+	// MaxBoxes is the most boxes a single TextToRect or TextToSpecs call may read from the boxer
+	MaxBoxes int
+	// MaxPixelArea is the largest page, in pixels, TextToRect may be asked to fill or TextToSpecs may produce
+	MaxPixelArea int
+}
+
+// Reports interface adherence
+var _ WrapperOption = Limits{}
+
+// ApplyWrapperConfig installs the limits
+func (l Limits) ApplyWrapperConfig(wr interface{}) {
+	if wr, ok := wr.(interface{ setLimits(Limits) }); ok {
+		wr.setLimits(l)
+	} else {
+		log.Printf("can't apply")
+	}
+}
+
+// setLimits sets the limits
+func (sw *SimpleWrapper) setLimits(l Limits) {
+	sw.limits = l
+}
+
+// checkLimit returns a LimitError if max is set and value is over it
+func checkLimit(limit string, max int, value int) error {
+	if max > 0 && value > max {
+		return &LimitError{
+			Limit: limit,
+			Max:   max,
+		}
+	}
+	return nil
+}
+
+// startPage counts a page laid out by the wrapper against the page limit
+func (sw *SimpleWrapper) startPage() error {
+	if err := checkLimit("pages", sw.limits.MaxPages, sw.pageCount+1); err != nil {
+		return err
+	}
+	sw.pageCount++
+	return nil
+}
+
+// checkPixelArea returns a LimitError if a page of the size is over the pixel area limit
+func (sw *SimpleWrapper) checkPixelArea(width, height int) error {
+	return checkLimit("pixel area", sw.limits.MaxPixelArea, width*height)
+}
+
+// limitedBoxer counts the boxes its boxer produces against the box limit. Boxes put back and read again are only
+// counted once.
+type limitedBoxer struct {
+	Boxer
+	max   int
+	count int
+	// queued is the number of boxes put back which are still to be read again
+	queued int
+}
+
+// Ensures that limitedBoxer fits model
+var _ Boxer = (*limitedBoxer)(nil)
+
+// limitBoxes wraps boxer so reading more than max boxes is an error, returning boxer as is if max isn't set
+func limitBoxes(boxer Boxer, max int) Boxer {
+	if max <= 0 {
+		return boxer
+	}
+	return &limitedBoxer{
+		Boxer: boxer,
+		max:   max,
+	}
+}
+
+// Next returns the next box, or a LimitError once the boxer has produced more than the limit
+func (lb *limitedBoxer) Next() (Box, int, error) {
+	b, n, err := lb.Boxer.Next()
+	if err != nil || b == nil {
+		return b, n, err
+	}
+	if lb.queued > 0 {
+		lb.queued--
+		return b, n, nil
+	}
+	lb.count++
+	if err := checkLimit("boxes", lb.max, lb.count); err != nil {
+		return nil, 0, err
+	}
+	return b, n, nil
+}
+
+// Push puts boxes back to be read again
+func (lb *limitedBoxer) Push(box ...Box) {
+	lb.queued += len(box)
+	lb.Boxer.Push(box...)
+}
+
+// Unshift puts boxes back to be read again first
+func (lb *limitedBoxer) Unshift(box ...Box) {
+	lb.queued += len(box)
+	lb.Boxer.Unshift(box...)
+}
+
+// Shift removes the first box put back
+func (lb *limitedBoxer) Shift() Box {
+	b := lb.Boxer.Shift()
+	if b != nil && lb.queued > 0 {
+		lb.queued--
+	}
+	return b
+}
+
+// Reset restarts the boxer
+func (lb *limitedBoxer) Reset() {
+	lb.queued = 0
+	lb.Boxer.Reset()
+}
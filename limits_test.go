@@ -0,0 +1,169 @@
+package wordwrap
+
+import (
+	"context"
+	"errors"
+	"image"
+	"strings"
+	"testing"
+)
+
+func TestLayoutContext(t *testing.T) {
+	fontFace := FontFace16DPI180ForTest(t)
+	text := strings.Repeat("The quick brown fox jumps over the lazy dog. ", 100)
+
+	t.Run("Already cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if _, _, err := NewRichWrapper(fontFace, text).TextToRectContext(ctx, image.Rect(0, 0, 400, 400)); !errors.Is(err, context.Canceled) {
+			t.Errorf("TextToRectContext expected context.Canceled got %v", err)
+		}
+		if _, err := NewRichWrapper(fontFace, text).TextToSpecsContext(ctx, Width(Fixed(400))); !errors.Is(err, context.Canceled) {
+			t.Errorf("TextToSpecsContext expected context.Canceled got %v", err)
+		}
+	})
+
+	t.Run("Cancelled between lines", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		tokens := 0
+		tokenizer := Tokenizer(func(text []rune) (int, []rune, int) {
+			tokens++
+			if tokens == 50 {
+				cancel()
+			}
+			return LatinTokenizer(text)
+		})
+		sw := NewRichWrapper(fontFace, text, tokenizer)
+		_, _, err := sw.TextToRectContext(ctx, image.Rect(0, 0, 1, 1000000))
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected context.Canceled got %v", err)
+		}
+		if !sw.HasNext() {
+			t.Errorf("expected the layout to stop before the end of the text")
+		}
+	})
+}
+
+func TestLimits(t *testing.T) {
+	fontFace := FontFace16DPI180ForTest(t)
+	text := strings.Repeat("The quick brown fox jumps over the lazy dog. ", 100)
+	tests := []struct {
+		name   string
+		limits Limits
+		layout func(sw *SimpleWrapper) error
+		want   string
+	}{
+		{
+			name:   "Lines in a narrow rect",
+			limits: Limits{MaxLines: 100},
+			layout: func(sw *SimpleWrapper) error {
+				_, _, err := sw.TextToRect(image.Rect(0, 0, 1, 1000000))
+				return err
+			},
+			want: "lines",
+		},
+		{
+			name:   "Pages",
+			limits: Limits{MaxPages: 2},
+			layout: func(sw *SimpleWrapper) error {
+				for i := 0; i < 3; i++ {
+					if _, _, err := sw.TextToRect(image.Rect(0, 0, 400, 100)); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+			want: "pages",
+		},
+		{
+			name:   "Boxes while boxing for specs",
+			limits: Limits{MaxBoxes: 50},
+			layout: func(sw *SimpleWrapper) error {
+				_, err := sw.TextToSpecs(Width(Fixed(400)))
+				return err
+			},
+			want: "boxes",
+		},
+		{
+			name:   "Boxes on a page",
+			limits: Limits{MaxBoxes: 50},
+			layout: func(sw *SimpleWrapper) error {
+				_, _, err := sw.TextToRect(image.Rect(0, 0, 400, 100000))
+				return err
+			},
+			want: "boxes",
+		},
+		{
+			name:   "Pixel area",
+			limits: Limits{MaxPixelArea: 1000 * 1000},
+			layout: func(sw *SimpleWrapper) error {
+				_, err := sw.TextToSpecs(Width(Fixed(2000)), Height(Fixed(1000)))
+				return err
+			},
+			want: "pixel area",
+		},
+		{
+			name:   "Pixel area of a shape",
+			limits: Limits{MaxPixelArea: 1000 * 1000},
+			layout: func(sw *SimpleWrapper) error {
+				_, _, err := sw.TextToShape(Circle(image.Pt(1000, 1000), 1000))
+				return err
+			},
+			want: "pixel area",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.layout(NewRichWrapper(fontFace, text, tt.limits))
+			if !errors.Is(err, ErrLimitExceeded) {
+				t.Fatalf("expected ErrLimitExceeded got %v", err)
+			}
+			var le *LimitError
+			if !errors.As(err, &le) || le.Limit != tt.want {
+				t.Errorf("expected a %q LimitError got %v", tt.want, err)
+			}
+		})
+	}
+
+	t.Run("Within limits", func(t *testing.T) {
+		sw := NewRichWrapper(fontFace, text, Limits{MaxLines: 1000, MaxPages: 1, MaxBoxes: 10000, MaxPixelArea: 400 * 10000})
+		for i := 0; i < 2; i++ {
+			if _, err := sw.TextToSpecs(Width(Fixed(400))); err != nil {
+				t.Errorf("TextToSpecs call %d failed: %v", i, err)
+			}
+		}
+	})
+
+	t.Run("Pixel area checked before boxing", func(t *testing.T) {
+		tokens := 0
+		tokenizer := Tokenizer(func(text []rune) (int, []rune, int) {
+			tokens++
+			return LatinTokenizer(text)
+		})
+		sw := NewRichWrapper(fontFace, text, tokenizer, Limits{MaxPixelArea: 1000 * 1000})
+		if _, err := sw.TextToSpecs(Width(Fixed(2000)), Height(Fixed(1000))); !errors.Is(err, ErrLimitExceeded) {
+			t.Errorf("TextToSpecs expected ErrLimitExceeded got %v", err)
+		}
+		if _, _, err := sw.TextToRect(image.Rect(0, 0, 2000, 1000)); !errors.Is(err, ErrLimitExceeded) {
+			t.Errorf("TextToRect expected ErrLimitExceeded got %v", err)
+		}
+		if tokens != 0 {
+			t.Errorf("expected the limit to be checked before boxing, tokenized %d times", tokens)
+		}
+	})
+
+	t.Run("Boxes put back count once", func(t *testing.T) {
+		bl, err := NewBoxList(NewRichWrapper(fontFace, text).boxer)
+		if err != nil {
+			t.Fatalf("NewBoxList failed: %v", err)
+		}
+		r := image.Rect(0, 0, 300, 1000000)
+		if _, _, err := NewRichWrapper(fontFace, text, Limits{MaxBoxes: bl.Len()}).TextToRect(r); err != nil {
+			t.Errorf("TextToRect with a limit of every box failed: %v", err)
+		}
+		if _, _, err := NewRichWrapper(fontFace, text, Limits{MaxBoxes: bl.Len() - 1}).TextToRect(r); !errors.Is(err, ErrLimitExceeded) {
+			t.Errorf("TextToRect with a limit short of every box expected ErrLimitExceeded got %v", err)
+		}
+	})
+}
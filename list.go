@@ -0,0 +1,382 @@
+package wordwrap
+
+import (
+	"image"
+	"strconv"
+	"strings"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+// ListStyle how the items of a list are marked
+type ListStyle int
+
+const (
+	// BulletList marks items with a bullet glyph, which changes with how deeply the list is nested
+	BulletList ListStyle = iota
+	// DecimalList numbers items 1. 2. 3.
+	DecimalList
+	// LowerAlphaList numbers items a. b. c.
+	LowerAlphaList
+	// UpperAlphaList numbers items A. B. C.
+	UpperAlphaList
+	// LowerRomanList numbers items i. ii. iii.
+	LowerRomanList
+	// UpperRomanList numbers items I. II. III.
+	UpperRomanList
+)
+
+// ListGroup is a list of items, returned by List
+type ListGroup Group
+
+// ListItemGroup is an item of a list holding any rich content, returned by Item
+type ListItemGroup Group
+
+// BulletOption sets the glyph bullet lists mark their items with
+type BulletOption string
+
+// MarkerImageOption marks the items of a list with an image instead of a bullet or number
+type MarkerImageOption struct {
+	Image image.Image
+}
+
+// ListStartOption sets the number the first item of a numbered list is given
+type ListStartOption int
+
+// ListIndentOption sets how far in pixels the items of a list are indented, by default just far enough for the widest
+// marker and a space
+type ListIndentOption int
+
+// List returns a ListGroup. A ListStyle among the args picks how items are marked, by default they are bulleted.
+func List(args ...interface{}) interface{} {
+	return ListGroup{Args: args}
+}
+
+// Item returns a ListItemGroup. Items can hold lists of their own which are nested a level deeper.
+func Item(args ...interface{}) interface{} {
+	return ListItemGroup{Args: args}
+}
+
+// Bullet returns a BulletOption
+func Bullet(glyph string) BulletOption {
+	return BulletOption(glyph)
+}
+
+// MarkerImage returns a MarkerImageOption
+func MarkerImage(i image.Image) MarkerImageOption {
+	return MarkerImageOption{Image: i}
+}
+
+// ListStart returns a ListStartOption
+func ListStart(n int) ListStartOption {
+	return ListStartOption(n)
+}
+
+// ListIndent returns a ListIndentOption
+func ListIndent(px int) ListIndentOption {
+	return ListIndentOption(px)
+}
+
+// defaultBullets are the bullets used at each level of nesting, repeating once they run out
+var defaultBullets = []string{"•", "◦", "▪"}
+
+// listContent is the items of a list
+type listContent struct {
+	style  ListStyle
+	bullet string
+	image  image.Image
+	start  int
+	indent int
+	items  []*listItem
+}
+
+// listItem an item of a list
+type listItem struct {
+	contents []*Content
+}
+
+// runeLen the number of runes in the list
+func (lc *listContent) runeLen() int {
+	n := 0
+	for _, item := range lc.items {
+		for _, c := range item.contents {
+			n += c.runeLen()
+		}
+	}
+	return n
+}
+
+// marker the text marking the item numbered n of a list nested level deep
+func (lc *listContent) marker(n int, level int) string {
+	switch lc.style {
+	case DecimalList:
+		return strconv.Itoa(n) + "."
+	case LowerAlphaList:
+		return alpha(n) + "."
+	case UpperAlphaList:
+		return strings.ToUpper(alpha(n)) + "."
+	case LowerRomanList:
+		return roman(n) + "."
+	case UpperRomanList:
+		return strings.ToUpper(roman(n)) + "."
+	}
+	if lc.bullet != "" {
+		return lc.bullet
+	}
+	return defaultBullets[level%len(defaultBullets)]
+}
+
+// alpha numbers n as a b ... z aa ab, falling back to decimal for numbers below 1
+func alpha(n int) string {
+	if n < 1 {
+		return strconv.Itoa(n)
+	}
+	var b []byte
+	for ; n > 0; n = (n - 1) / 26 {
+		b = append([]byte{byte('a' + (n-1)%26)}, b...)
+	}
+	return string(b)
+}
+
+// roman numbers n as i ii iii iv, falling back to decimal for numbers below 1
+func roman(n int) string {
+	if n < 1 {
+		return strconv.Itoa(n)
+	}
+	values := []int{1000, 900, 500, 400, 100, 90, 50, 40, 10, 9, 5, 4, 1}
+	numerals := []string{"m", "cm", "d", "cd", "c", "xc", "l", "xl", "x", "ix", "v", "iv", "i"}
+	var sb strings.Builder
+	for i, v := range values {
+		for ; n >= v; n -= v {
+			sb.WriteString(numerals[i])
+		}
+	}
+	return sb.String()
+}
+
+// ListItemBox starts an item of a list. It takes no space on the line, its marker is drawn in the indent before it so
+// wrapped lines of the item line up with the first.
+type ListItemBox struct {
+	// Marker is the bullet, number or image marking the item
+	Marker Box
+	// Number is the item's number, counting from the list's start
+	Number int
+	// Level is how deeply the list is nested, 0 for a list that isn't in another
+	Level int
+	// indent is how far the item's lines are indented from the container
+	indent int
+	// gap is the space between the marker and the item
+	gap fixed.Int26_6
+}
+
+// Interface enforcement
+var _ Box = (*ListItemBox)(nil)
+
+// AdvanceRect the item takes no space, its marker hangs in the indent
+func (lib *ListItemBox) AdvanceRect() fixed.Int26_6 {
+	return 0
+}
+
+// MetricsRect the metrics of the marker, so the line is tall enough for it
+func (lib *ListItemBox) MetricsRect() font.Metrics {
+	return lib.Marker.MetricsRect()
+}
+
+// Whitespace if this is a white space or not
+func (lib *ListItemBox) Whitespace() bool {
+	return false
+}
+
+// DrawBox does nothing, the marker is drawn outside the line by RenderLines
+func (lib *ListItemBox) DrawBox(i Image, y fixed.Int26_6, dc *DrawConfig) {}
+
+// FontDrawer font used
+func (lib *ListItemBox) FontDrawer() *font.Drawer {
+	return nil
+}
+
+// Len the item's marker isn't part of the text
+func (lib *ListItemBox) Len() int {
+	return 0
+}
+
+// TextValue the item's marker isn't part of the text
+func (lib *ListItemBox) TextValue() string {
+	return ""
+}
+
+func (lib *ListItemBox) MinSize() (fixed.Int26_6, fixed.Int26_6) {
+	return 0, 0
+}
+
+func (lib *ListItemBox) MaxSize() (fixed.Int26_6, fixed.Int26_6) {
+	return 0, 0
+}
+
+// listEndBox ends a list, returning the lines after it to the indent they had before it
+type listEndBox struct {
+	indent int
+}
+
+// Interface enforcement
+var _ Box = (*listEndBox)(nil)
+
+func (leb *listEndBox) AdvanceRect() fixed.Int26_6 {
+	return 0
+}
+
+func (leb *listEndBox) MetricsRect() font.Metrics {
+	return font.Metrics{}
+}
+
+func (leb *listEndBox) Whitespace() bool {
+	return false
+}
+
+func (leb *listEndBox) DrawBox(i Image, y fixed.Int26_6, dc *DrawConfig) {}
+
+func (leb *listEndBox) FontDrawer() *font.Drawer {
+	return nil
+}
+
+func (leb *listEndBox) Len() int {
+	return 0
+}
+
+func (leb *listEndBox) TextValue() string {
+	return ""
+}
+
+func (leb *listEndBox) MinSize() (fixed.Int26_6, fixed.Int26_6) {
+	return 0, 0
+}
+
+func (leb *listEndBox) MaxSize() (fixed.Int26_6, fixed.Int26_6) {
+	return 0, 0
+}
+
+// listBoxes boxes a list, each item being a ListItemBox followed by the item's boxes, and a listEndBox to finish it
+func (sb *SimpleBoxer) listBoxes(c *Content) ([]Box, error) {
+	lc := c.list
+	drawer := sb.contentDrawer(c)
+	markers := make([]Box, len(lc.items))
+	widest := fixed.Int26_6(0)
+	for i := range lc.items {
+		var m Box
+		if lc.image != nil {
+			m = NewImageBox(lc.image)
+		} else {
+			var err error
+			if m, err = NewSimpleTextBox(drawer, lc.marker(lc.start+i, sb.listLevel)); err != nil {
+				return nil, err
+			}
+		}
+		if a := m.AdvanceRect(); a > widest {
+			widest = a
+		}
+		markers[i] = m
+	}
+	_, gap := drawer.BoundString(" ")
+	indent := lc.indent
+	if indent == 0 {
+		indent = (widest + gap).Ceil()
+	}
+	itemIndent := sb.listIndent + indent
+	var boxes []Box
+	for i, item := range lc.items {
+		boxes = append(boxes, &ListItemBox{
+			Marker: markers[i],
+			Number: lc.start + i,
+			Level:  sb.listLevel,
+			indent: itemIndent,
+			gap:    gap,
+		})
+		child := sb.child(item.contents)
+		child.listIndent = itemIndent
+		child.listLevel = sb.listLevel + 1
+		for child.HasNext() {
+			b, _, err := child.Next()
+			if err != nil {
+				return nil, err
+			}
+			if b != nil {
+				boxes = append(boxes, b)
+			}
+		}
+	}
+	return append(boxes, &listEndBox{indent: sb.listIndent}), nil
+}
+
+// child a boxer for content nested in what is being boxed
+func (sb *SimpleBoxer) child(contents []*Content) *SimpleBoxer {
+	child := &SimpleBoxer{
+		contents:       contents,
+		fontDrawer:     sb.fontDrawer,
+		Tokenizer:      sb.Tokenizer,
+		measureCache:   sb.measureCache,
+		postBoxOptions: sb.postBoxOptions,
+	}
+	child.indexContents()
+	return child
+}
+
+// setIndent indents the line, and the lines after it, indent pixels from the container
+func (sf *SimpleFolder) setIndent(l *SimpleLine, indent int) {
+	l.indentBy(indent - sf.indent)
+	sf.indent = indent
+}
+
+// indentBy moves the line right by px, taking it from the line's width
+func (l *SimpleLine) indentBy(px int) {
+	if px == 0 {
+		return
+	}
+	l.width -= px
+	l.xoffset += px
+	l.narrowed = true
+}
+
+// lastBox the last box on the line, or nil
+func (l *SimpleLine) lastBox() Box {
+	if len(l.boxes) == 0 {
+		return nil
+	}
+	return l.boxes[len(l.boxes)-1]
+}
+
+// markedLine is a line that may start an item of a list
+type markedLine interface {
+	// listItem the list item the line starts, if any, and the line's baseline
+	listItem() (*ListItemBox, fixed.Int26_6)
+}
+
+// Ensures that the interface is filled
+var _ markedLine = (*SimpleLine)(nil)
+
+// listItem the list item the line starts, if any
+func (l *SimpleLine) listItem() (*ListItemBox, fixed.Int26_6) {
+	for _, b := range l.boxes {
+		if lib, ok := b.(*ListItemBox); ok {
+			return lib, l.yoffset
+		}
+	}
+	return nil, 0
+}
+
+// drawMarkers draws the markers of the list items the lines start in the indent before them
+func (sw *SimpleWrapper) drawMarkers(i Image, ls []Line, at image.Point, dc *DrawConfig) {
+	for li, lr := range sw.lineRects(ls, i.Bounds(), at) {
+		ml, ok := ls[li].(markedLine)
+		if !ok {
+			continue
+		}
+		lib, y := ml.listItem()
+		if lib == nil {
+			continue
+		}
+		right := lr.Min.X - lib.gap.Ceil()
+		r := image.Rect(right-lib.Marker.AdvanceRect().Ceil(), lr.Min.Y, right, lr.Max.Y)
+		lib.Marker.DrawBox(i.SubImage(r).(Image), y, dc)
+	}
+}
@@ -0,0 +1,206 @@
+package wordwrap
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"strconv"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func listItems(ls []Line) []*ListItemBox {
+	var items []*ListItemBox
+	for _, l := range ls {
+		for _, b := range l.Boxes() {
+			if lib, ok := b.(*ListItemBox); ok {
+				items = append(items, lib)
+			}
+		}
+	}
+	return items
+}
+
+func TestListMarkers(t *testing.T) {
+	tests := []struct {
+		style ListStyle
+		n     int
+		want  string
+	}{
+		{style: DecimalList, n: 12, want: "12."},
+		{style: LowerAlphaList, n: 1, want: "a."},
+		{style: LowerAlphaList, n: 27, want: "aa."},
+		{style: UpperAlphaList, n: 52, want: "AZ."},
+		{style: LowerRomanList, n: 4, want: "iv."},
+		{style: UpperRomanList, n: 1994, want: "MCMXCIV."},
+		{style: BulletList, n: 3, want: "•"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			lc := &listContent{style: tt.style}
+			if got := lc.marker(tt.n, 0); got != tt.want {
+				t.Errorf("marker(%d) = %q want %q", tt.n, got, tt.want)
+			}
+		})
+	}
+	if got := (&listContent{}).marker(1, 1); got != "◦" {
+		t.Errorf("nested bullet %q", got)
+	}
+}
+
+func TestList(t *testing.T) {
+	fontFace := FontFace16DPI180ForTest(t)
+	long := "The quick brown fox jumps over the lazy dog again and again"
+
+	t.Run("Hanging indent", func(t *testing.T) {
+		sw := NewRichWrapper(fontFace, "Before", List(DecimalList, Item(long), Item("Short")), "After")
+		bounds := image.Rect(0, 0, 600, 1000)
+		ls, _, err := sw.TextToRect(bounds)
+		if err != nil {
+			t.Fatalf("TextToRect failed: %v", err)
+		}
+		texts := lineTexts(ls)
+		if texts[0] != "Before" || texts[len(texts)-1] != "After" {
+			t.Fatalf("expected the list on lines of its own got %q", texts)
+		}
+		items := listItems(ls)
+		if len(items) != 2 || items[0].Number != 1 || items[1].Number != 2 {
+			t.Fatalf("items %v", items)
+		}
+		rs := sw.lineRects(ls, bounds, image.Point{})
+		indent := items[0].indent
+		if indent <= items[0].Marker.AdvanceRect().Ceil() {
+			t.Errorf("indent %d leaves no room for the marker", indent)
+		}
+		if rs[0].Min.X != 0 || rs[len(rs)-1].Min.X != 0 {
+			t.Errorf("expected the text around the list not to be indented got %v", rs)
+		}
+		if len(rs) < 5 {
+			t.Fatalf("expected the first item to wrap got %q", texts)
+		}
+		for li := 1; li < len(rs)-1; li++ {
+			if rs[li].Min.X != indent {
+				t.Errorf("line %d %q at %d want %d", li, texts[li], rs[li].Min.X, indent)
+			}
+			if rs[li].Dx() > 600-indent {
+				t.Errorf("line %d is %d wide, more than the indented width", li, rs[li].Dx())
+			}
+		}
+	})
+
+	t.Run("Nested", func(t *testing.T) {
+		sw := NewRichWrapper(fontFace, List(
+			Item("Fruit", List(LowerAlphaList, Item("Apple"), Item("Pear"))),
+			Item("Veg"),
+		))
+		bounds := image.Rect(0, 0, 600, 1000)
+		ls, _, err := sw.TextToRect(bounds)
+		if err != nil {
+			t.Fatalf("TextToRect failed: %v", err)
+		}
+		if s := cmp.Diff([]string{"Fruit", "Apple", "Pear", "Veg"}, lineTexts(ls)); s != "" {
+			t.Fatalf("lines differ:\n%s", s)
+		}
+		items := listItems(ls)
+		var markers []string
+		for _, lib := range items {
+			markers = append(markers, lib.Marker.TextValue()+" "+strconv.Itoa(lib.Level))
+		}
+		if s := cmp.Diff([]string{"• 0", "a. 1", "b. 1", "• 0"}, markers); s != "" {
+			t.Errorf("markers differ:\n%s", s)
+		}
+		rs := sw.lineRects(ls, bounds, image.Point{})
+		if rs[1].Min.X <= rs[0].Min.X || rs[2].Min.X != rs[1].Min.X || rs[3].Min.X != rs[0].Min.X {
+			t.Errorf("line positions %v", rs)
+		}
+	})
+
+	t.Run("Options", func(t *testing.T) {
+		marker := image.NewRGBA(image.Rect(0, 0, 10, 10))
+		sw := NewRichWrapper(fontFace, List(UpperRomanList, ListStart(3), ListIndent(120), Item("a")), List(MarkerImage(marker), Item("b")), List(Bullet("-"), Item("c")))
+		ls, _, err := sw.TextToRect(image.Rect(0, 0, 600, 1000))
+		if err != nil {
+			t.Fatalf("TextToRect failed: %v", err)
+		}
+		items := listItems(ls)
+		if len(items) != 3 {
+			t.Fatalf("expected 3 items got %d", len(items))
+		}
+		if items[0].Marker.TextValue() != "III." || items[0].Number != 3 || items[0].indent != 120 {
+			t.Errorf("first item %q %d indented %d", items[0].Marker.TextValue(), items[0].Number, items[0].indent)
+		}
+		if ib, ok := items[1].Marker.(*ImageBox); !ok || ib.I != marker {
+			t.Errorf("expected an image marker got %T", items[1].Marker)
+		}
+		if items[2].Marker.TextValue() != "-" {
+			t.Errorf("expected the bullet got %q", items[2].Marker.TextValue())
+		}
+	})
+
+	t.Run("Continues across pages", func(t *testing.T) {
+		var args []interface{}
+		args = append(args, DecimalList)
+		for i := 0; i < 20; i++ {
+			args = append(args, Item(long))
+		}
+		sw := NewRichWrapper(fontFace, List(args...), "After")
+		bounds := image.Rect(0, 0, 600, 400)
+		var numbers []int
+		var last []Line
+		continued := 0
+		for page := 0; sw.HasNext(); page++ {
+			ls, _, err := sw.TextToRect(bounds)
+			if err != nil {
+				t.Fatalf("TextToRect failed: %v", err)
+			}
+			for _, lib := range listItems(ls) {
+				numbers = append(numbers, lib.Number)
+			}
+			if page > 0 && listItems(ls[:1]) == nil && lineTexts(ls)[0] != "After" {
+				continued++
+				if x := sw.lineRects(ls, bounds, image.Point{})[0].Min.X; x == 0 {
+					t.Errorf("page %d carried on with an item without indenting it", page)
+				}
+			}
+			last = ls
+			if page > 40 {
+				t.Fatalf("too many pages")
+			}
+		}
+		for i, n := range numbers {
+			if n != i+1 {
+				t.Fatalf("numbers %v", numbers)
+			}
+		}
+		if len(numbers) != 20 || continued == 0 {
+			t.Errorf("expected 20 items with some carried on to the next page got %d and %d", len(numbers), continued)
+		}
+		if rs := sw.lineRects(last, bounds, image.Point{}); rs[len(rs)-1].Min.X != 0 {
+			t.Errorf("expected the text after the list not to be indented")
+		}
+	})
+
+	t.Run("Draws markers", func(t *testing.T) {
+		red := color.RGBA{R: 255, A: 255}
+		marker := image.NewRGBA(image.Rect(0, 0, 10, 10))
+		draw.Draw(marker, marker.Bounds(), image.NewUniform(red), image.Point{}, draw.Src)
+		sw := NewRichWrapper(fontFace, List(MarkerImage(marker), ListIndent(40), Item("a")))
+		ls, _, err := sw.TextToRect(image.Rect(0, 0, 600, 1000))
+		if err != nil {
+			t.Fatalf("TextToRect failed: %v", err)
+		}
+		i := image.NewRGBA(image.Rect(0, 0, 600, 200))
+		if err := sw.RenderLines(i, ls, image.Point{}); err != nil {
+			t.Fatalf("RenderLines failed: %v", err)
+		}
+		gap := listItems(ls)[0].gap.Ceil()
+		y := ls[0].YValue() - 5
+		if c := i.RGBAAt(40-gap-5, y); c != red {
+			t.Errorf("expected the marker in the indent got %v", c)
+		}
+		if c := i.RGBAAt(40-gap+1, y); c == red {
+			t.Errorf("expected the gap after the marker to be clear")
+		}
+	})
+}
@@ -0,0 +1,156 @@
+package wordwrap
+
+import (
+	"log"
+	"unicode/utf8"
+
+	"golang.org/x/image/math/fixed"
+)
+
+// OpticalMargin is a FolderOption which lets punctuation at the edges of a line hang into the margins, so the edges of
+// the text look straight rather than indented where a line starts with a quote or ends with a full stop. Each table
+// is how far, as a percentage of its advance, a character protrudes. Lines are folded allowing for the protrusion.
+type OpticalMargin struct {
+	// Left is the protrusion of the characters starting a line into the left margin
+	Left map[rune]float64
+	// Right is the protrusion of the characters ending a line into the right margin
+	Right map[rune]float64
+}
+
+// DefaultOpticalMargin hangs opening quotes into the left margin and closing quotes, stops, commas and hyphens into the
+// right
+var DefaultOpticalMargin = OpticalMargin{
+	Left: map[rune]float64{
+		'"': 50, '\'': 50, '“': 70, '‘': 70, '„': 70, '«': 50, '‹': 50, '(': 20, '[': 20,
+	},
+	Right: map[rune]float64{
+		'.': 70, ',': 70, ':': 50, ';': 50, '-': 70, '‐': 70, '–': 50, '—': 20, '!': 20, '?': 20,
+		'"': 50, '\'': 50, '”': 70, '’': 70, '»': 50, '›': 50, ')': 20, ']': 20,
+	},
+}
+
+var (
+	// Ensures interface compliance
+	_ FolderOption = OpticalMargin{}
+	// Ensures interface compliance
+	_ WrapperOption = OpticalMargin{}
+)
+
+// ApplyWrapperConfig passes the configuration through to the folder
+func (om OpticalMargin) ApplyWrapperConfig(wr interface{}) {
+	if wr, ok := wr.(addFoldConfig); ok {
+		wr.addFoldConfig(om)
+	} else {
+		log.Printf("can't apply")
+	}
+}
+
+// ApplyFoldConfig folds lines allowing for the protrusion and stores it in the lines
+func (om OpticalMargin) ApplyFoldConfig(f interface{}) {
+	if f, ok := f.(*SimpleFolder); ok {
+		f.opticalMargin = &om
+		f.lineOptions = append(f.lineOptions, func(line Line) {
+			switch hl := line.(type) {
+			case hangingLine:
+				hl.setHang(om.hangs(line.Boxes()))
+			default:
+				log.Printf("can't apply")
+			}
+		})
+	}
+}
+
+// hangs how far the boxes protrude into the left and right margins when they make up a line
+func (om *OpticalMargin) hangs(boxes []Box) (left fixed.Int26_6, right fixed.Int26_6) {
+	for _, b := range boxes {
+		if b.AdvanceRect() == 0 {
+			continue
+		}
+		if !b.Whitespace() {
+			r, _ := utf8.DecodeRuneInString(b.TextValue())
+			left = protrusion(b, r, om.Left)
+		}
+		break
+	}
+	for i := len(boxes) - 1; i >= 0; i-- {
+		b := boxes[i]
+		if b.AdvanceRect() == 0 || b.Whitespace() {
+			continue
+		}
+		r, _ := utf8.DecodeLastRuneInString(b.TextValue() + hyphenOf(b))
+		right = protrusion(b, r, om.Right)
+		break
+	}
+	return left, right
+}
+
+// hyphenOf the hyphen drawn after the text of b where it is part of a broken word
+func hyphenOf(b Box) string {
+	if tb, _ := textBoxWithin(b); tb != nil {
+		return tb.hyphen
+	}
+	return ""
+}
+
+// protrusion how far r protrudes in the font of b by the table
+func protrusion(b Box, r rune, table map[rune]float64) fixed.Int26_6 {
+	pct, ok := table[r]
+	d := b.FontDrawer()
+	if !ok || d == nil || d.Face == nil {
+		return 0
+	}
+	a, ok := d.Face.GlyphAdvance(r)
+	if !ok {
+		return 0
+	}
+	return fixed.Int26_6(float64(a) * pct / 100)
+}
+
+// hangsInMargin true if the line would fit its width with b added once its edges hang into the margins
+func (sf *SimpleFolder) hangsInMargin(l *SimpleLine, b Box, width fixed.Int26_6) bool {
+	if sf.opticalMargin == nil || b.Whitespace() {
+		return false
+	}
+	left, right := sf.opticalMargin.hangs(append(l.boxes[:len(l.boxes):len(l.boxes)], b))
+	return (width - left - right).Ceil() <= l.width
+}
+
+// hangingLine is a line whose edges may hang into the margins
+type hangingLine interface {
+	// hang how far the line protrudes into the left and right margins
+	hang() (fixed.Int26_6, fixed.Int26_6)
+	// setHang sets how far the line protrudes into the left and right margins
+	setHang(left, right fixed.Int26_6)
+}
+
+// Ensures that the interface is filled
+var _ hangingLine = (*SimpleLine)(nil)
+
+// hang how far the line protrudes into the left and right margins
+func (l *SimpleLine) hang() (fixed.Int26_6, fixed.Int26_6) {
+	return l.hangLeft, l.hangRight
+}
+
+// setHang sets how far the line protrudes into the margins
+func (l *SimpleLine) setHang(left, right fixed.Int26_6) {
+	l.hangLeft, l.hangRight = left, right
+}
+
+// opticalWidth the width of the line between the edges of its text, given the width of its Size, which is less what
+// hangs into the left margin. What hangs into the right margin is already left out of the Size.
+func opticalWidth(l Line, s int) int {
+	if hl, ok := l.(hangingLine); ok {
+		left, _ := hl.hang()
+		return s - left.Ceil()
+	}
+	return s
+}
+
+// rightHang how far the line hangs into the right margin, beyond its Size, when it is drawn
+func rightHang(l Line) int {
+	if hl, ok := l.(hangingLine); ok {
+		_, right := hl.hang()
+		return right.Ceil()
+	}
+	return 0
+}
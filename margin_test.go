@@ -0,0 +1,112 @@
+package wordwrap
+
+import (
+	"image"
+	"testing"
+
+	"golang.org/x/image/font"
+)
+
+// inkLeft the leftmost column of i with anything drawn in it
+func inkLeft(i *image.RGBA) int {
+	b := i.Bounds()
+	for x := b.Min.X; x < b.Max.X; x++ {
+		for y := b.Min.Y; y < b.Max.Y; y++ {
+			if i.RGBAAt(x, y).A != 0 {
+				return x
+			}
+		}
+	}
+	return -1
+}
+
+func TestOpticalMargin(t *testing.T) {
+	fontFace := FontFace16DPI180ForTest(t)
+	drawer := &font.Drawer{Face: fontFace}
+
+	t.Run("Hangs opening quotes into the left margin", func(t *testing.T) {
+		bounds := image.Rect(0, 0, 600, 300)
+		at := image.Pt(100, 0)
+		var left [2]int
+		var hang int
+		for n, options := range [][]interface{}{nil, {DefaultOpticalMargin}} {
+			sw := NewRichWrapper(append([]interface{}{fontFace, "“Quoted”"}, options...)...)
+			ls, _, err := sw.TextToRect(bounds)
+			if err != nil {
+				t.Fatalf("TextToRect failed: %v", err)
+			}
+			i := image.NewRGBA(bounds)
+			if err := sw.RenderLines(i, ls, at); err != nil {
+				t.Fatalf("RenderLines failed: %v", err)
+			}
+			left[n] = inkLeft(i)
+			if n == 1 {
+				a, _ := fontFace.GlyphAdvance('“')
+				hang = (a * 70 / 100).Round()
+				if got := sw.lineRects(ls, bounds, at)[0].Min.X; got != at.X-(a*70/100).Ceil() {
+					t.Errorf("expected the line to start in the margin got %d", got)
+				}
+			}
+		}
+		if d := left[0] - left[1]; d < hang-1 || d > hang+1 {
+			t.Errorf("expected the quote %d further left got %d", hang, d)
+		}
+	})
+
+	t.Run("Hangs stops into the right margin", func(t *testing.T) {
+		width := drawer.MeasureString("Stop.").Ceil()
+		bounds := image.Rect(0, 0, 600, 300)
+		sw := NewRichWrapper(fontFace, "Stop.", DefaultOpticalMargin, RightLines)
+		ls, _, err := sw.TextToRect(bounds)
+		if err != nil {
+			t.Fatalf("TextToRect failed: %v", err)
+		}
+		a, _ := fontFace.GlyphAdvance('.')
+		r := sw.lineRects(ls, bounds, image.Point{})[0]
+		if d := r.Max.X - bounds.Max.X; d < (a*70/100).Round()-1 || d > (a*70/100).Round()+1 {
+			t.Errorf("expected the stop to hang %d into the margin got %d", (a * 70 / 100).Round(), d)
+		}
+		if r.Dx() < width-1 || r.Dx() > width+1 {
+			t.Errorf("expected the line %d wide got %d", width, r.Dx())
+		}
+	})
+
+	t.Run("Folds allowing for the protrusion", func(t *testing.T) {
+		text := "Some words that end."
+		width := drawer.MeasureString(text).Ceil() - 2
+		bounds := image.Rect(0, 0, width, 300)
+		plain, _, err := NewRichWrapper(fontFace, text).TextToRect(bounds)
+		if err != nil {
+			t.Fatalf("TextToRect failed: %v", err)
+		}
+		if len(plain) != 2 {
+			t.Fatalf("expected the stop to wrap without the option got %q", lineTexts(plain))
+		}
+		ls, _, err := NewRichWrapper(fontFace, text, DefaultOpticalMargin).TextToRect(bounds)
+		if err != nil {
+			t.Fatalf("TextToRect failed: %v", err)
+		}
+		if len(ls) != 1 {
+			t.Fatalf("expected the stop to hang rather than wrap got %q", lineTexts(ls))
+		}
+		if ls[0].Size().Max.X > width {
+			t.Errorf("expected the line to fit %d without what hangs got %v", width, ls[0].Size())
+		}
+	})
+
+	t.Run("Custom tables", func(t *testing.T) {
+		om := OpticalMargin{Left: map[rune]float64{'Q': 100}}
+		ls, _, err := NewRichWrapper(fontFace, "Quoted.", om).TextToRect(image.Rect(0, 0, 600, 300))
+		if err != nil {
+			t.Fatalf("TextToRect failed: %v", err)
+		}
+		a, _ := fontFace.GlyphAdvance('Q')
+		left, right := ls[0].(hangingLine).hang()
+		if left != a || right != 0 {
+			t.Errorf("expected hangs %v and 0 got %v and %v", a, left, right)
+		}
+		if ls[0].Size().Min.X != -a.Ceil() {
+			t.Errorf("expected the size to start at %d got %d", -a.Ceil(), ls[0].Size().Min.X)
+		}
+	})
+}
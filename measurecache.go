@@ -0,0 +1,139 @@
+package wordwrap
+
+import (
+	"container/list"
+	"log"
+	"reflect"
+	"sync"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+// MeasureCache is a BoxerOption that caches the measurements of text boxes by face and string so repeated words are
+// only measured once. The cache is safe for concurrent use and can be shared between boxers and wrappers. When full the
+// least recently used measurement is dropped. Faces are map keys, so a face whose value can't be compared, such as a
+// struct holding a slice, is measured every time rather than cached.
+type MeasureCache struct {
+	mu      sync.Mutex
+	size    int
+	entries map[measureKey]*list.Element
+	order   *list.List
+	hits    int
+	misses  int
+}
+
+// measureKey identifies a measurement
+type measureKey struct {
+	face font.Face
+	s    string
+}
+
+// measureEntry a cached measurement
+type measureEntry struct {
+	key     measureKey
+	bounds  fixed.Rectangle26_6
+	advance fixed.Int26_6
+}
+
+// DefaultMeasureCacheSize is the number of strings NewMeasureCache holds if given a size of 0 or less
+const DefaultMeasureCacheSize = 16384
+
+// NewMeasureCache creates a measurement cache holding at most size strings
+func NewMeasureCache(size int) *MeasureCache {
+	if size <= 0 {
+		size = DefaultMeasureCacheSize
+	}
+	return &MeasureCache{
+		size:    size,
+		entries: map[measureKey]*list.Element{},
+		order:   list.New(),
+	}
+}
+
+// Reports interface adherence
+var _ BoxerOption = (*MeasureCache)(nil)
+
+// ApplyWrapperConfig passes the cache on to the wrapper's boxer
+func (mc *MeasureCache) ApplyWrapperConfig(wr interface{}) {
+	if wr, ok := wr.(addBoxConfig); ok {
+		wr.addBoxConfig(mc)
+	} else {
+		log.Printf("can't apply")
+	}
+}
+
+// ApplyBoxConfig installs the cache on a SimpleBoxer or ReaderBoxer
+func (mc *MeasureCache) ApplyBoxConfig(br interface{}) {
+	switch br := br.(type) {
+	case *SimpleBoxer:
+		br.measureCache = mc
+	case *ReaderBoxer:
+		br.measureCache = mc
+	}
+}
+
+// Len the number of strings currently cached
+func (mc *MeasureCache) Len() int {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	return mc.order.Len()
+}
+
+// Stats the number of lookups that were found in the cache and the number that had to be measured
+func (mc *MeasureCache) Stats() (hits int, misses int) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	return mc.hits, mc.misses
+}
+
+// BoundString returns the same as font.BoundString for the face and string, measuring it only if it isn't cached
+func (mc *MeasureCache) BoundString(face font.Face, s string) (fixed.Rectangle26_6, fixed.Int26_6) {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	if !comparableFace(face) {
+		mc.misses++
+		return font.BoundString(face, s)
+	}
+	k := measureKey{face: face, s: s}
+	if el, ok := mc.entries[k]; ok {
+		mc.hits++
+		mc.order.MoveToFront(el)
+		e := el.Value.(*measureEntry)
+		return e.bounds, e.advance
+	}
+	mc.misses++
+	// Measured under the lock as faces aren't safe for concurrent use
+	bounds, advance := font.BoundString(face, s)
+	mc.entries[k] = mc.order.PushFront(&measureEntry{
+		key:     k,
+		bounds:  bounds,
+		advance: advance,
+	})
+	for mc.order.Len() > mc.size {
+		oldest := mc.order.Back()
+		mc.order.Remove(oldest)
+		delete(mc.entries, oldest.Value.(*measureEntry).key)
+	}
+	return bounds, advance
+}
+
+// comparableFace true if face can be used as a map key without panicking
+func comparableFace(face font.Face) bool {
+	return face == nil || reflect.ValueOf(face).Comparable()
+}
+
+// newSimpleTextBox creates a text box, measuring it with the cache if there is one
+func newSimpleTextBox(drawer *font.Drawer, t string, mc *MeasureCache) (Box, error) {
+	if mc == nil || drawer == nil {
+		return NewSimpleTextBox(drawer, t)
+	}
+	ttb, a := mc.BoundString(drawer.Face, t)
+	return &SimpleTextBox{
+		drawer:   drawer,
+		Contents: t,
+		Bounds:   ttb,
+		Advance:  a,
+		Metrics:  drawer.Face.Metrics(),
+	}, nil
+}
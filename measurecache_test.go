@@ -0,0 +1,102 @@
+package wordwrap
+
+import (
+	"image"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/image/font"
+)
+
+func TestMeasureCache(t *testing.T) {
+	fontFace := FontFace16DPI180ForTest(t)
+	text := strings.Repeat("The quick brown fox jumps over the lazy dog. ", 10)
+
+	t.Run("Same layout as unmeasured", func(t *testing.T) {
+		mc := NewMeasureCache(0)
+		r := image.Rect(0, 0, 300, 10000)
+		want, _, err := NewRichWrapper(fontFace, text).TextToRect(r)
+		if err != nil {
+			t.Fatalf("TextToRect failed: %v", err)
+		}
+		got, _, err := NewRichWrapper(fontFace, text, mc).TextToRect(r)
+		if err != nil {
+			t.Fatalf("TextToRect failed: %v", err)
+		}
+		if s := cmp.Diff(lineTexts(want), lineTexts(got)); s != "" {
+			t.Errorf("lines differ:\n%s", s)
+		}
+		for i := range want {
+			if want[i].Size() != got[i].Size() {
+				t.Errorf("line %d size %v, want %v", i, got[i].Size(), want[i].Size())
+			}
+		}
+		hits, misses := mc.Stats()
+		if hits == 0 {
+			t.Errorf("expected repeated words to be found in the cache")
+		}
+		if misses != mc.Len() {
+			t.Errorf("misses %d != Len() %d", misses, mc.Len())
+		}
+	})
+
+	t.Run("Bounded", func(t *testing.T) {
+		mc := NewMeasureCache(3)
+		for _, s := range []string{"a", "b", "c", "d", "a"} {
+			b, a := mc.BoundString(fontFace, s)
+			wb, wa := font.BoundString(fontFace, s)
+			if b != wb || a != wa {
+				t.Errorf("BoundString(%q) = %v %v, want %v %v", s, b, a, wb, wa)
+			}
+		}
+		if mc.Len() != 3 {
+			t.Errorf("Len() = %d, want 3", mc.Len())
+		}
+		if hits, _ := mc.Stats(); hits != 0 {
+			t.Errorf("expected a to be evicted before being measured again, got %d hits", hits)
+		}
+	})
+
+	t.Run("Face that can't be a key", func(t *testing.T) {
+		mc := NewMeasureCache(0)
+		face := sliceFace{Face: fontFace, tags: []string{"not comparable"}}
+		for _, s := range []string{"a", "a"} {
+			b, a := mc.BoundString(face, s)
+			wb, wa := font.BoundString(fontFace, s)
+			if b != wb || a != wa {
+				t.Errorf("BoundString(%q) = %v %v, want %v %v", s, b, a, wb, wa)
+			}
+		}
+		if mc.Len() != 0 {
+			t.Errorf("Len() = %d, want it measured without caching", mc.Len())
+		}
+	})
+
+	t.Run("Concurrent use", func(t *testing.T) {
+		mc := NewMeasureCache(10)
+		var wg sync.WaitGroup
+		for i := 0; i < 4; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 200; j++ {
+					if _, a := mc.BoundString(fontFace, string(rune('a'+j%26))); a == 0 {
+						t.Errorf("missing advance")
+					}
+				}
+			}()
+		}
+		wg.Wait()
+		if mc.Len() != 10 {
+			t.Errorf("Len() = %d, want 10", mc.Len())
+		}
+	})
+}
+
+// sliceFace is a face whose value can't be compared
+type sliceFace struct {
+	font.Face
+	tags []string
+}
@@ -163,6 +163,8 @@ var BoxBox = boxerOptionFunc(func(f interface{}) {
 	switch f := f.(type) {
 	case *SimpleBoxer:
 		f.postBoxOptions = append(f.postBoxOptions, bf)
+	case *ReaderBoxer:
+		f.postBoxOptions = append(f.postBoxOptions, bf)
 
 	case Box:
 		bf(f)
@@ -317,6 +319,9 @@ const (
 	VerticalCenterBlock
 	// BottomBlock positions the entire block of text bottom
 	BottomBlock
+	// JustifyBlock spreads the lines of the block of text between the lines to fill the height, as configured by
+	// VerticalJustify
+	JustifyBlock
 )
 
 // Interface enforcement
@@ -0,0 +1,208 @@
+package wordwrap
+
+import (
+	"unicode"
+
+	"golang.org/x/image/math/fixed"
+)
+
+// OverflowWrapAnywhere is a FolderOption that breaks a word too wide for a line of its own at the last grapheme that
+// fits, as with the CSS overflow-wrap: anywhere, rather than letting it overflow the line. The rest of the word carries
+// on to the next line. The first part is drawn ending with hyphen unless it is empty, the hyphen taking space on the
+// line but not being part of the text of the box.
+func OverflowWrapAnywhere(hyphen string) WrapperOption {
+	return folderOptionFunc(func(f interface{}) {
+		if f, ok := f.(*SimpleFolder); ok {
+			f.overflowWrap = &hyphen
+		}
+	})
+}
+
+// breakWord breaks b if it is too wide for the empty line it is going on, pushing the rest back to the boxer and
+// returning the part that fits
+func (sf *SimpleFolder) breakWord(b Box, r *SimpleLine) Box {
+	if sf.overflowWrap == nil || b.Whitespace() {
+		return b
+	}
+	used := r.size.Max.X - r.size.Min.X
+	space := fixed.I(r.width) - used
+	if used != 0 || b.AdvanceRect() <= space {
+		return b
+	}
+	head, tail, ok := splitBox(b, space, *sf.overflowWrap)
+	if !ok {
+		return b
+	}
+	sf.boxer.Unshift(tail)
+	sf.broken = &brokenWord{whole: b, head: head, tail: tail}
+	if len(sf.taken) > 0 {
+		// Rolled back as the part on the line, the rest is already back in the boxer
+		sf.taken[len(sf.taken)-1] = head
+	}
+	return head
+}
+
+// brokenWord is a word broken by breakWord, with the parts it was broken into
+type brokenWord struct {
+	whole Box
+	head  Box
+	tail  Box
+}
+
+// unbreak puts the word broken by breakWord back together where its first part is among the boxes of a line being
+// rolled back, taking the rest back off the boxer, so the word is folded again as it was rather than with the hyphen in
+// it
+func (sf *SimpleFolder) unbreak(boxes []Box) []Box {
+	bw := sf.broken
+	if bw == nil {
+		return boxes
+	}
+	for i, b := range boxes {
+		if b != bw.head {
+			continue
+		}
+		if i+1 < len(boxes) && boxes[i+1] == bw.tail {
+			boxes = append(boxes[:i:i], append([]Box{bw.whole}, boxes[i+2:]...)...)
+		} else if tail := sf.boxer.Shift(); tail == bw.tail {
+			boxes = append(boxes[:i:i], append([]Box{bw.whole}, boxes[i+1:]...)...)
+		} else {
+			if tail != nil {
+				sf.boxer.Unshift(tail)
+			}
+			return boxes
+		}
+		sf.broken = nil
+		return boxes
+	}
+	return boxes
+}
+
+// splitBox splits the text of b at the last grapheme that fits width, the first part ending with hyphen, looking
+// through the boxes wrapping it so both parts are styled and decorated alike. There is always at least one grapheme in
+// each part. Returns false if b can't be split.
+func splitBox(b Box, width fixed.Int26_6, hyphen string) (Box, Box, bool) {
+	switch b := b.(type) {
+	case *SimpleTextBox:
+		return b.split(width, hyphen)
+	case *whiteSpaceBox:
+		if !b.mode.wraps() {
+			return nil, nil, false
+		}
+		head, tail, ok := splitBox(b.Box, width, hyphen)
+		if !ok {
+			return nil, nil, false
+		}
+		hb, tb := *b, *b
+		hb.Box, tb.Box = head, tail
+		hb.runes = head.Len()
+		tb.runes = b.runes - hb.runes
+		return &hb, &tb, true
+	case *IDBox:
+		head, tail, ok := splitBox(b.Box, width, hyphen)
+		if !ok {
+			return nil, nil, false
+		}
+		hb, tb := *b, *b
+		hb.Box, tb.Box = head, tail
+		return &hb, &tb, true
+	case *BackgroundBox:
+		head, tail, ok := splitBox(b.Box, width, hyphen)
+		if !ok {
+			return nil, nil, false
+		}
+		hb, tb := *b, *b
+		hb.Box, tb.Box = head, tail
+		return &hb, &tb, true
+	case *EffectBox:
+		head, tail, ok := splitBox(b.Box, width, hyphen)
+		if !ok {
+			return nil, nil, false
+		}
+		hb, tb := *b, *b
+		hb.Box, tb.Box = head, tail
+		return &hb, &tb, true
+	case *AlignedBox:
+		head, tail, ok := splitBox(b.Box, width, hyphen)
+		if !ok {
+			return nil, nil, false
+		}
+		hb, tb := *b, *b
+		hb.Box, tb.Box = head, tail
+		return &hb, &tb, true
+	case *DecorationBox:
+		head, tail, ok := splitBox(b.Box, width, hyphen)
+		if !ok {
+			return nil, nil, false
+		}
+		hb, tb := *b, *b
+		hb.Box, tb.Box = head, tail
+		return &hb, &tb, true
+	}
+	return nil, nil, false
+}
+
+// split the box at the last grapheme that fits width, measuring each prefix, with hyphen, with the box's drawer. The
+// first part is measured with the hyphen but keeps it apart from its contents, as the rest does with any hyphen the box
+// ended with.
+func (sb *SimpleTextBox) split(width fixed.Int26_6, hyphen string) (Box, Box, bool) {
+	rs := []rune(sb.Contents)
+	ends := graphemeEnds(rs)
+	if len(ends) < 2 || sb.drawer == nil {
+		return nil, nil, false
+	}
+	at := ends[0]
+	for _, e := range ends[1 : len(ends)-1] {
+		if sb.drawer.MeasureString(string(rs[:e])+hyphen) > width {
+			break
+		}
+		at = e
+	}
+	head, err := NewSimpleTextBox(sb.drawer, string(rs[:at])+hyphen)
+	if err != nil {
+		return nil, nil, false
+	}
+	tail, err := NewSimpleTextBox(sb.drawer, string(rs[at:])+sb.hyphen)
+	if err != nil {
+		return nil, nil, false
+	}
+	hb, tb := head.(*SimpleTextBox), tail.(*SimpleTextBox)
+	hb.Contents, hb.hyphen = string(rs[:at]), hyphen
+	tb.Contents, tb.hyphen = string(rs[at:]), sb.hyphen
+	hb.boxBox, tb.boxBox = sb.boxBox, sb.boxBox
+	return hb, tb, true
+}
+
+// graphemeEnds the rune index after each grapheme in rs, keeping combining marks, joined sequences, emoji modifiers and
+// flag pairs with the rune they follow
+func graphemeEnds(rs []rune) []int {
+	var ends []int
+	for i := 0; i < len(rs); {
+		i++
+		flag := isRegionalIndicator(rs[i-1])
+		for i < len(rs) {
+			switch r := rs[i]; {
+			case unicode.Is(unicode.M, r), r == '\u200d', r >= 0x1f3fb && r <= 0x1f3ff:
+				i++
+				continue
+			case rs[i-1] == '\u200d':
+				i++
+				continue
+			case flag && isRegionalIndicator(r):
+				i++
+				flag = false
+				continue
+			case rs[i-1] == '\r' && r == '\n':
+				i++
+				continue
+			}
+			break
+		}
+		ends = append(ends, i)
+	}
+	return ends
+}
+
+// isRegionalIndicator true for the letters which pair up into flags
+func isRegionalIndicator(r rune) bool {
+	return r >= 0x1f1e6 && r <= 0x1f1ff
+}
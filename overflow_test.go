@@ -0,0 +1,158 @@
+package wordwrap
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestOverflowWrapAnywhere(t *testing.T) {
+	fontFace := FontFace16DPI180ForTest(t)
+	word := strings.Repeat("abcdefghij", 6)
+	bounds := image.Rect(0, 0, 400, 1000)
+
+	t.Run("Overflows without it", func(t *testing.T) {
+		ls, _, err := NewRichWrapper(fontFace, "a "+word).TextToRect(bounds)
+		if err != nil {
+			t.Fatalf("TextToRect failed: %v", err)
+		}
+		if len(ls) != 2 || ls[1].Size().Dx() <= bounds.Dx() {
+			t.Errorf("expected the word to overflow a line of its own got %q", lineTexts(ls))
+		}
+	})
+
+	for _, hyphen := range []string{"", "-"} {
+		t.Run("Breaks the word with "+hyphen, func(t *testing.T) {
+			sw := NewRichWrapper(fontFace, "a "+word+" b", OverflowWrapAnywhere(hyphen))
+			ls, _, err := sw.TextToRect(bounds)
+			if err != nil {
+				t.Fatalf("TextToRect failed: %v", err)
+			}
+			if len(ls) < 4 {
+				t.Fatalf("expected the word to be broken got %q", lineTexts(ls))
+			}
+			if ls[0].TextValue() != "a " {
+				t.Errorf("expected the word to start a line of its own got %q", ls[0].TextValue())
+			}
+			var joined strings.Builder
+			n := 0
+			for li, l := range ls {
+				if l.Size().Dx() > bounds.Dx() {
+					t.Errorf("line %d %q overflows at %d", li, l.TextValue(), l.Size().Dx())
+				}
+				text := l.TextValue()
+				if li > 0 && li < len(ls)-1 && !strings.HasSuffix(text, " ") {
+					if got := hyphenOf(l.Boxes()[len(l.Boxes())-1]); got != hyphen {
+						t.Errorf("line %d %q is drawn ending with %q not %q", li, text, got, hyphen)
+					}
+				}
+				joined.WriteString(text)
+				for _, b := range l.Boxes() {
+					n += b.Len()
+				}
+			}
+			if joined.String() != "a "+word+" b" {
+				t.Errorf("expected the text to be kept got %q", joined.String())
+			}
+			if n != len("a "+word+" b") {
+				t.Errorf("expected the boxes to cover the text got %d", n)
+			}
+			if sw.HasNext() {
+				t.Errorf("expected all the text to be used")
+			}
+		})
+	}
+
+	t.Run("Keeps the style of both parts", func(t *testing.T) {
+		red := color.RGBA{R: 255, A: 255}
+		sw := NewRichWrapper(fontFace, ID("w", BgColor(red, word)), OverflowWrapAnywhere("-"))
+		ls, _, err := sw.TextToRect(bounds)
+		if err != nil {
+			t.Fatalf("TextToRect failed: %v", err)
+		}
+		for li, l := range ls {
+			ib, ok := l.Boxes()[0].(*IDBox)
+			if !ok || ib.ID() != "w" {
+				t.Fatalf("line %d expected the id got %#v", li, l.Boxes()[0])
+			}
+			if db, ok := ib.Box.(*DecorationBox); !ok || db.Background == nil {
+				t.Errorf("line %d expected the background got %#v", li, ib.Box)
+			}
+		}
+	})
+
+	t.Run("Put back whole when its line is rolled back", func(t *testing.T) {
+		// The first band is folded at the height of the small font, and is rolled back to be folded again into the
+		// narrower spans of a band as tall as the large font
+		small := fontFace.Metrics().Height.Ceil()
+		mask := image.NewAlpha(image.Rect(0, 0, 600, 1000))
+		draw.Draw(mask, image.Rect(0, 0, 600, small), image.Opaque, image.Point{}, draw.Src)
+		draw.Draw(mask, image.Rect(0, small, 300, 1000), image.Opaque, image.Point{}, draw.Src)
+		sw := NewRichWrapper(fontFace, FontFace24DPI180ForTest(t), word, OverflowWrapAnywhere("-"))
+		ls, _, err := sw.TextToShape(NewAlphaMask(mask, 0))
+		if err != nil {
+			t.Fatalf("TextToShape failed: %v", err)
+		}
+		want, _, err := NewRichWrapper(fontFace, FontFace24DPI180ForTest(t), word, OverflowWrapAnywhere("-")).TextToRect(image.Rect(0, 0, 300, 1000))
+		if err != nil {
+			t.Fatalf("TextToRect failed: %v", err)
+		}
+		if s := cmp.Diff(lineTexts(want), lineTexts(ls)); s != "" {
+			t.Errorf("expected the word to be broken as it is for the narrow spans alone:\n%s", s)
+		}
+		for li, l := range ls {
+			if l.Size().Dx() > 300 {
+				t.Errorf("line %d %q overflows at %d", li, l.TextValue(), l.Size().Dx())
+			}
+			boxes := l.Boxes()
+			for bi, b := range boxes {
+				want := "-"
+				if bi < len(boxes)-1 || li == len(ls)-1 {
+					want = ""
+				}
+				if got := hyphenOf(b); got != want {
+					t.Errorf("line %d box %d %q is drawn ending with %q not %q", li, bi, b.TextValue(), got, want)
+				}
+			}
+		}
+		if sw.HasNext() {
+			t.Errorf("expected all the text to be used")
+		}
+	})
+
+	t.Run("Rune offsets stop before the hyphen", func(t *testing.T) {
+		ls, _, err := NewRichWrapper(fontFace, word, OverflowWrapAnywhere("-")).TextToRect(bounds)
+		if err != nil {
+			t.Fatalf("TextToRect failed: %v", err)
+		}
+		tb, _ := textBoxWithin(ls[0].Boxes()[0])
+		if tb == nil || tb.hyphen != "-" {
+			t.Fatalf("expected a broken word got %#v", ls[0].Boxes()[0])
+		}
+		if got, want := tb.RuneOffsetAt(tb.AdvanceRect()), tb.Len(); got != want {
+			t.Errorf("RuneOffsetAt the end = %d, want %d", got, want)
+		}
+	})
+
+	t.Run("Keeps white space modes that don't wrap", func(t *testing.T) {
+		ls, _, err := NewRichWrapper(fontFace, WhiteSpaceMode(WhiteSpacePre, word), OverflowWrapAnywhere("")).TextToRect(bounds)
+		if err != nil {
+			t.Fatalf("TextToRect failed: %v", err)
+		}
+		if s := cmp.Diff([]string{word}, lineTexts(ls)); s != "" {
+			t.Errorf("lines differ:\n%s", s)
+		}
+	})
+}
+
+func TestGraphemeEnds(t *testing.T) {
+	rs := []rune("aé\U0001F44D\U0001F3FD\U0001F1E6\U0001F1FA\U0001F1E6\U0001F468‍\U0001F469")
+	want := []int{1, 3, 5, 7, 8, 11}
+	if s := cmp.Diff(want, graphemeEnds(rs)); s != "" {
+		t.Errorf("ends differ:\n%s", s)
+	}
+}
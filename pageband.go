@@ -0,0 +1,215 @@
+package wordwrap
+
+import (
+	"context"
+	"fmt"
+	"image"
+
+	"golang.org/x/image/font"
+)
+
+// PageVariant picks the pages of a paginated layout a header or footer is on
+type PageVariant int
+
+const (
+	// AllPages the header or footer is on every page no other variant is for
+	AllPages PageVariant = iota
+	// FirstPage the header or footer is on the first page only
+	FirstPage
+	// OddPages the header or footer is on the odd pages, counting the first as 1
+	OddPages
+	// EvenPages the header or footer is on the even pages
+	EvenPages
+)
+
+// BandSlot is a slot of a header or footer, each taking a third of the width
+type BandSlot int
+
+const (
+	// BandLeft the left slot, its lines are aligned left
+	BandLeft BandSlot = iota
+	// BandCenter the centre slot, its lines are centred
+	BandCenter
+	// BandRight the right slot, its lines are aligned right
+	BandRight
+)
+
+// BandSlotGroup is the content of a slot of a header or footer, returned by LeftSlot, CenterSlot and RightSlot
+type BandSlotGroup struct {
+	Slot BandSlot
+	Args []interface{}
+}
+
+// LeftSlot returns the content of the left slot of a header or footer
+func LeftSlot(args ...interface{}) BandSlotGroup {
+	return BandSlotGroup{Slot: BandLeft, Args: args}
+}
+
+// CenterSlot returns the content of the centre slot of a header or footer
+func CenterSlot(args ...interface{}) BandSlotGroup {
+	return BandSlotGroup{Slot: BandCenter, Args: args}
+}
+
+// RightSlot returns the content of the right slot of a header or footer
+func RightSlot(args ...interface{}) BandSlotGroup {
+	return BandSlotGroup{Slot: BandRight, Args: args}
+}
+
+// PageBand is a header or footer, rich content in left, centre and right slots. Content is laid out with the wrapper's
+// font unless it gives its own.
+type PageBand struct {
+	Pages PageVariant
+	Slots [3][]interface{}
+}
+
+// newPageBand sorts the args into a band, args not in a slot go in the left slot
+func newPageBand(args []interface{}) PageBand {
+	var pb PageBand
+	for _, arg := range args {
+		switch v := arg.(type) {
+		case PageVariant:
+			pb.Pages = v
+		case BandSlotGroup:
+			pb.Slots[v.Slot] = append(pb.Slots[v.Slot], v.Args...)
+		default:
+			pb.Slots[BandLeft] = append(pb.Slots[BandLeft], v)
+		}
+	}
+	return pb
+}
+
+// HeaderOption adds a header above the content of each page
+type HeaderOption PageBand
+
+// ApplySpec adds the header to the variants the page bands are picked from
+func (o HeaderOption) ApplySpec(c *SpecConfig) { c.Headers = append(c.Headers, PageBand(o)) }
+
+// FooterOption adds a footer below the content of each page
+type FooterOption PageBand
+
+// ApplySpec adds the footer to the variants the page bands are picked from
+func (o FooterOption) ApplySpec(c *SpecConfig) { c.Footers = append(c.Footers, PageBand(o)) }
+
+// Header returns a HeaderOption. Args are a PageVariant, by default AllPages, and the content of the slots, given with
+// LeftSlot, CenterSlot and RightSlot. Content not in a slot goes in the left slot.
+func Header(args ...interface{}) HeaderOption {
+	return HeaderOption(newPageBand(args))
+}
+
+// Footer returns a FooterOption, with args as Header
+func Footer(args ...interface{}) FooterOption {
+	return FooterOption(newPageBand(args))
+}
+
+// bandFor the band of the variant for the page, pages counting from 1, or nil if there isn't one
+func bandFor(bands []PageBand, page int) *PageBand {
+	want := OddPages
+	if page%2 == 0 {
+		want = EvenPages
+	}
+	var match, all *PageBand
+	for i := range bands {
+		b := &bands[i]
+		switch {
+		case b.Pages == FirstPage && page == 1:
+			return b
+		case b.Pages == want && match == nil:
+			match = b
+		case b.Pages == AllPages && all == nil:
+			all = b
+		}
+	}
+	if match != nil {
+		return match
+	}
+	return all
+}
+
+// BandLayout is a header or footer laid out on a page
+type BandLayout struct {
+	// Rect is where the band is on the page
+	Rect image.Rectangle
+	// Slots are the lines of the left, centre and right slots
+	Slots [3][]Line
+	// wrappers laid out each slot
+	wrappers [3]*SimpleWrapper
+}
+
+// Height the height of the band, 0 if there is no band
+func (bl *BandLayout) Height() int {
+	if bl == nil {
+		return 0
+	}
+	return bl.Rect.Dy()
+}
+
+// slotRect where the slot is drawn
+func (bl *BandLayout) slotRect(slot int) image.Rectangle {
+	w := bl.Rect.Dx()
+	return image.Rect(bl.Rect.Min.X+w*slot/3, bl.Rect.Min.Y, bl.Rect.Min.X+w*(slot+1)/3, bl.Rect.Max.Y)
+}
+
+// Render draws the band's slots
+func (bl *BandLayout) Render(i Image, options ...DrawOption) error {
+	for slot, sw := range bl.wrappers {
+		if sw == nil {
+			continue
+		}
+		r := bl.slotRect(slot).Add(i.Bounds().Min)
+		if err := sw.RenderLines(i.SubImage(r).(Image), bl.Slots[slot], r.Min, options...); err != nil {
+			return fmt.Errorf("slot %d: %w", slot, err)
+		}
+	}
+	return nil
+}
+
+// layoutBand lays out the slots of the band width wide for the page in the section, the rectangle of the result being
+// at the origin
+func (sw *SimpleWrapper) layoutBand(ctx context.Context, pb *PageBand, page int, section string, width int) (*BandLayout, error) {
+	if pb == nil {
+		return nil, nil
+	}
+	bl := &BandLayout{}
+	height := 0
+	for slot, args := range pb.Slots {
+		if len(args) == 0 {
+			continue
+		}
+		contents, drawer, wrapperOptions, boxerOptions, _, tokenizer := ProcessRichArgs(args...)
+		if drawer == nil {
+			drawer = &font.Drawer{
+				Src:  sw.fontDrawer.Src,
+				Face: sw.fontDrawer.Face,
+			}
+		}
+		ssw := &SimpleWrapper{
+			fontDrawer:    drawer,
+			wrapperConfig: wrapperConfig{boxerOptions: boxerOptions},
+			currentPage:   page - 1,
+			fields: fieldState{
+				values:  sw.fields.values,
+				section: section,
+			},
+		}
+		ssw.ApplyOptions([]WrapperOption{LeftLines, HorizontalCenterLines, RightLines}[slot])
+		ssw.ApplyOptions(wrapperOptions...)
+		sb := NewSimpleBoxer(contents, drawer, ssw.boxerOptions...)
+		if tokenizer != nil {
+			sb.Tokenizer = tokenizer
+		}
+		ssw.boxer = sb
+		slotWidth := width*(slot+1)/3 - width*slot/3
+		ls, p, err := ssw.textToRect(ctx, image.Rect(0, 0, max(slotWidth, 1), unboundedCell))
+		if err != nil {
+			return nil, fmt.Errorf("slot %d: %w", slot, err)
+		}
+		if ssw.fields.pageCountUsed {
+			sw.fields.pageCountUsed = true
+		}
+		bl.Slots[slot] = ls
+		bl.wrappers[slot] = ssw
+		height = max(height, p.Y)
+	}
+	bl.Rect = image.Rect(0, 0, width, height)
+	return bl, nil
+}
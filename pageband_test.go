@@ -0,0 +1,148 @@
+package wordwrap
+
+import (
+	"image"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func bandText(bl *BandLayout, slot BandSlot) string {
+	if bl == nil {
+		return ""
+	}
+	return strings.Join(lineTexts(bl.Slots[slot]), " ")
+}
+
+func TestBandFor(t *testing.T) {
+	bands := []PageBand{
+		newPageBand([]interface{}{"All"}),
+		newPageBand([]interface{}{FirstPage, "First"}),
+		newPageBand([]interface{}{EvenPages, "Even"}),
+	}
+	var got []string
+	for page := 1; page <= 4; page++ {
+		got = append(got, bandFor(bands, page).Slots[BandLeft][0].(string))
+	}
+	if s := cmp.Diff([]string{"First", "Even", "All", "Even"}, got); s != "" {
+		t.Errorf("bands differ:\n%s", s)
+	}
+	if bandFor(bands[2:], 1) != nil {
+		t.Errorf("expected no band for an odd page")
+	}
+}
+
+func TestPageBands(t *testing.T) {
+	fontFace := FontFace16DPI180ForTest(t)
+	text := strings.Repeat("The quick brown fox jumps over the lazy dog. ", 12)
+
+	t.Run("Takes height from the content", func(t *testing.T) {
+		sw := NewRichWrapper(fontFace, text)
+		lr, err := sw.TextToSpecs(Width(Fixed(600)), Height(Fixed(400)), Padding(10, nil), Header(CenterSlot("Title")), Footer(RightSlot("Footer")))
+		if err != nil {
+			t.Fatalf("TextToSpecs failed: %v", err)
+		}
+		hh, fh := lr.Header.Height(), lr.Footer.Height()
+		if hh == 0 || fh == 0 {
+			t.Fatalf("expected a header and footer got heights %d and %d", hh, fh)
+		}
+		if lr.Header.Rect != image.Rect(10, 10, 590, 10+hh) || lr.Footer.Rect != image.Rect(10, 390-fh, 590, 390) {
+			t.Errorf("header %v footer %v", lr.Header.Rect, lr.Footer.Rect)
+		}
+		if lr.ContentStart != image.Pt(10, 10+hh) || lr.ContentSize != image.Pt(580, 380-hh-fh) {
+			t.Errorf("content at %v size %v", lr.ContentStart, lr.ContentSize)
+		}
+		h := 0
+		for _, l := range lr.Lines {
+			h += l.Size().Dy()
+		}
+		if h > lr.ContentSize.Y {
+			t.Errorf("lines take %d, more than the content area %d", h, lr.ContentSize.Y)
+		}
+	})
+
+	t.Run("Slots", func(t *testing.T) {
+		sw := NewRichWrapper(fontFace, "Body")
+		lr, err := sw.TextToSpecs(Width(Fixed(600)), Header(LeftSlot("L"), CenterSlot("C"), RightSlot("R")))
+		if err != nil {
+			t.Fatalf("TextToSpecs failed: %v", err)
+		}
+		for slot, want := range []string{"L", "C", "R"} {
+			if got := bandText(lr.Header, BandSlot(slot)); got != want {
+				t.Errorf("slot %d got %q", slot, got)
+			}
+		}
+		var rs []image.Rectangle
+		for slot, sw := range lr.Header.wrappers {
+			sr := lr.Header.slotRect(slot)
+			rs = append(rs, sw.lineRects(lr.Header.Slots[slot], sr, sr.Min)[0])
+		}
+		if rs[0].Min.X != 0 || rs[2].Max.X != 600 || (rs[1].Min.X-200)-(400-rs[1].Max.X) > 1 || (400-rs[1].Max.X)-(rs[1].Min.X-200) > 1 {
+			t.Errorf("slot lines at %v", rs)
+		}
+	})
+
+	t.Run("Variants on each page", func(t *testing.T) {
+		sw := NewRichWrapper(fontFace, text)
+		pages, err := sw.TextToSpecPages(Width(Fixed(600)), Height(Fixed(300)),
+			Header(FirstPage, "First"), Header(OddPages, "Odd"), Header(EvenPages, "Even"),
+			Footer(CenterSlot("Foot")),
+		)
+		if err != nil {
+			t.Fatalf("TextToSpecPages failed: %v", err)
+		}
+		if len(pages) < 3 {
+			t.Fatalf("expected several pages got %d", len(pages))
+		}
+		var body []string
+		for pi, lr := range pages {
+			want := map[bool]string{true: "Even", false: "Odd"}[pi%2 == 1]
+			if pi == 0 {
+				want = "First"
+			}
+			if got := bandText(lr.Header, BandLeft); got != want || lr.Page != pi+1 {
+				t.Errorf("page %d header %q", lr.Page, got)
+			}
+			if got := bandText(lr.Footer, BandCenter); got != "Foot" {
+				t.Errorf("page %d footer %q", lr.Page, got)
+			}
+			if lr.PageSize != image.Pt(600, 300) {
+				t.Errorf("page %d size %v", lr.Page, lr.PageSize)
+			}
+			body = append(body, lineTexts(lr.Lines)...)
+		}
+		if s := cmp.Diff(strings.Fields(text), strings.Fields(strings.Join(body, ""))); s != "" {
+			t.Errorf("text differs:\n%s", s)
+		}
+	})
+
+	t.Run("Renders", func(t *testing.T) {
+		sw := NewRichWrapper(fontFace, text)
+		pages, err := sw.TextToSpecPages(Width(Fixed(600)), Height(Fixed(300)), Header(CenterSlot("Title")))
+		if err != nil {
+			t.Fatalf("TextToSpecPages failed: %v", err)
+		}
+		lr := pages[0]
+		i := image.NewRGBA(image.Rectangle{Max: lr.PageSize})
+		if err := lr.Render(i); err != nil {
+			t.Fatalf("Render failed: %v", err)
+		}
+		inked := func(r image.Rectangle) bool {
+			for y := r.Min.Y; y < r.Max.Y; y++ {
+				for x := r.Min.X; x < r.Max.X; x++ {
+					if i.RGBAAt(x, y).A != 0 {
+						return true
+					}
+				}
+			}
+			return false
+		}
+		if !inked(lr.Header.slotRect(int(BandCenter))) || inked(lr.Header.slotRect(int(BandLeft))) {
+			t.Errorf("expected the title in the centre slot only")
+		}
+		if !inked(image.Rectangle{Min: lr.ContentStart, Max: lr.ContentStart.Add(lr.ContentSize)}) {
+			t.Errorf("expected the content drawn")
+		}
+	})
+}
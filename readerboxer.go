@@ -0,0 +1,245 @@
+package wordwrap
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+
+	"golang.org/x/image/font"
+)
+
+// readerChunkSize is the number of runes ReaderBoxer reads ahead at a time
+const readerChunkSize = 4096
+
+// ReaderBoxer is a Boxer like SimpleBoxer for plain text read lazily from an io.Reader. Only a small window of the text
+// is held at once, so text of any size can be wrapped a page at a time without loading it all into memory first.
+type ReaderBoxer struct {
+	postBoxOptions []func(Box)
+	source         io.Reader
+	reader         io.RuneReader
+	// buf is the window of decoded runes, n the index of the next rune to tokenize within it
+	buf []rune
+	n   int
+	// bufPos is the rune offset of the start of buf
+	bufPos       int
+	eof          bool
+	err          error
+	fontDrawer   *font.Drawer
+	Tokenizer    Tokenizer
+	cacheQueue   []Box
+	queueLen     int
+	measureCache *MeasureCache
+}
+
+// Ensures that ReaderBoxer fits model
+var _ Boxer = (*ReaderBoxer)(nil)
+
+// NewReaderBoxer creates a boxer which reads text from r as it is needed. r is wrapped with a bufio.Reader unless it is
+// already an io.RuneReader. Supports the same BoxerOptions as SimpleBoxer.
+func NewReaderBoxer(r io.Reader, drawer *font.Drawer, options ...BoxerOption) *ReaderBoxer {
+	rb := &ReaderBoxer{
+		source:     r,
+		fontDrawer: drawer,
+		Tokenizer:  LatinTokenizer,
+	}
+	rb.reader = runeReader(r)
+	for _, option := range options {
+		option.ApplyBoxConfig(rb)
+	}
+	return rb
+}
+
+// NewReaderWrapper creates a wrapper over text read lazily from r, for use with TextToRect or StreamPages one page at
+// a time.
+func NewReaderWrapper(r io.Reader, grf font.Face, opts ...WrapperOption) *SimpleWrapper {
+	sw := NewSimpleWrapper(nil, grf, opts...)
+	sw.boxer = NewReaderBoxer(r, sw.fontDrawer, sw.boxerOptions...)
+	return sw
+}
+
+// runeReader returns r as an io.RuneReader
+func runeReader(r io.Reader) io.RuneReader {
+	if rr, ok := r.(io.RuneReader); ok {
+		return rr
+	}
+	return bufio.NewReader(r)
+}
+
+// fill makes sure at least want runes are available after n unless the end of the reader is reached, reading ahead by
+// at least readerChunkSize runes at a time. Runes well behind n are dropped to make space.
+func (rb *ReaderBoxer) fill(want int) {
+	if rb.eof || len(rb.buf)-rb.n >= want {
+		return
+	}
+	if rb.n > 0 && rb.n >= len(rb.buf)/2 {
+		rb.bufPos += rb.n
+		rb.buf = append(rb.buf[:0], rb.buf[rb.n:]...)
+		rb.n = 0
+	}
+	want = max(want, readerChunkSize)
+	for len(rb.buf)-rb.n < want {
+		r, _, err := rb.reader.ReadRune()
+		if err != nil {
+			rb.eof = true
+			if err != io.EOF {
+				rb.err = err
+			}
+			break
+		}
+		rb.buf = append(rb.buf, r)
+	}
+}
+
+// Next gets the next word in a Box
+func (rb *ReaderBoxer) Next() (Box, int, error) {
+	if len(rb.cacheQueue) > 0 {
+		return rb.Shift(), 0, nil
+	}
+	want := 1
+	for {
+		rb.fill(want)
+		text := rb.buf[rb.n:]
+		if len(text) == 0 {
+			if rb.err != nil {
+				return nil, 0, fmt.Errorf("reading text at %d: %w", rb.Pos(), rb.err)
+			}
+			return nil, 0, nil
+		}
+		n, rs, rmode := rb.Tokenizer(text)
+		if n >= len(text) && !rb.eof {
+			// The token may continue past what has been read so far
+			want = len(text) * 2
+			continue
+		}
+		rb.n += n
+		var b Box
+		switch rmode {
+		case RNIL:
+			return nil, n, nil
+		case RSimpleBox, RCRLF:
+			var err error
+			b, err = newSimpleTextBox(rb.fontDrawer, string(rs), rb.measureCache)
+			if err != nil {
+				return nil, 0, err
+			}
+		default:
+			return nil, 0, fmt.Errorf("unknown rmode %d", rmode)
+		}
+		if rmode == RCRLF {
+			b = &LineBreakBox{
+				Box: b,
+			}
+		}
+		for _, option := range rb.postBoxOptions {
+			option(b)
+		}
+		return b, n, nil
+	}
+}
+
+// SetFontDrawer Changes the default font
+func (rb *ReaderBoxer) SetFontDrawer(face *font.Drawer) {
+	rb.fontDrawer = face
+}
+
+// FontDrawer encapsulates default fonts and more
+func (rb *ReaderBoxer) FontDrawer() *font.Drawer {
+	return rb.fontDrawer
+}
+
+// ErrBackTooFar is recorded by ReaderBoxer.Back when asked to unread runes it no longer holds
+var ErrBackTooFar = errors.New("unread past the runes still held")
+
+// Back goes back i spaces (ie unreads). Only text since the reader was last read from can be unread. Going back further
+// stops the boxer with ErrBackTooFar, which Err returns, as the text after where it can go back to would be wrong.
+func (rb *ReaderBoxer) Back(i int) {
+	if i > rb.n {
+		rb.err = fmt.Errorf("going back %d runes from %d: %w", i, rb.bufPos+rb.n, ErrBackTooFar)
+		rb.eof = true
+		rb.n = len(rb.buf)
+		return
+	}
+	rb.n -= i
+}
+
+// Err the error reading the text stopped at, if it didn't stop at the end. HasNext is false once reading fails, so
+// callers check Err when it is to tell a failed read from the end of the text.
+func (rb *ReaderBoxer) Err() error {
+	return rb.err
+}
+
+// errBoxer is a boxer which can stop early because its text failed to be read
+type errBoxer interface {
+	Err() error
+}
+
+// Ensures that ReaderBoxer fits model
+var _ errBoxer = (*ReaderBoxer)(nil)
+
+// boxerErr the error the wrapper's boxer stopped reading its text at, if any
+func (sw *SimpleWrapper) boxerErr() error {
+	if eb, ok := sw.boxer.(errBoxer); ok && eb.Err() != nil {
+		return fmt.Errorf("reading text at %d: %w", sw.boxer.Pos(), eb.Err())
+	}
+	return nil
+}
+
+// HasNext unprocessed text exists
+func (rb *ReaderBoxer) HasNext() bool {
+	if len(rb.cacheQueue) > 0 {
+		return true
+	}
+	rb.fill(1)
+	return rb.n < len(rb.buf)
+}
+
+// Push puts a box back on to the cache stack
+func (rb *ReaderBoxer) Push(box ...Box) {
+	rb.cacheQueue = append(rb.cacheQueue, box...)
+	rb.queueLen += boxesLen(box)
+}
+
+// Pos current parser position.
+func (rb *ReaderBoxer) Pos() int {
+	return rb.bufPos + rb.n - rb.queueLen
+}
+
+// Unshift basically is Push but to the start
+func (rb *ReaderBoxer) Unshift(box ...Box) {
+	rb.cacheQueue = append(append(make([]Box, 0, len(box)+len(rb.cacheQueue)), box...), rb.cacheQueue...)
+	rb.queueLen += boxesLen(box)
+}
+
+// Shift removes the first box of the cache
+func (rb *ReaderBoxer) Shift() Box {
+	if len(rb.cacheQueue) > 0 {
+		cb := rb.cacheQueue[0]
+		rb.cacheQueue = rb.cacheQueue[1:]
+		rb.queueLen -= cb.Len()
+		return cb
+	}
+	return nil
+}
+
+// Reset restarts from the beginning of the text if the reader is an io.Seeker, otherwise it only drops pushed boxes
+func (rb *ReaderBoxer) Reset() {
+	rb.cacheQueue = nil
+	rb.queueLen = 0
+	s, ok := rb.source.(io.Seeker)
+	if !ok {
+		return
+	}
+	if _, err := s.Seek(0, io.SeekStart); err != nil {
+		rb.err = err
+		return
+	}
+	if br, ok := rb.reader.(*bufio.Reader); ok {
+		br.Reset(rb.source)
+	}
+	rb.buf = rb.buf[:0]
+	rb.n = 0
+	rb.bufPos = 0
+	rb.eof = false
+	rb.err = nil
+}
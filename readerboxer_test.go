@@ -0,0 +1,162 @@
+package wordwrap
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func streamedLines(t *testing.T, sw *SimpleWrapper, r image.Rectangle) ([][]string, []int) {
+	var pages [][]string
+	var offsets []int
+	err := sw.StreamPages(r, func(page int, ls []Line) error {
+		if page != len(pages) {
+			t.Errorf("page %d out of order", page)
+		}
+		pages = append(pages, lineTexts(ls))
+		for _, l := range ls {
+			offsets = append(offsets, linePositionStats(l).RuneOffset)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("StreamPages failed: %v", err)
+	}
+	return pages, offsets
+}
+
+func TestReaderBoxer(t *testing.T) {
+	fontFace := FontFace16DPI180ForTest(t)
+	text := strings.Repeat("The quick brown fox jumps över the lazy dog.\r\nA line\nwith breaks ", 30)
+	r := image.Rect(0, 0, 400, 200)
+	wantPages, wantOffsets := streamedLines(t, NewRichWrapper(fontFace, text), r)
+	if len(wantPages) < 3 {
+		t.Fatalf("expected several pages got %d", len(wantPages))
+	}
+
+	readers := map[string]func() io.Reader{
+		"Rune reader":     func() io.Reader { return strings.NewReader(text) },
+		"One byte reader": func() io.Reader { return iotest.OneByteReader(bytes.NewBufferString(text)) },
+	}
+	for name, reader := range readers {
+		t.Run(name, func(t *testing.T) {
+			gotPages, gotOffsets := streamedLines(t, NewReaderWrapper(reader(), fontFace), r)
+			if s := cmp.Diff(wantPages, gotPages); s != "" {
+				t.Errorf("pages differ:\n%s", s)
+			}
+			if s := cmp.Diff(wantOffsets, gotOffsets); s != "" {
+				t.Errorf("rune offsets differ:\n%s", s)
+			}
+		})
+	}
+
+	t.Run("Holds a bounded window", func(t *testing.T) {
+		big := strings.Repeat("The quick brown fox jumps over the lazy dog. ", 1<<20/45)
+		sw := NewReaderWrapper(iotest.HalfReader(strings.NewReader(big)), fontFace)
+		rb := sw.boxer.(*ReaderBoxer)
+		largest := 0
+		err := sw.StreamPages(image.Rect(0, 0, 600, 2000), func(page int, ls []Line) error {
+			largest = max(largest, cap(rb.buf))
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("StreamPages failed: %v", err)
+		}
+		if rb.Pos() != len(big) {
+			t.Errorf("Pos() = %d want %d", rb.Pos(), len(big))
+		}
+		if largest > 4*readerChunkSize {
+			t.Errorf("buffer grew to %d runes", largest)
+		}
+	})
+
+	t.Run("Reset seeks", func(t *testing.T) {
+		sw := NewReaderWrapper(strings.NewReader(text), fontFace)
+		want, _, err := sw.TextToRect(r)
+		if err != nil {
+			t.Fatalf("TextToRect failed: %v", err)
+		}
+		sw.boxer.Reset()
+		got, _, err := sw.TextToRect(r)
+		if err != nil {
+			t.Fatalf("TextToRect failed: %v", err)
+		}
+		if s := cmp.Diff(lineTexts(want), lineTexts(got)); s != "" {
+			t.Errorf("lines after Reset differ:\n%s", s)
+		}
+	})
+
+	t.Run("Read errors", func(t *testing.T) {
+		failure := errors.New("disk on fire")
+		sw := NewReaderWrapper(io.MultiReader(strings.NewReader("Some text "), iotest.ErrReader(failure)), fontFace)
+		err := sw.StreamPages(r, func(page int, ls []Line) error { return nil })
+		if !errors.Is(err, failure) {
+			t.Errorf("expected the read error got %v", err)
+		}
+	})
+
+	t.Run("Read error at the end of a page", func(t *testing.T) {
+		failure := errors.New("disk on fire")
+		ls, _, err := NewRichWrapper(fontFace, "Some text").TextToRect(r)
+		if err != nil || len(ls) != 1 {
+			t.Fatalf("TextToRect failed: %v", err)
+		}
+		// The page ends with the last box read before the error, so nothing is left to read it with
+		page := image.Rect(0, 0, r.Dx(), ls[0].Size().Dy()-1)
+		source := func() io.Reader {
+			return io.MultiReader(strings.NewReader("Some text\n"), iotest.ErrReader(failure))
+		}
+		err = NewReaderWrapper(source(), fontFace).StreamPages(page, func(page int, ls []Line) error { return nil })
+		if !errors.Is(err, failure) {
+			t.Errorf("StreamPages: expected the read error got %v", err)
+		}
+		sw := NewReaderWrapper(source(), fontFace)
+		if _, _, err := sw.TextToRect(page); !errors.Is(err, failure) {
+			t.Errorf("TextToRect: expected the read error got %v", err)
+		}
+		if err := sw.boxer.(*ReaderBoxer).Err(); !errors.Is(err, failure) {
+			t.Errorf("Err() = %v, want the read error", err)
+		}
+	})
+
+	t.Run("Back past the window", func(t *testing.T) {
+		big := strings.Repeat("The quick brown fox jumps over the lazy dog. ", 1000)
+		rb := NewReaderBoxer(strings.NewReader(big), nil)
+		rb.SetFontDrawer(NewSimpleWrapper(nil, fontFace).fontDrawer)
+		n := 0
+		for rb.Pos() < 3*readerChunkSize {
+			var err error
+			if _, n, err = rb.Next(); err != nil {
+				t.Fatalf("Next failed: %v", err)
+			}
+		}
+		rb.Back(n)
+		if err := rb.Err(); err != nil {
+			t.Fatalf("going back within the window failed: %v", err)
+		}
+		rb.Back(rb.Pos())
+		if err := rb.Err(); !errors.Is(err, ErrBackTooFar) {
+			t.Errorf("Err() = %v, want ErrBackTooFar", err)
+		}
+		if rb.HasNext() {
+			t.Errorf("expected the boxer to stop")
+		}
+		if _, _, err := rb.Next(); !errors.Is(err, ErrBackTooFar) {
+			t.Errorf("Next() = %v, want ErrBackTooFar", err)
+		}
+	})
+
+	t.Run("No progress", func(t *testing.T) {
+		sw := NewReaderWrapper(strings.NewReader(text), fontFace)
+		err := sw.StreamPages(image.Rect(0, 0, 400, 2), func(page int, ls []Line) error { return nil })
+		if !errors.Is(err, ErrNoProgress) {
+			t.Errorf("expected ErrNoProgress got %v", err)
+		}
+	})
+}
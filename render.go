@@ -0,0 +1,226 @@
+package wordwrap
+
+import (
+	"fmt"
+	"image"
+	"image/draw"
+	"runtime"
+	"sync"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+// Page is a set of lines to draw on to an image with RenderPages, such as the lines of a page from TextToRect or
+// TextToSpecs
+type Page struct {
+	Image Image
+	Lines []Line
+	// At is where the lines start, as with RenderLines
+	At      image.Point
+	Options []DrawOption
+}
+
+// RenderConfig options for RenderPages
+type RenderConfig struct {
+	// Workers is the number of pages drawn at once, defaulting to GOMAXPROCS
+	Workers int
+	// CloneFace creates a copy of a face for the exclusive use of one worker. If nil, or it returns nil, workers share
+	// the face through a LockedFace
+	CloneFace func(font.Face) font.Face
+}
+
+// RenderOption options for RenderPages
+type RenderOption interface {
+	ApplyRender(*RenderConfig)
+}
+
+// WorkersOption sets the number of pages drawn at once
+type WorkersOption int
+
+// ApplyRender applies the worker count
+func (w WorkersOption) ApplyRender(c *RenderConfig) {
+	c.Workers = int(w)
+}
+
+// Workers sets the number of pages RenderPages draws at once
+func Workers(n int) WorkersOption {
+	return WorkersOption(n)
+}
+
+// CloneFacesOption sets the function used to give each worker its own copy of a face
+type CloneFacesOption func(font.Face) font.Face
+
+// ApplyRender applies the clone function
+func (f CloneFacesOption) ApplyRender(c *RenderConfig) {
+	c.CloneFace = f
+}
+
+// CloneFaces gives each RenderPages worker its own copy of every face, created with f, so glyphs can be rasterized
+// without waiting on other workers. For instance, by calling opentype.NewFace again with the same font.
+func CloneFaces(f func(font.Face) font.Face) CloneFacesOption {
+	return CloneFacesOption(f)
+}
+
+// RenderPages draws each page as RenderLines would, drawing several pages at once. Faces are shared between workers
+// through a LockedFace, or copied per worker with CloneFaces, so the output is identical to drawing the pages one after
+// the other. Pages must not share destination pixels, and any callbacks in the draw options, such as a BoxRecorder,
+// must be safe for concurrent use. The error of the first page that failed is returned.
+func (sw *SimpleWrapper) RenderPages(pages []Page, opts ...RenderOption) error {
+	config := RenderConfig{
+		Workers: runtime.GOMAXPROCS(0),
+	}
+	for _, opt := range opts {
+		opt.ApplyRender(&config)
+	}
+	workers := min(config.Workers, len(pages))
+	if workers <= 1 {
+		for pi, p := range pages {
+			if err := sw.RenderLines(p.Image, p.Lines, p.At, p.Options...); err != nil {
+				return fmt.Errorf("rendering page %d: %w", pi, err)
+			}
+		}
+		return nil
+	}
+	locked := &lockedFaces{
+		faces: map[font.Face]*LockedFace{},
+	}
+	errs := make([]error, len(pages))
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			mapper := locked.get
+			if config.CloneFace != nil {
+				mapper = clonedFaces(config.CloneFace, locked)
+			}
+			for pi := range next {
+				p := pages[pi]
+				options := append([]DrawOption{FaceMapper(mapper)}, p.Options...)
+				errs[pi] = sw.RenderLines(p.Image, p.Lines, p.At, options...)
+			}
+		}()
+	}
+	for pi := range pages {
+		next <- pi
+	}
+	close(next)
+	wg.Wait()
+	for pi, err := range errs {
+		if err != nil {
+			return fmt.Errorf("rendering page %d: %w", pi, err)
+		}
+	}
+	return nil
+}
+
+// clonedFaces creates a face mapper for one worker which uses its own copy of every face, falling back to the shared
+// locked face if a face can't be copied
+func clonedFaces(clone func(font.Face) font.Face, locked *lockedFaces) FaceMapper {
+	faces := map[font.Face]font.Face{}
+	return func(face font.Face) font.Face {
+		if f, ok := faces[face]; ok {
+			return f
+		}
+		f := clone(face)
+		if f == nil {
+			f = locked.get(face)
+		}
+		faces[face] = f
+		return f
+	}
+}
+
+// lockedFaces the LockedFace used for each face shared between workers
+type lockedFaces struct {
+	mu    sync.Mutex
+	faces map[font.Face]*LockedFace
+}
+
+// get returns the LockedFace for face
+func (lf *lockedFaces) get(face font.Face) font.Face {
+	lf.mu.Lock()
+	defer lf.mu.Unlock()
+	f, ok := lf.faces[face]
+	if !ok {
+		f = NewLockedFace(face)
+		lf.faces[face] = f
+	}
+	return f
+}
+
+// LockedFace is a font.Face which allows a face that isn't safe for concurrent use, such as those from truetype and
+// opentype, to be shared between goroutines by only calling it by one at a time. Glyph masks are copied as faces reuse
+// their mask buffers.
+type LockedFace struct {
+	mu   sync.Mutex
+	face font.Face
+}
+
+// Interface enforcement
+var _ font.Face = (*LockedFace)(nil)
+
+// NewLockedFace wraps face
+func NewLockedFace(face font.Face) *LockedFace {
+	return &LockedFace{
+		face: face,
+	}
+}
+
+// Close closes the wrapped face
+func (lf *LockedFace) Close() error {
+	lf.mu.Lock()
+	defer lf.mu.Unlock()
+	return lf.face.Close()
+}
+
+// Glyph returns the wrapped face's glyph, with a mask that is safe to keep. A GlyphCache copies the mask only once, as
+// it is cached, rather than on every call.
+func (lf *LockedFace) Glyph(dot fixed.Point26_6, r rune) (image.Rectangle, image.Image, image.Point, fixed.Int26_6, bool) {
+	lf.mu.Lock()
+	defer lf.mu.Unlock()
+	dr, mask, maskp, advance, ok := lf.face.Glyph(dot, r)
+	if mask == nil || dr.Empty() {
+		return dr, mask, maskp, advance, ok
+	}
+	m := image.NewAlpha(image.Rectangle{Max: dr.Size()})
+	draw.Draw(m, m.Rect, mask, maskp, draw.Src)
+	return dr, m, image.Point{}, advance, ok
+}
+
+// withGlyph calls fn with the wrapped face's glyph while it is locked, so fn can copy the mask itself
+func (lf *LockedFace) withGlyph(dot fixed.Point26_6, r rune, fn func(image.Rectangle, image.Image, image.Point, fixed.Int26_6, bool)) {
+	lf.mu.Lock()
+	defer lf.mu.Unlock()
+	fn(lf.face.Glyph(dot, r))
+}
+
+// GlyphBounds returns the wrapped face's glyph bounds
+func (lf *LockedFace) GlyphBounds(r rune) (fixed.Rectangle26_6, fixed.Int26_6, bool) {
+	lf.mu.Lock()
+	defer lf.mu.Unlock()
+	return lf.face.GlyphBounds(r)
+}
+
+// GlyphAdvance returns the wrapped face's glyph advance
+func (lf *LockedFace) GlyphAdvance(r rune) (fixed.Int26_6, bool) {
+	lf.mu.Lock()
+	defer lf.mu.Unlock()
+	return lf.face.GlyphAdvance(r)
+}
+
+// Kern returns the wrapped face's kerning
+func (lf *LockedFace) Kern(r0, r1 rune) fixed.Int26_6 {
+	lf.mu.Lock()
+	defer lf.mu.Unlock()
+	return lf.face.Kern(r0, r1)
+}
+
+// Metrics returns the wrapped face's metrics
+func (lf *LockedFace) Metrics() font.Metrics {
+	lf.mu.Lock()
+	defer lf.mu.Unlock()
+	return lf.face.Metrics()
+}
@@ -0,0 +1,111 @@
+package wordwrap
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+
+	"github.com/arran4/golang-wordwrap/util"
+	"golang.org/x/image/font"
+)
+
+func TestRenderPages(t *testing.T) {
+	gr, err := util.OpenFont("goregular")
+	if err != nil {
+		t.Fatalf("Error opening font: %s", err)
+	}
+	regular := util.GetFontFace(16, 180, gr)
+	large := util.GetFontFace(24, 75, gr)
+	text := strings.Repeat("The quick brown fox jumps over the lazy dog. ", 40)
+	pages := func(t *testing.T, options ...DrawOption) (*SimpleWrapper, []Page) {
+		sw := NewRichWrapper(regular, text, large, Color(color.RGBA{0, 0, 200, 255}, text))
+		var ps []Page
+		for sw.HasNext() {
+			img := image.NewRGBA(image.Rect(0, 0, 400, 300))
+			lines, _, err := sw.TextToRect(img.Bounds())
+			if err != nil {
+				t.Fatalf("TextToRect failed: %v", err)
+			}
+			if len(lines) == 0 {
+				t.Fatalf("no progress laying out page %d", len(ps))
+			}
+			ps = append(ps, Page{Image: img, Lines: lines, At: img.Bounds().Min, Options: options})
+		}
+		if len(ps) < 4 {
+			t.Fatalf("expected several pages got %d", len(ps))
+		}
+		return sw, ps
+	}
+	selection := Selection{Start: 10, End: 2000, Color: color.RGBA{255, 255, 0, 255}}
+	sw, want := pages(t, selection)
+	if err := sw.RenderPages(want, Workers(1)); err != nil {
+		t.Fatalf("serial RenderPages failed: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		options []DrawOption
+		render  []RenderOption
+	}{
+		{name: "Locked faces", options: []DrawOption{selection}, render: []RenderOption{Workers(4)}},
+		{name: "Locked faces with glyph cache", options: []DrawOption{selection, NewGlyphCache(0)}, render: []RenderOption{Workers(4)}},
+		{
+			name:    "Cloned faces",
+			options: []DrawOption{selection},
+			render: []RenderOption{Workers(3), CloneFaces(func(f font.Face) font.Face {
+				if f == regular {
+					return util.GetFontFace(16, 180, gr)
+				}
+				return nil
+			})},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sw, got := pages(t, tt.options...)
+			if err := sw.RenderPages(got, tt.render...); err != nil {
+				t.Fatalf("RenderPages failed: %v", err)
+			}
+			if len(got) != len(want) {
+				t.Fatalf("got %d pages want %d", len(got), len(want))
+			}
+			for i := range want {
+				if !bytes.Equal(want[i].Image.(*image.RGBA).Pix, got[i].Image.(*image.RGBA).Pix) {
+					t.Errorf("page %d differs from serial rendering", i)
+				}
+			}
+		})
+	}
+	t.Run("Glyph cache shared by locked and cloned faces", func(t *testing.T) {
+		gc := NewGlyphCache(0)
+		renders := [][]RenderOption{
+			{Workers(4)},
+			{Workers(4)},
+			{Workers(3), CloneFaces(func(f font.Face) font.Face {
+				if f == regular {
+					return util.GetFontFace(16, 180, gr)
+				}
+				return nil
+			})},
+		}
+		size := 0
+		for ri, render := range renders {
+			sw, got := pages(t, selection, gc)
+			if err := sw.RenderPages(got, render...); err != nil {
+				t.Fatalf("RenderPages %d failed: %v", ri, err)
+			}
+			for i := range want {
+				if !bytes.Equal(want[i].Image.(*image.RGBA).Pix, got[i].Image.(*image.RGBA).Pix) {
+					t.Errorf("render %d page %d differs from serial rendering", ri, i)
+				}
+			}
+			if ri == 0 {
+				size = gc.Len()
+			} else if gc.Len() != size {
+				t.Errorf("render %d cached %d glyphs want the %d of the first render", ri, gc.Len(), size)
+			}
+		}
+	})
+}
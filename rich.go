@@ -4,6 +4,7 @@ import (
 	"image"
 	"image/color"
 	"image/draw"
+	"log"
 
 	"golang.org/x/image/font"
 	"golang.org/x/image/math/fixed"
@@ -206,6 +207,7 @@ func Align(a BaselineAlignment, args ...interface{}) interface{} {
 func ProcessRichArgs(args ...interface{}) ([]*Content, *font.Drawer, []WrapperOption, []BoxerOption, Boxer, Tokenizer) {
 	state := &rcState{
 		currentStyle: &Style{},
+		footnotes:    new(int),
 	}
 	state.process(args)
 	if state.drawer == nil && state.defaultFont != nil {
@@ -231,6 +233,87 @@ type rcState struct {
 	currentID             interface{}
 	inBorder              bool
 	currentDecoratorTypes []string
+
+	// table is the table rows and cells are being added to
+	table *tableContent
+	// tableFrom and rowFrom are the number of contents there were when the table and the row in it were started, the
+	// contents added after them being outside a cell
+	tableFrom int
+	rowFrom   int
+	// inRow is set while the args of a row are processed
+	inRow bool
+	// colSpan and rowSpan are the spans of the cell being processed
+	colSpan int
+	rowSpan int
+	// list is the list items and list options are being added to
+	list *listContent
+	// footnotes is the number of footnotes so far, shared with the states of containers
+	footnotes *int
+}
+
+// strayCell puts the contents added to the row since from outside of a cell into a cell of their own, so they stay in
+// the table
+func (s *rcState) strayCell(from int) {
+	if len(s.contents) <= from {
+		return
+	}
+	cell := &tableCell{
+		contents: append([]*Content(nil), s.contents[from:]...),
+	}
+	s.contents = s.contents[:from]
+	row := len(s.table.rows) - 1
+	s.table.rows[row] = append(s.table.rows[row], cell)
+}
+
+// strayRow puts the content added to the table outside of a row into a row of its own, so it stays in the table
+func (s *rcState) strayRow() {
+	if len(s.contents) <= s.tableFrom {
+		return
+	}
+	s.table.rows = append(s.table.rows, nil)
+	s.strayCell(s.tableFrom)
+}
+
+// processScoped processes the args with any style changes they make undone afterwards
+func (s *rcState) processScoped(args []interface{}) {
+	prevStyle := s.currentStyle
+	prevFont := s.currentFont
+	prevID := s.currentID
+	prevInBorder := s.inBorder
+	prevDecoratorTypes := s.currentDecoratorTypes
+
+	s.currentStyle = s.cloneStyle()
+	s.currentDecoratorTypes = append([]string(nil), s.currentDecoratorTypes...)
+	s.process(args)
+
+	s.currentStyle = prevStyle
+	s.currentFont = prevFont
+	s.currentID = prevID
+	s.inBorder = prevInBorder
+	s.currentDecoratorTypes = prevDecoratorTypes
+}
+
+// childState returns the state for the content of a container, which inherits the font and colour but not the
+// decorators so the content isn't wrapped in them as well as the container
+func (s *rcState) childState() *rcState {
+	subS := &rcState{
+		currentStyle:          s.cloneStyle(),
+		currentFont:           s.currentFont,
+		defaultFont:           s.defaultFont,
+		drawer:                s.drawer,
+		boxerOptions:          s.boxerOptions,
+		tokenizer:             s.tokenizer,
+		footnotes:             s.footnotes,
+		currentDecoratorTypes: append([]string(nil), s.currentDecoratorTypes...),
+	}
+
+	// Clear decorators for children so they don't get double-wrapped
+	if subS.currentStyle != nil {
+		subS.currentStyle.Decorators = nil
+		subS.currentDecoratorTypes = nil
+		// Keep Effects and Alignment (inherited).
+	}
+	return subS
 }
 
 func (s *rcState) cloneStyle() *Style {
@@ -297,42 +380,11 @@ func (s *rcState) process(args []interface{}) {
 	for _, arg := range args {
 		switch v := arg.(type) {
 		case Group:
-			prevStyle := s.currentStyle
-			prevFont := s.currentFont
-			prevID := s.currentID
-			prevInBorder := s.inBorder
-			prevDecoratorTypes := s.currentDecoratorTypes
-
-			s.currentStyle = s.cloneStyle()
-			s.currentDecoratorTypes = append([]string(nil), s.currentDecoratorTypes...)
-			s.process(v.Args)
-
-			s.currentStyle = prevStyle
-			s.currentFont = prevFont
-			s.currentID = prevID
-			s.inBorder = prevInBorder
-			s.currentDecoratorTypes = prevDecoratorTypes
+			s.processScoped(v.Args)
 
 		case ContainerGroup:
 			// Isolate content: Children inherit Font/Color but not decorators (Margin/Padding/Bg) from the container.
-
-			// Clone state for children
-			subS := &rcState{
-				currentStyle:          s.cloneStyle(),
-				currentFont:           s.currentFont,
-				defaultFont:           s.defaultFont,
-				drawer:                s.drawer,
-				boxerOptions:          s.boxerOptions,
-				tokenizer:             s.tokenizer,
-				currentDecoratorTypes: append([]string(nil), s.currentDecoratorTypes...),
-			}
-
-			// Clear decorators for children so they don't get double-wrapped
-			if subS.currentStyle != nil {
-				subS.currentStyle.Decorators = nil
-				subS.currentDecoratorTypes = nil
-				// Keep Effects and Alignment (inherited).
-			}
+			subS := s.childState()
 
 			// Process children
 			subS.process(v.Args)
@@ -524,6 +576,120 @@ func (s *rcState) process(args []interface{}) {
 			}
 			s.currentStyle.Decorators = append(s.currentStyle.Decorators, d)
 			s.currentDecoratorTypes = append(s.currentDecoratorTypes, "MinSize")
+		case TableGroup:
+			prevTable, prevTableFrom, prevInRow := s.table, s.tableFrom, s.inRow
+			s.table, s.tableFrom, s.inRow = &tableContent{}, len(s.contents), false
+			s.processScoped(v.Args)
+			s.strayRow()
+			s.contents = append(s.contents, &Content{table: s.table})
+			s.table, s.tableFrom, s.inRow = prevTable, prevTableFrom, prevInRow
+		case TableRowGroup:
+			if s.table == nil {
+				log.Printf("row outside of a table")
+				continue
+			}
+			s.strayRow()
+			s.table.rows = append(s.table.rows, nil)
+			prevRowFrom, prevInRow := s.rowFrom, s.inRow
+			s.rowFrom, s.inRow = len(s.contents), true
+			s.processScoped(v.Args)
+			s.strayCell(s.rowFrom)
+			s.rowFrom, s.inRow = prevRowFrom, prevInRow
+		case TableCellGroup:
+			if s.table == nil || len(s.table.rows) == 0 {
+				log.Printf("cell outside of a table row")
+				continue
+			}
+			if s.inRow {
+				s.strayCell(s.rowFrom)
+			}
+			// Like a container the decorators in effect wrap the cell rather than its content
+			subS := s.childState()
+			subS.process(v.Args)
+			cell := &tableCell{
+				contents: subS.contents,
+				colSpan:  subS.colSpan,
+				rowSpan:  subS.rowSpan,
+			}
+			if s.currentStyle != nil {
+				cell.decorators = append([]func(Box) Box(nil), s.currentStyle.Decorators...)
+			}
+			row := len(s.table.rows) - 1
+			s.table.rows[row] = append(s.table.rows[row], cell)
+		case TableColumnsOption:
+			if s.table != nil {
+				s.table.columns = v
+			}
+		case HeaderRowsOption:
+			if s.table != nil {
+				s.table.headerRows = int(v)
+			}
+		case ColSpanOption:
+			s.colSpan = int(v)
+		case RowSpanOption:
+			s.rowSpan = int(v)
+		case FootnoteGroup:
+			*s.footnotes++
+			fc := &footnoteContent{number: *s.footnotes}
+			// The number is drawn in the font and colour in effect, the body inherits them as the content of a container
+			style := &Style{}
+			if s.currentStyle != nil {
+				style.font = s.currentStyle.font
+				style.FontDrawerSrc = s.currentStyle.FontDrawerSrc
+			}
+			subS := s.childState()
+			subS.process(v.Args)
+			fc.contents = subS.contents
+			s.contents = append(s.contents, &Content{footnote: fc, style: style})
+		case ListGroup:
+			prevList := s.list
+			s.list = &listContent{start: 1}
+			// Markers are drawn in the list's font and colour
+			style := &Style{}
+			if s.currentStyle != nil {
+				style.font = s.currentStyle.font
+				style.FontDrawerSrc = s.currentStyle.FontDrawerSrc
+			}
+			s.processScoped(v.Args)
+			s.contents = append(s.contents, &Content{list: s.list, style: style})
+			s.list = prevList
+		case ListItemGroup:
+			if s.list == nil {
+				log.Printf("item outside of a list")
+				continue
+			}
+			list, contents := s.list, s.contents
+			s.list, s.contents = nil, nil
+			s.processScoped(v.Args)
+			list.items = append(list.items, &listItem{contents: s.contents})
+			s.list, s.contents = list, contents
+		case ListStyle:
+			if s.list != nil {
+				s.list.style = v
+			}
+		case BulletOption:
+			if s.list != nil {
+				s.list.bullet = string(v)
+			}
+		case MarkerImageOption:
+			if s.list != nil {
+				s.list.image = v.Image
+			}
+		case ListStartOption:
+			if s.list != nil {
+				s.list.start = int(v)
+			}
+		case ListIndentOption:
+			if s.list != nil {
+				s.list.indent = int(v)
+			}
+		case FloatSide:
+			if s.currentStyle == nil {
+				s.currentStyle = &Style{}
+			}
+			// Floats must be the outermost decorator for the folder to see them
+			s.currentStyle.Decorators = append([]func(Box) Box{floatDecorator(v)}, s.currentStyle.Decorators...)
+			s.currentDecoratorTypes = append([]string{"Float"}, s.currentDecoratorTypes...)
 		case ResetOption:
 			s.currentStyle = &Style{}
 			s.currentDecoratorTypes = nil
@@ -540,6 +706,11 @@ func (s *rcState) process(args []interface{}) {
 				s.currentStyle = &Style{}
 			}
 			s.currentStyle.Effects = append(s.currentStyle.Effects, v)
+		case WhiteSpace:
+			if s.currentStyle == nil {
+				s.currentStyle = &Style{}
+			}
+			s.currentStyle.WhiteSpace = v
 		case BaselineAlignmentOption:
 			if s.currentStyle == nil {
 				s.currentStyle = &Style{}
@@ -555,33 +726,16 @@ func (s *rcState) process(args []interface{}) {
 				}
 			}
 		case string:
-			var opts []ContentOption
-			if s.currentStyle != nil {
-				if s.currentStyle.font != nil {
-					opts = append(opts, WithFont(s.currentStyle.font))
-				}
-				if s.currentStyle.FontDrawerSrc != nil {
-					opts = append(opts, WithFontImage(s.currentStyle.FontDrawerSrc))
-				}
-				if s.currentStyle.Alignment != AlignBaseline {
-					opts = append(opts, WithAlignment(s.currentStyle.Alignment))
-				}
-				if len(s.currentStyle.Decorators) > 0 {
-					opts = append(opts, WithDecorators(s.currentStyle.Decorators...))
-				}
-				if s.currentStyle.MinSize != (fixed.Point26_6{}) {
-					opts = append(opts, WithMinSize(s.currentStyle.MinSize))
-				}
-			}
-			if len(s.currentStyle.Effects) > 0 {
-				opts = append(opts, WithBoxEffects(s.currentStyle.Effects))
-			}
-			if s.currentID != nil {
-				opts = append(opts, WithID(s.currentID))
-			}
-
-			c := NewContent(v, opts...)
+			s.contents = append(s.contents, NewContent(v, s.textOptions()...))
+		case FieldContent:
+			c := NewContent("", s.textOptions()...)
+			c.field = &v
 			s.contents = append(s.contents, c)
+		case SectionGroup:
+			n := len(s.contents)
+			s.processScoped(v.Args)
+			mark := &Content{section: &sectionContent{title: contentText(s.contents[n:])}}
+			s.contents = append(s.contents[:n], append([]*Content{mark}, s.contents[n:]...)...)
 		case ImageContent:
 			var opts []ContentOption
 			if s.currentStyle != nil {
@@ -615,6 +769,38 @@ func (s *rcState) process(args []interface{}) {
 	}
 }
 
+// textOptions the options of text content in the current style
+func (s *rcState) textOptions() []ContentOption {
+	var opts []ContentOption
+	if s.currentStyle != nil {
+		if s.currentStyle.font != nil {
+			opts = append(opts, WithFont(s.currentStyle.font))
+		}
+		if s.currentStyle.FontDrawerSrc != nil {
+			opts = append(opts, WithFontImage(s.currentStyle.FontDrawerSrc))
+		}
+		if s.currentStyle.Alignment != AlignBaseline {
+			opts = append(opts, WithAlignment(s.currentStyle.Alignment))
+		}
+		if len(s.currentStyle.Decorators) > 0 {
+			opts = append(opts, WithDecorators(s.currentStyle.Decorators...))
+		}
+		if s.currentStyle.MinSize != (fixed.Point26_6{}) {
+			opts = append(opts, WithMinSize(s.currentStyle.MinSize))
+		}
+		if s.currentStyle.WhiteSpace != WhiteSpacePreWrap {
+			opts = append(opts, WithWhiteSpace(s.currentStyle.WhiteSpace))
+		}
+	}
+	if len(s.currentStyle.Effects) > 0 {
+		opts = append(opts, WithBoxEffects(s.currentStyle.Effects))
+	}
+	if s.currentID != nil {
+		opts = append(opts, WithID(s.currentID))
+	}
+	return opts
+}
+
 // WithBoxEffects sets the effects
 func WithBoxEffects(e []BoxEffect) ContentOption {
 	return func(c *Content) {
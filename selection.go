@@ -0,0 +1,109 @@
+package wordwrap
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+
+	"golang.org/x/image/math/fixed"
+)
+
+// Selection is a DrawOption that paints a highlight behind the runes in the source contents from Start (inclusive) to
+// End (exclusive)
+type Selection struct {
+	Start int
+	End   int
+	Color color.Color
+}
+
+// Apply installs the selection
+func (s Selection) Apply(config *DrawConfig) {
+	config.Selection = &s
+}
+
+// Interface enforcement
+var _ DrawOption = Selection{}
+
+// SelectionRects returns the rectangles covering the runes from start (inclusive) to end (exclusive) for lines rendered
+// with RenderLines to an image with the given bounds at the given starting point. There is at most one rectangle per
+// line. Lines without position stats (ie not produced by TextToRect) are skipped.
+func (sw *SimpleWrapper) SelectionRects(ls []Line, bounds image.Rectangle, at image.Point, start, end int) []image.Rectangle {
+	var rs []image.Rectangle
+	for li, lr := range sw.lineRects(ls, bounds, at) {
+		if r, ok := lineSelectionRect(ls[li], lr, start, end, nil); ok {
+			rs = append(rs, r)
+		}
+	}
+	return rs
+}
+
+// lineSelectionRect calculates the part of the line drawn in r that covers the runes from start to end. Text is measured
+// with the faces of the draw config if there is one.
+func lineSelectionRect(l Line, r image.Rectangle, start, end int, dc *DrawConfig) (image.Rectangle, bool) {
+	stats := linePositionStats(l)
+	if stats == nil || start >= end {
+		return image.Rectangle{}, false
+	}
+	var sel image.Rectangle
+	found := false
+	offset := stats.RuneOffset
+	boxes := l.Boxes()
+	for bi, br := range boxRects(boxes, r) {
+		b := boxes[bi]
+		offset += floatRunesBefore(l, bi)
+		n := b.Len()
+		bStart, bEnd := offset, offset+n
+		offset = bEnd
+		if bEnd <= start || bStart >= end || br.Empty() {
+			continue
+		}
+		if start > bStart || end < bEnd {
+			if tb, shift := textBoxWithin(b); tb != nil {
+				minX := br.Min.X + (shift + tb.prefixAdvance(start-bStart, dc)).Floor()
+				maxX := br.Min.X + (shift + tb.prefixAdvance(end-bStart, dc)).Ceil()
+				if minX > br.Min.X {
+					br.Min.X = minX
+				}
+				if maxX < br.Max.X {
+					br.Max.X = maxX
+				}
+			}
+		}
+		if !found {
+			sel = br
+			found = true
+		} else {
+			sel = sel.Union(br)
+		}
+	}
+	return sel, found && !sel.Empty()
+}
+
+// textBoxWithin finds the text box a decorated box draws, and how far in from the box start the text begins. Returns
+// nil if there isn't exactly one text box.
+func textBoxWithin(b Box) (*SimpleTextBox, fixed.Int26_6) {
+	var shift fixed.Int26_6
+	for b != nil {
+		switch bb := b.(type) {
+		case *SimpleTextBox:
+			return bb, shift
+		case *DecorationBox:
+			shift += fixed.I(bb.Margin.Min.X.Ceil() + bb.Padding.Min.X.Ceil())
+			b = bb.Box
+		default:
+			b = innerBox(b)
+		}
+	}
+	return nil, 0
+}
+
+// drawSelection paints the configured selection behind the line's boxes
+func drawSelection(i Image, l Line, dc *DrawConfig) {
+	s := dc.Selection
+	if s.Color == nil {
+		return
+	}
+	if r, ok := lineSelectionRect(l, i.Bounds(), s.Start, s.End, dc); ok {
+		draw.Draw(i, r, image.NewUniform(s.Color), image.Point{}, draw.Over)
+	}
+}
@@ -0,0 +1,114 @@
+package wordwrap
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestSimpleWrapper_SelectionRects(t *testing.T) {
+	fontFace := FontFace16DPI180ForTest(t)
+	text := "Testing this! Testing this!"
+	sw := NewSimpleWrapper([]*Content{{text: text}}, fontFace)
+	bounds := SpaceFor(fontFace, "Testing this!", "Testing this!")
+	lines, _, err := sw.TextToRect(bounds)
+	if err != nil {
+		t.Fatalf("TextToRect failed: %v", err)
+	}
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines got %d", len(lines))
+	}
+	if got := linePositionStats(lines[1]).RuneOffset; got != len("Testing this! ") {
+		t.Fatalf("second line RuneOffset = %d, want %d", got, len("Testing this! "))
+	}
+	lrs := sw.lineRects(lines, bounds, bounds.Min)
+
+	t.Run("Within a single word", func(t *testing.T) {
+		rs := sw.SelectionRects(lines, bounds, bounds.Min, 1, 3)
+		if len(rs) != 1 {
+			t.Fatalf("expected 1 rect got %v", rs)
+		}
+		tb := lines[0].Boxes()[0].(*SimpleTextBox)
+		if rs[0].Min.X != lrs[0].Min.X+tb.PrefixAdvance(1).Floor() {
+			t.Errorf("rect starts at %d", rs[0].Min.X)
+		}
+		if rs[0].Max.X != lrs[0].Min.X+tb.PrefixAdvance(3).Ceil() {
+			t.Errorf("rect ends at %d", rs[0].Max.X)
+		}
+		if rs[0].Min.Y != lrs[0].Min.Y || rs[0].Max.Y != lrs[0].Max.Y {
+			t.Errorf("rect %v does not cover line height %v", rs[0], lrs[0])
+		}
+	})
+
+	t.Run("Across wrapped lines", func(t *testing.T) {
+		rs := sw.SelectionRects(lines, bounds, bounds.Min, 8, len(text)-1)
+		if len(rs) != 2 {
+			t.Fatalf("expected 2 rects got %v", rs)
+		}
+		if rs[0].Min.Y != lrs[0].Min.Y || rs[1].Min.Y != lrs[1].Min.Y {
+			t.Errorf("rects %v not on the lines %v", rs, lrs)
+		}
+		if rs[1].Min.X != lrs[1].Min.X {
+			t.Errorf("second line selection should start at the line start, got %d", rs[1].Min.X)
+		}
+		if rs[1].Max.X >= lrs[1].Max.X {
+			t.Errorf("second line selection should stop before the final rune, got %d", rs[1].Max.X)
+		}
+	})
+
+	t.Run("Empty selection", func(t *testing.T) {
+		if rs := sw.SelectionRects(lines, bounds, bounds.Min, 4, 4); len(rs) != 0 {
+			t.Errorf("expected no rects got %v", rs)
+		}
+	})
+
+	t.Run("Draw option paints behind text", func(t *testing.T) {
+		img := image.NewRGBA(bounds)
+		yellow := color.RGBA{255, 255, 0, 255}
+		if err := sw.RenderLines(img, lines, bounds.Min, Selection{Start: 0, End: 7, Color: yellow}); err != nil {
+			t.Fatalf("RenderLines failed: %v", err)
+		}
+		rs := sw.SelectionRects(lines, bounds, bounds.Min, 0, 7)
+		if len(rs) != 1 {
+			t.Fatalf("expected 1 rect got %v", rs)
+		}
+		if got := img.RGBAAt(rs[0].Min.X, rs[0].Min.Y); got != yellow {
+			t.Errorf("expected selection colour at %v got %v", rs[0].Min, got)
+		}
+		if got := img.RGBAAt(rs[0].Max.X+1, rs[0].Min.Y); got == yellow {
+			t.Errorf("selection colour painted outside of selection")
+		}
+	})
+}
+
+func TestSimpleWrapper_SelectionRectsAfterFloat(t *testing.T) {
+	fontFace := FontFace16DPI180ForTest(t)
+	sw := NewRichWrapper(fontFace, "Some text ", Float(FloatLeft, Container("boxed")), "more text")
+	bounds := image.Rect(0, 0, 600, 400)
+	lines, _, err := sw.TextToRect(bounds)
+	if err != nil {
+		t.Fatalf("TextToRect failed: %v", err)
+	}
+	if len(lines) != 1 {
+		t.Fatalf("expected 1 line got %d", len(lines))
+	}
+	more := -1
+	for bi, b := range lines[0].Boxes() {
+		if b.TextValue() == "more" {
+			more = bi
+		}
+	}
+	if more < 0 {
+		t.Fatalf("no box for more in %q", lines[0].TextValue())
+	}
+	// The float's text comes before the word in the source
+	start := len("Some text boxed")
+	rs := sw.SelectionRects(lines, bounds, bounds.Min, start, start+len("more"))
+	if len(rs) != 1 {
+		t.Fatalf("expected 1 rect got %v", rs)
+	}
+	want := boxRects(lines[0].Boxes(), sw.lineRects(lines, bounds, bounds.Min)[0])[more]
+	if rs[0].Min.X != want.Min.X || rs[0].Max.X != want.Max.X {
+		t.Errorf("selection %v not over the box of more %v", rs[0], want)
+	}
+}
@@ -0,0 +1,402 @@
+package wordwrap
+
+import (
+	"context"
+	"image"
+	"math"
+	"sort"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+// Span is a horizontal run of pixels, from Min up to but not including Max
+type Span struct {
+	Min, Max int
+}
+
+// Dx the width of the span
+func (s Span) Dx() int {
+	return s.Max - s.Min
+}
+
+// Shape is an area text can be folded into other than a rectangle
+type Shape interface {
+	// Bounds the rectangle containing the shape
+	Bounds() image.Rectangle
+	// Spans the horizontal spans, left to right, which are inside the shape on every row from y0 up to y1
+	Spans(y0, y1 int) []Span
+}
+
+// bandSpans intersects the spans of each row from y0 up to y1
+func bandSpans(y0, y1 int, row func(y int) []Span) []Span {
+	var spans []Span
+	for y := y0; y < y1; y++ {
+		if y == y0 {
+			spans = row(y)
+		} else {
+			spans = intersectSpans(spans, row(y))
+		}
+		if len(spans) == 0 {
+			return nil
+		}
+	}
+	return spans
+}
+
+// intersectSpans the spans covered by both a and b, both of which must be ordered left to right
+func intersectSpans(a, b []Span) []Span {
+	var spans []Span
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		s := Span{Min: max(a[i].Min, b[j].Min), Max: min(a[i].Max, b[j].Max)}
+		if s.Dx() > 0 {
+			spans = append(spans, s)
+		}
+		if a[i].Max < b[j].Max {
+			i++
+		} else {
+			j++
+		}
+	}
+	return spans
+}
+
+// Polygon is a Shape made from the points of a polygon, filled with the even-odd rule so holes can be cut out of it
+type Polygon []image.Point
+
+// Reports interface adherence
+var _ Shape = Polygon(nil)
+
+// Bounds the rectangle containing the points
+func (p Polygon) Bounds() image.Rectangle {
+	var r image.Rectangle
+	for i, pt := range p {
+		if i == 0 {
+			r = image.Rectangle{Min: pt, Max: pt}
+			continue
+		}
+		r.Min.X, r.Min.Y = min(r.Min.X, pt.X), min(r.Min.Y, pt.Y)
+		r.Max.X, r.Max.Y = max(r.Max.X, pt.X), max(r.Max.Y, pt.Y)
+	}
+	return r
+}
+
+// Spans the spans inside the polygon on every row from y0 up to y1
+func (p Polygon) Spans(y0, y1 int) []Span {
+	return bandSpans(y0, y1, p.row)
+}
+
+// row the spans inside the polygon at the middle of row y
+func (p Polygon) row(y int) []Span {
+	yc := float64(y) + 0.5
+	var xs []float64
+	for i := range p {
+		a, b := p[i], p[(i+1)%len(p)]
+		if (float64(a.Y) <= yc) == (float64(b.Y) <= yc) {
+			continue
+		}
+		t := (yc - float64(a.Y)) / float64(b.Y-a.Y)
+		xs = append(xs, float64(a.X)+t*float64(b.X-a.X))
+	}
+	sort.Float64s(xs)
+	var spans []Span
+	for i := 0; i+1 < len(xs); i += 2 {
+		s := Span{Min: int(math.Ceil(xs[i])), Max: int(math.Floor(xs[i+1]))}
+		if s.Dx() > 0 {
+			spans = append(spans, s)
+		}
+	}
+	return spans
+}
+
+// Ellipse is a Shape of the ellipse which fills Rect
+type Ellipse struct {
+	Rect image.Rectangle
+}
+
+// Reports interface adherence
+var _ Shape = Ellipse{}
+
+// Circle returns the Ellipse of the circle with the given center and radius
+func Circle(center image.Point, radius int) Ellipse {
+	return Ellipse{
+		Rect: image.Rect(center.X-radius, center.Y-radius, center.X+radius, center.Y+radius),
+	}
+}
+
+// Bounds the rectangle the ellipse fills
+func (e Ellipse) Bounds() image.Rectangle {
+	return e.Rect
+}
+
+// Spans the span inside the ellipse on every row from y0 up to y1
+func (e Ellipse) Spans(y0, y1 int) []Span {
+	return bandSpans(y0, y1, e.row)
+}
+
+// row the span inside the ellipse at the middle of row y
+func (e Ellipse) row(y int) []Span {
+	rx, ry := float64(e.Rect.Dx())/2, float64(e.Rect.Dy())/2
+	cx, cy := float64(e.Rect.Min.X)+rx, float64(e.Rect.Min.Y)+ry
+	dy := (float64(y) + 0.5 - cy) / ry
+	if dy*dy >= 1 {
+		return nil
+	}
+	half := rx * math.Sqrt(1-dy*dy)
+	s := Span{Min: int(math.Ceil(cx - half)), Max: int(math.Floor(cx + half))}
+	if s.Dx() <= 0 {
+		return nil
+	}
+	return []Span{s}
+}
+
+// AlphaMask is a Shape made from the pixels of an image which are more opaque than a threshold, such as a logo's
+// silhouette
+type AlphaMask struct {
+	bounds image.Rectangle
+	rows   [][]Span
+}
+
+// Reports interface adherence
+var _ Shape = (*AlphaMask)(nil)
+
+// NewAlphaMask creates a Shape from the pixels of mask with an alpha above threshold
+func NewAlphaMask(mask image.Image, threshold uint8) *AlphaMask {
+	b := mask.Bounds()
+	am := &AlphaMask{
+		bounds: b,
+		rows:   make([][]Span, b.Dy()),
+	}
+	limit := uint32(threshold) * 0x101
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		var spans []Span
+		in := false
+		for x := b.Min.X; x < b.Max.X; x++ {
+			_, _, _, a := mask.At(x, y).RGBA()
+			switch {
+			case a > limit && !in:
+				spans = append(spans, Span{Min: x})
+				in = true
+			case a <= limit && in:
+				spans[len(spans)-1].Max = x
+				in = false
+			}
+		}
+		if in {
+			spans[len(spans)-1].Max = b.Max.X
+		}
+		am.rows[y-b.Min.Y] = spans
+	}
+	return am
+}
+
+// Bounds the bounds of the mask
+func (am *AlphaMask) Bounds() image.Rectangle {
+	return am.bounds
+}
+
+// Spans the spans of opaque pixels on every row from y0 up to y1
+func (am *AlphaMask) Spans(y0, y1 int) []Span {
+	return bandSpans(y0, y1, am.row)
+}
+
+// row the spans of opaque pixels in row y
+func (am *AlphaMask) row(y int) []Span {
+	if y < am.bounds.Min.Y || y >= am.bounds.Max.Y {
+		return nil
+	}
+	return am.rows[y-am.bounds.Min.Y]
+}
+
+// FitterWidestSpan is a FitterOption which folds lines only into the widest span of each band of a shape, rather than
+// into every span left to right
+type FitterWidestSpan struct{}
+
+// Apply sets WidestSpan
+func (FitterWidestSpan) Apply(c *FitterConfig) {
+	c.WidestSpan = true
+}
+
+// Reports interface adherence
+var _ FitterOption = FitterWidestSpan{}
+
+// fitterShape is a FitterOption which folds into a shape
+type fitterShape struct {
+	Shape
+}
+
+// Apply sets the shape
+func (fs fitterShape) Apply(c *FitterConfig) {
+	c.shape = fs.Shape
+}
+
+// TextToShape folds the text into the shape, one line into each span of each band of the shape as wide as it needs.
+// Draw the lines with RenderLines at the shape's Bounds().Min.
+func (sw *SimpleWrapper) TextToShape(s Shape, ops ...FitterOption) ([]Line, image.Point, error) {
+	return sw.TextToShapeContext(context.Background(), s, ops...)
+}
+
+// TextToShapeContext is TextToShape which gives up with the context's error if it is done before the layout is
+// finished
+func (sw *SimpleWrapper) TextToShapeContext(ctx context.Context, s Shape, ops ...FitterOption) ([]Line, image.Point, error) {
+	if err := sw.checkPixelArea(s.Bounds().Dx(), s.Bounds().Dy()); err != nil {
+		return nil, image.Point{}, err
+	}
+	if err := sw.startPage(); err != nil {
+		return nil, image.Point{}, err
+	}
+	return sw.textToRect(ctx, s.Bounds(), append([]FitterOption{fitterShape{s}}, ops...)...)
+}
+
+// shapeBand a band of rows of the shape lines are folded into
+type shapeBand struct {
+	// top is the distance of the band from the top of the shape
+	top    int
+	height int
+	// spans the spans left to fold lines into
+	spans []Span
+	// used is set once a line has been folded into the band
+	used bool
+}
+
+// shapedLine is a line that may have been folded into a shape
+type shapedLine interface {
+	// shapeTop returns the line's distance from the top of the shape, ok is false if it wasn't folded into one
+	shapeTop() (top int, ok bool)
+}
+
+// Ensures that the interface is filled
+var _ shapedLine = (*SimpleLine)(nil)
+
+// shapeTop returns the line's distance from the top of the shape
+func (l *SimpleLine) shapeTop() (int, bool) {
+	return l.top, l.shaped
+}
+
+// contentWidth the width of the line not counting trailing whitespace
+func (l *SimpleLine) contentWidth() int {
+	var w, content fixed.Int26_6
+	for _, b := range l.boxes {
+		w += b.AdvanceRect()
+		if !b.Whitespace() {
+			content = w
+		}
+	}
+	return content.Ceil()
+}
+
+// lineHeight the expected height of a line in the current font
+func (sf *SimpleFolder) lineHeight() int {
+	if sf.lastFontDrawer != nil && sf.lastFontDrawer.Face != nil {
+		if h := sf.lastFontDrawer.Face.Metrics().Height.Ceil(); h > 0 {
+			return h
+		}
+	}
+	return 1
+}
+
+// bandSpans the spans of the shape for a band of rows, relative to the top of the shape, starting at or after x
+func (sf *SimpleFolder) bandSpans(top, height, x int) []Span {
+	y := sf.shape.Bounds().Min.Y + top
+	spans := intersectSpans(sf.shape.Spans(y, y+height), []Span{{Min: x, Max: math.MaxInt}})
+	if sf.widestSpan && len(spans) > 1 {
+		widest := spans[0]
+		for _, s := range spans[1:] {
+			if s.Dx() > widest.Dx() {
+				widest = s
+			}
+		}
+		spans = []Span{widest}
+	}
+	return spans
+}
+
+// nextBand moves on to the next band of the shape, returning false if there are no rows left. A band lines were folded
+// into is moved past, otherwise the next band starts one row further down.
+func (sf *SimpleFolder) nextBand() bool {
+	top := sf.y
+	if sf.band != nil && !sf.band.used {
+		top = sf.band.top + 1
+	}
+	height := sf.lineHeight()
+	if top+height > sf.shape.Bounds().Dy() {
+		return false
+	}
+	sf.band = &shapeBand{
+		top:    top,
+		height: height,
+		spans:  sf.bandSpans(top, height, math.MinInt),
+	}
+	return true
+}
+
+// rollback returns the boxes taken for a line to the boxer
+func (sf *SimpleFolder) rollback(lastFontDrawer *font.Drawer) {
+	sf.boxer.Unshift(sf.unbreak(sf.taken)...)
+	sf.taken = sf.taken[:0]
+	sf.lastFontDrawer = lastFontDrawer
+}
+
+// untake forgets the last box taken, for when it has been put back on the boxer
+func (sf *SimpleFolder) untake() {
+	if len(sf.taken) > 0 {
+		sf.taken = sf.taken[:len(sf.taken)-1]
+	}
+}
+
+// nextInShape folds the next line into the next span of the shape wide enough for it
+func (sf *SimpleFolder) nextInShape() (Line, error) {
+	bounds := sf.shape.Bounds()
+	for sf.boxer.HasNext() {
+		if sf.band == nil || len(sf.band.spans) == 0 {
+			if !sf.nextBand() {
+				return nil, nil
+			}
+			continue
+		}
+		band := sf.band
+		span := band.spans[0]
+		r := sf.NewLine()
+		r.width = span.Dx()
+		r.container = bounds.Dx()
+		r.xoffset = span.Min - bounds.Min.X
+		r.narrowed = true
+		r.top = band.top
+		r.shaped = true
+		lastFontDrawer := sf.lastFontDrawer
+		sf.taken = sf.taken[:0]
+		if ok, err := sf.fill(r, bounds.Dy()-band.top); err != nil {
+			return r, err
+		} else if !ok {
+			return nil, nil
+		}
+		if h := r.Size().Dy(); h > band.height {
+			// The line is taller than the band so it has to be folded again into the spans of a taller one
+			sf.rollback(lastFontDrawer)
+			if band.top+h > bounds.Dy() {
+				return nil, nil
+			}
+			band.height = h
+			band.spans = sf.bandSpans(band.top, h, span.Min)
+			continue
+		}
+		if r.contentWidth() > span.Dx() {
+			// Nothing fits in this span
+			sf.rollback(lastFontDrawer)
+			band.spans = band.spans[1:]
+			continue
+		}
+		if len(r.boxes) == 0 && len(r.floats) == 0 {
+			return nil, nil
+		}
+		band.spans = band.spans[1:]
+		band.used = true
+		r.trimEnd()
+		for _, option := range sf.lineOptions {
+			option(r)
+		}
+		sf.y = band.top + band.height
+		return r, nil
+	}
+	return nil, nil
+}
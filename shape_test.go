@@ -0,0 +1,142 @@
+package wordwrap
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestShapeSpans(t *testing.T) {
+	mask := image.NewAlpha(image.Rect(10, 10, 110, 60))
+	draw.Draw(mask, image.Rect(10, 10, 40, 60), image.Opaque, image.Point{}, draw.Src)
+	draw.Draw(mask, image.Rect(60, 10, 110, 60), image.Opaque, image.Point{}, draw.Src)
+	mask.SetAlpha(100, 30, color.Alpha{A: 10})
+
+	tests := []struct {
+		name   string
+		shape  Shape
+		y0, y1 int
+		want   []Span
+	}{
+		{name: "Circle middle", shape: Circle(image.Pt(100, 100), 50), y0: 99, y1: 101, want: []Span{{Min: 51, Max: 149}}},
+		{name: "Circle band", shape: Circle(image.Pt(100, 100), 50), y0: 60, y1: 100, want: []Span{{Min: 70, Max: 130}}},
+		{name: "Circle outside", shape: Circle(image.Pt(100, 100), 50), y0: 0, y1: 50},
+		{name: "Triangle", shape: Polygon{{0, 0}, {100, 100}, {0, 100}}, y0: 50, y1: 60, want: []Span{{Min: 0, Max: 50}}},
+		{
+			name:  "Polygon with a hole",
+			shape: Polygon{{0, 0}, {100, 0}, {100, 100}, {0, 100}, {0, 0}, {40, 40}, {60, 40}, {60, 60}, {40, 60}, {40, 40}},
+			y0:    45, y1: 50,
+			want: []Span{{Min: 0, Max: 40}, {Min: 60, Max: 100}},
+		},
+		{name: "Alpha mask", shape: NewAlphaMask(mask, 0), y0: 20, y1: 40, want: []Span{{Min: 10, Max: 40}, {Min: 60, Max: 110}}},
+		{name: "Alpha mask threshold", shape: NewAlphaMask(mask, 128), y0: 20, y1: 40, want: []Span{{Min: 10, Max: 40}, {Min: 60, Max: 100}, {Min: 101, Max: 110}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if s := cmp.Diff(tt.want, tt.shape.Spans(tt.y0, tt.y1)); s != "" {
+				t.Errorf("Spans() differ:\n%s", s)
+			}
+		})
+	}
+}
+
+func TestTextToShape(t *testing.T) {
+	fontFace := FontFace16DPI180ForTest(t)
+	text := strings.Repeat("The quick brown fox jumps over the lazy dog. ", 6)
+
+	t.Run("Circle", func(t *testing.T) {
+		circle := Circle(image.Pt(400, 400), 400)
+		sw := NewRichWrapper(fontFace, text)
+		ls, _, err := sw.TextToShape(circle)
+		if err != nil {
+			t.Fatalf("TextToShape failed: %v", err)
+		}
+		if sw.HasNext() {
+			t.Fatalf("expected all the text to fit")
+		}
+		if s := cmp.Diff(strings.Fields(text), strings.Fields(strings.Join(lineTexts(ls), ""))); s != "" {
+			t.Errorf("words differ:\n%s", s)
+		}
+		widths := map[int]bool{}
+		for li, lr := range sw.lineRects(ls, circle.Bounds(), circle.Bounds().Min) {
+			content := image.Rect(lr.Min.X, lr.Min.Y, lr.Min.X+ls[li].(*SimpleLine).contentWidth(), lr.Max.Y)
+			spans := circle.Spans(content.Min.Y, content.Max.Y)
+			if len(spans) != 1 || content.Min.X < spans[0].Min || content.Max.X > spans[0].Max {
+				t.Errorf("line %d %v is outside the circle %v", li, content, spans)
+			}
+			widths[ls[li].(*SimpleLine).width] = true
+		}
+		if len(widths) < 3 {
+			t.Errorf("expected the lines to follow the circle got widths %v", widths)
+		}
+	})
+
+	mask := image.NewAlpha(image.Rect(0, 0, 1000, 300))
+	draw.Draw(mask, image.Rect(0, 0, 450, 300), image.Opaque, image.Point{}, draw.Src)
+	draw.Draw(mask, image.Rect(500, 0, 1000, 150), image.Opaque, image.Point{}, draw.Src)
+	shape := NewAlphaMask(mask, 0)
+
+	t.Run("Several spans", func(t *testing.T) {
+		sw := NewRichWrapper(fontFace, text)
+		ls, _, err := sw.TextToShape(shape)
+		if err != nil {
+			t.Fatalf("TextToShape failed: %v", err)
+		}
+		rs := sw.lineRects(ls, shape.Bounds(), image.Point{})
+		if len(rs) < 2 || rs[0].Min.Y != rs[1].Min.Y || rs[1].Min.X < 500 {
+			t.Fatalf("expected the first band to have a line in each span got %v", rs)
+		}
+		for li, lr := range rs {
+			if lr.Min.Y >= 150 && lr.Min.X >= 450 {
+				t.Errorf("line %d %v is below the right span", li, lr)
+			}
+		}
+	})
+
+	t.Run("Widest span", func(t *testing.T) {
+		sw := NewRichWrapper(fontFace, text)
+		ls, _, err := sw.TextToShape(shape, FitterWidestSpan{})
+		if err != nil {
+			t.Fatalf("TextToShape failed: %v", err)
+		}
+		for li, lr := range sw.lineRects(ls, shape.Bounds(), image.Point{}) {
+			if lr.Max.Y <= 150 && lr.Min.X < 500 {
+				t.Errorf("line %d %v is not in the widest span", li, lr)
+			}
+		}
+	})
+
+	t.Run("Renders", func(t *testing.T) {
+		sw := NewRichWrapper(fontFace, text)
+		ls, _, err := sw.TextToShape(shape)
+		if err != nil {
+			t.Fatalf("TextToShape failed: %v", err)
+		}
+		i := image.NewRGBA(shape.Bounds())
+		if err := sw.RenderLines(i, ls, shape.Bounds().Min); err != nil {
+			t.Fatalf("RenderLines failed: %v", err)
+		}
+	})
+
+	t.Run("Centred in an oversized image", func(t *testing.T) {
+		circle := Circle(image.Pt(300, 300), 300)
+		sw := NewRichWrapper(fontFace, text, HorizontalCenterLines)
+		ls, _, err := sw.TextToShape(circle)
+		if err != nil {
+			t.Fatalf("TextToShape failed: %v", err)
+		}
+		bounds := image.Rect(0, 0, 1500, 1000)
+		want := sw.lineRects(ls, circle.Bounds(), circle.Bounds().Min)
+		if s := cmp.Diff(want, sw.lineRects(ls, bounds, circle.Bounds().Min)); s != "" {
+			t.Errorf("expected the lines centred in their spans of the circle as they are in its bounds:\n%s", s)
+		}
+		i := image.NewRGBA(bounds)
+		if err := sw.RenderLines(i, ls, circle.Bounds().Min); err != nil {
+			t.Fatalf("RenderLines failed: %v", err)
+		}
+	})
+}
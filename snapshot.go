@@ -0,0 +1,241 @@
+package wordwrap
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/color"
+	"reflect"
+	"sort"
+	"strings"
+
+	"golang.org/x/image/math/fixed"
+)
+
+// LayoutSnapshot is a stable, serializable record of a layout. Unlike []Line it holds no font drawers or images so it
+// can be stored, cached, and compared.
+type LayoutSnapshot struct {
+	PageSize     image.Point    `json:"pageSize"`
+	ContentStart image.Point    `json:"contentStart"`
+	Lines        []LineSnapshot `json:"lines"`
+}
+
+// LineSnapshot is the recorded state of a line
+type LineSnapshot struct {
+	// Rect is where the line is rendered
+	Rect image.Rectangle `json:"rect"`
+	// Baseline is the YValue of the line
+	Baseline int                `json:"baseline"`
+	Stats    *LinePositionStats `json:"stats,omitempty"`
+	Boxes    []BoxSnapshot      `json:"boxes"`
+}
+
+// BoxSnapshot is the recorded state of a box
+type BoxSnapshot struct {
+	// Type is the chain of box types from the outermost wrapper in, such as "IDBox/BackgroundBox/SimpleTextBox"
+	Type    string          `json:"type"`
+	Text    string          `json:"text"`
+	Rect    image.Rectangle `json:"rect"`
+	Advance fixed.Int26_6   `json:"advance"`
+	Ascent  fixed.Int26_6   `json:"ascent"`
+	Descent fixed.Int26_6   `json:"descent"`
+	Height  fixed.Int26_6   `json:"height"`
+	ID      string          `json:"id,omitempty"`
+	// Color is the text colour as #rrggbbaa if the text is drawn with a uniform colour
+	Color string `json:"color,omitempty"`
+	// Background is the background colour as #rrggbbaa if a uniform background is used
+	Background string        `json:"background,omitempty"`
+	Children   []BoxSnapshot `json:"children,omitempty"`
+}
+
+// Snapshot records lines as they would be rendered with RenderLines to an image with the given bounds at the given
+// starting point.
+func (sw *SimpleWrapper) Snapshot(ls []Line, bounds image.Rectangle, at image.Point) *LayoutSnapshot {
+	s := &LayoutSnapshot{
+		PageSize:     bounds.Size(),
+		ContentStart: at,
+		Lines:        []LineSnapshot{},
+	}
+	for li, lr := range sw.lineRects(ls, bounds, at) {
+		l := ls[li]
+		boxes := l.Boxes()
+		ln := LineSnapshot{
+			Rect:     lr,
+			Baseline: l.YValue(),
+			Boxes:    make([]BoxSnapshot, 0, len(boxes)),
+		}
+		if stats := linePositionStats(l); stats != nil {
+			st := *stats
+			ln.Stats = &st
+		}
+		for bi, br := range boxRects(boxes, lr) {
+			ln.Boxes = append(ln.Boxes, snapshotBox(boxes[bi], br))
+		}
+		s.Lines = append(s.Lines, ln)
+	}
+	return s
+}
+
+// Snapshot records the layout result
+func (lr *LayoutResult) Snapshot() *LayoutSnapshot {
+	sw := lr.wrapper
+	if sw == nil {
+		sw = &SimpleWrapper{}
+	}
+	// Recorded against the content area Render draws the lines into
+	s := sw.Snapshot(lr.Lines, image.Rectangle{Min: lr.ContentStart, Max: lr.ContentStart.Add(lr.ContentSize)}, lr.ContentStart)
+	s.PageSize = lr.PageSize
+	return s
+}
+
+// snapshotBox records a box drawn at r
+func snapshotBox(b Box, r image.Rectangle) BoxSnapshot {
+	m := b.MetricsRect()
+	bs := BoxSnapshot{
+		Text:    b.TextValue(),
+		Rect:    r,
+		Advance: b.AdvanceRect(),
+		Ascent:  m.Ascent,
+		Descent: m.Descent,
+		Height:  m.Height,
+	}
+	var types []string
+	for b != nil {
+		types = append(types, strings.TrimPrefix(reflect.TypeOf(b).String(), "*wordwrap."))
+		var next Box
+		switch bb := b.(type) {
+		case *IDBox:
+			if bs.ID == "" {
+				bs.ID = fmt.Sprint(bb.id)
+			}
+			next = bb.Box
+		case *BackgroundBox:
+			if bs.Background == "" {
+				bs.Background = uniformHex(bb.Background)
+			}
+			next = bb.Box
+		case *DecorationBox:
+			if bs.Background == "" {
+				bs.Background = uniformHex(bb.Background)
+			}
+			next = bb.Box
+		case *PageBreakBox:
+			next = bb.VisualBox
+		case *SimpleTextBox:
+			if bb.drawer != nil {
+				bs.Color = uniformHex(bb.drawer.Src)
+			}
+		case *RowBox:
+			x := fixed.I(r.Min.X)
+			for _, c := range bb.Boxes {
+				minX := x.Round()
+				x += c.AdvanceRect()
+				bs.Children = append(bs.Children, snapshotBox(c, image.Rect(minX, r.Min.Y, x.Round(), r.Max.Y)))
+			}
+		default:
+			next = innerBox(b)
+		}
+		b = next
+	}
+	bs.Type = strings.Join(types, "/")
+	return bs
+}
+
+// uniformHex formats a uniform image's colour as #rrggbbaa, or returns "" if the image isn't uniform
+func uniformHex(i interface{}) string {
+	u, ok := i.(*image.Uniform)
+	if !ok || u == nil {
+		return ""
+	}
+	c := color.RGBAModel.Convert(u.C).(color.RGBA)
+	return fmt.Sprintf("#%02x%02x%02x%02x", c.R, c.G, c.B, c.A)
+}
+
+// DiffSnapshots describes the differences between two snapshots, one per line of output, as a path into the JSON
+// structure followed by the two values. Returns nil if they are the same.
+func DiffSnapshots(a, b *LayoutSnapshot) ([]string, error) {
+	av, err := snapshotValue(a)
+	if err != nil {
+		return nil, err
+	}
+	bv, err := snapshotValue(b)
+	if err != nil {
+		return nil, err
+	}
+	var diffs []string
+	diffValues("", av, bv, &diffs)
+	return diffs, nil
+}
+
+// snapshotValue converts the snapshot into its generic JSON form
+func snapshotValue(s *LayoutSnapshot) (interface{}, error) {
+	b, err := json.Marshal(s)
+	if err != nil {
+		return nil, fmt.Errorf("encoding snapshot: %w", err)
+	}
+	var v interface{}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return nil, fmt.Errorf("decoding snapshot: %w", err)
+	}
+	return v, nil
+}
+
+// diffValues appends the differences between the generic JSON values a and b found at path
+func diffValues(path string, a, b interface{}, diffs *[]string) {
+	switch av := a.(type) {
+	case map[string]interface{}:
+		bv, ok := b.(map[string]interface{})
+		if !ok {
+			break
+		}
+		keys := map[string]struct{}{}
+		for k := range av {
+			keys[k] = struct{}{}
+		}
+		for k := range bv {
+			keys[k] = struct{}{}
+		}
+		sorted := make([]string, 0, len(keys))
+		for k := range keys {
+			sorted = append(sorted, k)
+		}
+		sort.Strings(sorted)
+		for _, k := range sorted {
+			p := k
+			if path != "" {
+				p = path + "." + k
+			}
+			diffValues(p, av[k], bv[k], diffs)
+		}
+		return
+	case []interface{}:
+		bv, ok := b.([]interface{})
+		if !ok {
+			break
+		}
+		for i := 0; i < len(av) || i < len(bv); i++ {
+			p := fmt.Sprintf("%s[%d]", path, i)
+			switch {
+			case i >= len(av):
+				*diffs = append(*diffs, fmt.Sprintf("%s: added %s", p, jsonString(bv[i])))
+			case i >= len(bv):
+				*diffs = append(*diffs, fmt.Sprintf("%s: removed %s", p, jsonString(av[i])))
+			default:
+				diffValues(p, av[i], bv[i], diffs)
+			}
+		}
+		return
+	}
+	if !reflect.DeepEqual(a, b) {
+		*diffs = append(*diffs, fmt.Sprintf("%s: %s != %s", path, jsonString(a), jsonString(b)))
+	}
+}
+
+// jsonString formats a generic JSON value for a diff
+func jsonString(v interface{}) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
@@ -0,0 +1,137 @@
+package wordwrap
+
+import (
+	"encoding/json"
+	"image/color"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestLayoutSnapshot(t *testing.T) {
+	fontFace := FontFace16DPI180ForTest(t)
+	red := color.RGBA{255, 0, 0, 255}
+	layout := func(t *testing.T, text string) *LayoutSnapshot {
+		sw := NewRichWrapper(fontFace, "Hello ", ID("who", Color(red, BgColor(color.White, text))))
+		lr, err := sw.TextToSpecs(Width(Fixed(400)), Padding(5, color.Black))
+		if err != nil {
+			t.Fatalf("TextToSpecs failed: %v", err)
+		}
+		return lr.Snapshot()
+	}
+
+	t.Run("Records lines and boxes", func(t *testing.T) {
+		s := layout(t, "World")
+		if s.PageSize.X != 400 {
+			t.Errorf("PageSize = %v", s.PageSize)
+		}
+		if len(s.Lines) != 1 {
+			t.Fatalf("expected 1 line got %d", len(s.Lines))
+		}
+		l := s.Lines[0]
+		if l.Rect.Min.X != 5 || l.Rect.Min.Y != 5 {
+			t.Errorf("line rect %v does not start at the content start", l.Rect)
+		}
+		if l.Stats == nil || l.Stats.LineNumber != 0 {
+			t.Errorf("missing line stats %+v", l.Stats)
+		}
+		var found *BoxSnapshot
+		for i := range l.Boxes {
+			if l.Boxes[i].Text == "World" {
+				found = &l.Boxes[i]
+			}
+		}
+		if found == nil {
+			t.Fatalf("no box for World in %+v", l.Boxes)
+		}
+		if found.ID != "who" {
+			t.Errorf("ID = %q", found.ID)
+		}
+		if found.Color != "#ff0000ff" {
+			t.Errorf("Color = %q", found.Color)
+		}
+		if found.Background != "#ffffffff" {
+			t.Errorf("Background = %q", found.Background)
+		}
+		if !strings.HasPrefix(found.Type, "IDBox/") || !strings.HasSuffix(found.Type, "/SimpleTextBox") {
+			t.Errorf("Type = %q", found.Type)
+		}
+		if found.Advance == 0 || found.Ascent == 0 {
+			t.Errorf("missing measurements %+v", found)
+		}
+	})
+
+	t.Run("JSON round trip", func(t *testing.T) {
+		s := layout(t, "World")
+		b, err := json.Marshal(s)
+		if err != nil {
+			t.Fatalf("Marshal failed: %v", err)
+		}
+		var got LayoutSnapshot
+		if err := json.Unmarshal(b, &got); err != nil {
+			t.Fatalf("Unmarshal failed: %v", err)
+		}
+		if d := cmp.Diff(s, &got); d != "" {
+			t.Errorf("round trip differs:\n%s", d)
+		}
+	})
+
+	t.Run("Lines are where Render draws them", func(t *testing.T) {
+		sw := NewRichWrapper(fontFace, RightLines, "Hello")
+		lr, err := sw.TextToSpecs(Width(Fixed(400)), Padding(5, color.Black))
+		if err != nil {
+			t.Fatalf("TextToSpecs failed: %v", err)
+		}
+		s := lr.Snapshot()
+		if s.PageSize != lr.PageSize {
+			t.Errorf("PageSize = %v, want %v", s.PageSize, lr.PageSize)
+		}
+		if len(s.Lines) != 1 {
+			t.Fatalf("expected 1 line got %d", len(s.Lines))
+		}
+		if r := s.Lines[0].Rect; r.Max.X != 395 || r.Min.Y != 5 {
+			t.Errorf("right aligned line rect %v should end at the right padding", r)
+		}
+	})
+
+	t.Run("Stats JSON", func(t *testing.T) {
+		b, err := json.Marshal(layout(t, "World").Lines[0].Stats)
+		if err != nil {
+			t.Fatalf("Marshal failed: %v", err)
+		}
+		var got map[string]interface{}
+		if err := json.Unmarshal(b, &got); err != nil {
+			t.Fatalf("Unmarshal failed: %v", err)
+		}
+		for _, k := range []string{"lineNumber", "pageBoxOffset", "wordOffset", "pageNumber", "runeOffset"} {
+			if _, ok := got[k]; !ok {
+				t.Errorf("missing %q in %s", k, b)
+			}
+		}
+	})
+
+	t.Run("Diff", func(t *testing.T) {
+		diffs, err := DiffSnapshots(layout(t, "World"), layout(t, "World"))
+		if err != nil {
+			t.Fatalf("DiffSnapshots failed: %v", err)
+		}
+		if len(diffs) != 0 {
+			t.Errorf("expected no differences got %v", diffs)
+		}
+		diffs, err = DiffSnapshots(layout(t, "World"), layout(t, "Earth"))
+		if err != nil {
+			t.Fatalf("DiffSnapshots failed: %v", err)
+		}
+		want := `lines[0].boxes[2].text: "World" != "Earth"`
+		found := false
+		for _, d := range diffs {
+			if d == want {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("expected %q in %v", want, diffs)
+		}
+	})
+}
@@ -0,0 +1,738 @@
+package wordwrap
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"strings"
+	"sync"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+// ColumnWidthKind how a table column's width is worked out
+type ColumnWidthKind int
+
+const (
+	// AutoWidth columns are as wide as their widest content, shrinking towards their narrowest when space is short
+	AutoWidth ColumnWidthKind = iota
+	// FixedWidth columns are always Value pixels wide
+	FixedWidth
+	// FractionWidth columns share the space left over by the other columns in proportion to Value
+	FractionWidth
+)
+
+// ColumnWidth is the width of a table column
+type ColumnWidth struct {
+	Kind  ColumnWidthKind
+	Value float64
+}
+
+// AutoColumn a column sized by its content
+func AutoColumn() ColumnWidth {
+	return ColumnWidth{Kind: AutoWidth}
+}
+
+// FixedColumn a column px pixels wide
+func FixedColumn(px int) ColumnWidth {
+	return ColumnWidth{Kind: FixedWidth, Value: float64(px)}
+}
+
+// FractionColumn a column taking a share of the space left, f being its weight against the other fraction columns
+func FractionColumn(f float64) ColumnWidth {
+	return ColumnWidth{Kind: FractionWidth, Value: f}
+}
+
+// TableGroup is a table of rows, returned by Table
+type TableGroup Group
+
+// TableRowGroup is a row of cells, returned by Row
+type TableRowGroup Group
+
+// TableCellGroup is a cell holding any rich content, returned by Cell
+type TableCellGroup Group
+
+// TableColumnsOption sets the widths of a table's columns, columns without one are AutoColumn
+type TableColumnsOption []ColumnWidth
+
+// HeaderRowsOption sets how many rows at the top of a table are repeated at the top of each page the table breaks on to
+type HeaderRowsOption int
+
+// ColSpanOption sets how many columns a cell spans
+type ColSpanOption int
+
+// RowSpanOption sets how many rows a cell spans
+type RowSpanOption int
+
+// Table returns a TableGroup. Decorators in effect, such as BoxPadding, Border and BgColor, apply to each cell.
+func Table(args ...interface{}) interface{} {
+	return TableGroup{Args: args}
+}
+
+// Row returns a TableRowGroup
+func Row(args ...interface{}) interface{} {
+	return TableRowGroup{Args: args}
+}
+
+// Cell returns a TableCellGroup. Like a Container the cell's content inherits the font and colour but the decorators
+// in effect wrap the cell rather than its content.
+func Cell(args ...interface{}) interface{} {
+	return TableCellGroup{Args: args}
+}
+
+// Columns returns a TableColumnsOption
+func Columns(widths ...ColumnWidth) TableColumnsOption {
+	return widths
+}
+
+// HeaderRows returns a HeaderRowsOption
+func HeaderRows(n int) HeaderRowsOption {
+	return HeaderRowsOption(n)
+}
+
+// ColSpan returns a ColSpanOption
+func ColSpan(n int) ColSpanOption {
+	return ColSpanOption(n)
+}
+
+// RowSpan returns a RowSpanOption
+func RowSpan(n int) RowSpanOption {
+	return RowSpanOption(n)
+}
+
+// unboundedCell is the width and height cells are folded into when they aren't bounded, small enough not to overflow
+// fixed point arithmetic
+const unboundedCell = 1 << 24
+
+// tableContent is the rows and cells of a table
+type tableContent struct {
+	columns    []ColumnWidth
+	headerRows int
+	rows       [][]*tableCell
+}
+
+// tableCell a cell of a table
+type tableCell struct {
+	contents   []*Content
+	decorators []func(Box) Box
+	colSpan    int
+	rowSpan    int
+}
+
+// runeLen the number of runes in the table
+func (t *tableContent) runeLen() int {
+	n := 0
+	for _, row := range t.rows {
+		for _, c := range row {
+			for _, cc := range c.contents {
+				n += cc.runeLen()
+			}
+		}
+	}
+	return n
+}
+
+// placedCell a cell placed in the grid of the table
+type placedCell struct {
+	*tableCell
+	row, col int
+	list     *BoxList
+	drawer   *font.Drawer
+	// insets the space taken on each side by the cell's decorators
+	insets image.Rectangle
+	// minWidth and maxWidth the narrowest the content can be folded to and its width unfolded, not counting insets
+	minWidth, maxWidth int
+	runes              int
+}
+
+// tableBlock is a table boxed ready to be laid out at any width
+type tableBlock struct {
+	cells      []*placedCell
+	columns    []ColumnWidth
+	rows, cols int
+	// groups are the first and last rows of each run of rows joined by row spans
+	groups [][2]int
+	// headerGroups is the number of groups which are headers
+	headerGroups int
+	// widest is the table at its widest, as it is measured before being fitted to a line
+	widest  *tableLayout
+	mu      sync.Mutex
+	layouts map[int]*tableLayout
+}
+
+// newTableBlock places the cells of the table in a grid and boxes their content like content nested in what parent is
+// boxing
+func newTableBlock(t *tableContent, parent *SimpleBoxer) (*tableBlock, error) {
+	tb := &tableBlock{
+		columns: t.columns,
+		rows:    len(t.rows),
+		layouts: map[int]*tableLayout{},
+	}
+	occupied := map[image.Point]bool{}
+	for r, row := range t.rows {
+		col := 0
+		for _, c := range row {
+			for occupied[image.Pt(col, r)] {
+				col++
+			}
+			// The spans are clamped on a copy so the table can be boxed again as it was given
+			cp := *c
+			c = &cp
+			c.colSpan, c.rowSpan = max(1, c.colSpan), max(1, min(c.rowSpan, len(t.rows)-r))
+			for y := r; y < r+c.rowSpan; y++ {
+				for x := col; x < col+c.colSpan; x++ {
+					occupied[image.Pt(x, y)] = true
+				}
+			}
+			pc, err := newPlacedCell(c, r, col, parent)
+			if err != nil {
+				return nil, err
+			}
+			tb.cells = append(tb.cells, pc)
+			col += c.colSpan
+			tb.cols = max(tb.cols, col)
+		}
+	}
+	tb.cols = max(tb.cols, len(t.columns))
+	start, end := 0, 0
+	for r := 0; r < tb.rows; r++ {
+		for _, c := range tb.cells {
+			if c.row == r {
+				end = max(end, r+c.rowSpan)
+			}
+		}
+		if end <= r+1 {
+			tb.groups = append(tb.groups, [2]int{start, r})
+			start = r + 1
+		}
+	}
+	for _, g := range tb.groups {
+		if g[0] < t.headerRows {
+			tb.headerGroups++
+		}
+	}
+	widest, err := tb.layout(-1)
+	if err != nil {
+		return nil, err
+	}
+	tb.widest = widest
+	return tb, nil
+}
+
+// newPlacedCell boxes the content of a cell with a child of parent, so it is boxed as the text around the table is,
+// and measures it
+func newPlacedCell(c *tableCell, row, col int, parent *SimpleBoxer) (*placedCell, error) {
+	boxer := parent.child(c.contents)
+	drawer := boxer.fontDrawer
+	list, err := NewBoxList(boxer)
+	if err != nil {
+		return nil, err
+	}
+	pc := &placedCell{
+		tableCell: c,
+		row:       row,
+		col:       col,
+		list:      list,
+		drawer:    drawer,
+		runes:     boxer.Pos(),
+	}
+	probe := &tableCellBox{}
+	pc.insets = decorationInsets(pc.decorate(probe), probe)
+	for _, b := range list.Boxes() {
+		if !b.Whitespace() {
+			pc.minWidth = max(pc.minWidth, b.AdvanceRect().Ceil())
+		}
+	}
+	ls, err := pc.fold(unboundedCell)
+	if err != nil {
+		return nil, err
+	}
+	for _, l := range ls {
+		pc.maxWidth = max(pc.maxWidth, l.Size().Dx())
+	}
+	return pc, nil
+}
+
+// decorate wraps the box in the cell's decorators
+func (pc *placedCell) decorate(b Box) Box {
+	for i := len(pc.decorators) - 1; i >= 0; i-- {
+		b = pc.decorators[i](b)
+	}
+	return b
+}
+
+// fold folds the cell's content into the width
+func (pc *placedCell) fold(width int) ([]Line, error) {
+	sw := &SimpleWrapper{
+		boxer:      pc.list.Boxer(),
+		fontDrawer: pc.drawer,
+	}
+	ls, _, err := sw.TextToRect(image.Rect(0, 0, max(width, 1), unboundedCell))
+	if err != nil {
+		return nil, fmt.Errorf("table cell %d,%d: %w", pc.col, pc.row, err)
+	}
+	return ls, nil
+}
+
+// decorationInsets the space the decorators wrapped around probe take on each side
+func decorationInsets(b Box, probe Box) image.Rectangle {
+	var insets image.Rectangle
+	for b != nil && b != probe {
+		if db, ok := b.(*DecorationBox); ok {
+			insets.Min.X += db.Margin.Min.X.Ceil() + db.Padding.Min.X.Ceil()
+			insets.Min.Y += db.Margin.Min.Y.Ceil() + db.Padding.Min.Y.Ceil()
+			insets.Max.X += db.Margin.Max.X.Ceil() + db.Padding.Max.X.Ceil()
+			insets.Max.Y += db.Margin.Max.Y.Ceil() + db.Padding.Max.Y.Ceil()
+			b = db.Box
+			continue
+		}
+		b = innerBox(b)
+	}
+	return insets
+}
+
+// tableLayout the table laid out at a width
+type tableLayout struct {
+	// colX the x position of the start of each column and the end of the last
+	colX []int
+	// rowY the y position of the start of each row and the end of the last
+	rowY []int
+	// boxes the decorated box of each cell
+	boxes []Box
+}
+
+// layout returns the table laid out to fit width, or at its widest when width is negative
+func (tb *tableBlock) layout(width int) (*tableLayout, error) {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+	if tl, ok := tb.layouts[width]; ok {
+		return tl, nil
+	}
+	if len(tb.layouts) >= 16 {
+		clear(tb.layouts)
+	}
+	tl := &tableLayout{}
+	widths := tb.columnWidths(width)
+	tl.colX = make([]int, tb.cols+1)
+	for i, w := range widths {
+		tl.colX[i+1] = tl.colX[i] + w
+	}
+	heights := make([]int, tb.rows)
+	contentHeights := make([]int, len(tb.cells))
+	lines := make([][]Line, len(tb.cells))
+	for ci, c := range tb.cells {
+		inner := tl.colX[c.col+c.colSpan] - tl.colX[c.col] - c.insets.Min.X - c.insets.Max.X
+		var err error
+		if lines[ci], err = c.fold(inner); err != nil {
+			return nil, err
+		}
+		for _, l := range lines[ci] {
+			contentHeights[ci] += l.Size().Dy()
+		}
+		if c.rowSpan == 1 {
+			heights[c.row] = max(heights[c.row], contentHeights[ci]+c.insets.Min.Y+c.insets.Max.Y)
+		}
+	}
+	for ci, c := range tb.cells {
+		if c.rowSpan == 1 {
+			continue
+		}
+		need := contentHeights[ci] + c.insets.Min.Y + c.insets.Max.Y
+		for r := c.row; r < c.row+c.rowSpan; r++ {
+			need -= heights[r]
+		}
+		if need > 0 {
+			heights[c.row+c.rowSpan-1] += need
+		}
+	}
+	tl.rowY = make([]int, tb.rows+1)
+	for i, h := range heights {
+		tl.rowY[i+1] = tl.rowY[i] + h
+	}
+	tl.boxes = make([]Box, len(tb.cells))
+	for ci, c := range tb.cells {
+		tl.boxes[ci] = c.decorate(&tableCellBox{
+			lines: lines[ci],
+			size:  image.Pt(tl.colX[c.col+c.colSpan]-tl.colX[c.col], contentHeights[ci]),
+		})
+	}
+	tb.layouts[width] = tl
+	return tl, nil
+}
+
+// columnWidths works out the width of each column to fit width, or at their widest when width is negative
+func (tb *tableBlock) columnWidths(width int) []int {
+	mins, maxs := make([]int, tb.cols), make([]int, tb.cols)
+	for _, span := range []bool{false, true} {
+		for _, c := range tb.cells {
+			if (c.colSpan > 1) != span {
+				continue
+			}
+			inset := c.insets.Min.X + c.insets.Max.X
+			spread(mins[c.col:c.col+c.colSpan], c.minWidth+inset)
+			spread(maxs[c.col:c.col+c.colSpan], c.maxWidth+inset)
+		}
+	}
+	kinds := make([]ColumnWidth, tb.cols)
+	copy(kinds, tb.columns)
+	widths := make([]int, tb.cols)
+	remaining := width
+	var autoMin, autoMax, fracMin int
+	var fractions float64
+	for i, k := range kinds {
+		switch k.Kind {
+		case FixedWidth:
+			widths[i] = int(k.Value)
+			remaining -= widths[i]
+		case FractionWidth:
+			fracMin += mins[i]
+			fractions += k.Value
+		default:
+			autoMin += mins[i]
+			autoMax += maxs[i]
+		}
+	}
+	if width < 0 {
+		for i, k := range kinds {
+			if k.Kind != FixedWidth {
+				widths[i] = maxs[i]
+			}
+		}
+		return widths
+	}
+	autoSpace := remaining - fracMin
+	for i, k := range kinds {
+		if k.Kind != AutoWidth {
+			continue
+		}
+		switch {
+		case autoSpace >= autoMax:
+			widths[i] = maxs[i]
+		case autoSpace > autoMin:
+			widths[i] = mins[i] + (maxs[i]-mins[i])*(autoSpace-autoMin)/(autoMax-autoMin)
+		default:
+			widths[i] = mins[i]
+		}
+		remaining -= widths[i]
+	}
+	if fractions <= 0 {
+		// No weight to go by, so the fraction columns share the space equally
+		for i := range kinds {
+			if kinds[i].Kind == FractionWidth {
+				kinds[i].Value = 1
+				fractions++
+			}
+		}
+	}
+	// Shared out by the running total so rounding doesn't lose any pixels
+	var weight float64
+	shared := 0
+	for i, k := range kinds {
+		if k.Kind == FractionWidth {
+			weight += k.Value
+			share := int(math.Round(float64(remaining) * weight / fractions))
+			widths[i] = max(mins[i], share-shared)
+			shared = share
+		}
+	}
+	return widths
+}
+
+// spread grows the columns evenly until together they are at least want wide
+func spread(cols []int, want int) {
+	have := 0
+	for _, w := range cols {
+		have += w
+	}
+	for i := range cols {
+		if have >= want {
+			return
+		}
+		add := (want - have + len(cols) - i - 1) / (len(cols) - i)
+		cols[i] += add
+		have += add
+	}
+}
+
+// blockBox is a box which takes a line of its own
+type blockBox interface {
+	Box
+	block()
+}
+
+// widthFitter is a box which lays itself out to the width of the line it is put on
+type widthFitter interface {
+	// fitWidth returns the box laid out to fit width
+	fitWidth(width int) (Box, error)
+}
+
+// TableRowBox is a box holding a run of a table's rows which are joined by row spans, so it can't be broken across a
+// page. A table is boxed into one for each such run, each taking a line of its own.
+type TableRowBox struct {
+	table       *tableBlock
+	group       int
+	first, last int
+	layout      *tableLayout
+	// repeated is set on header rows repeated at the top of a page
+	repeated bool
+}
+
+// Ensures that TableRowBox fits model
+var _ blockBox = (*TableRowBox)(nil)
+var _ widthFitter = (*TableRowBox)(nil)
+
+// tableRowBoxes boxes the table
+func (sb *SimpleBoxer) tableRowBoxes(t *tableContent) ([]Box, error) {
+	tb, err := newTableBlock(t, sb)
+	if err != nil {
+		return nil, err
+	}
+	boxes := make([]Box, 0, len(tb.groups))
+	for gi, g := range tb.groups {
+		boxes = append(boxes, &TableRowBox{
+			table: tb,
+			group: gi,
+			first: g[0],
+			last:  g[1],
+		})
+	}
+	return boxes, nil
+}
+
+// block marks the box as a block
+func (trb *TableRowBox) block() {}
+
+// fitWidth returns the rows laid out to the width
+func (trb *TableRowBox) fitWidth(width int) (Box, error) {
+	tl, err := trb.table.layout(width)
+	if err != nil {
+		return nil, err
+	}
+	if tl == trb.layout {
+		return trb, nil
+	}
+	cp := *trb
+	cp.layout = tl
+	return &cp, nil
+}
+
+// Header is true if the rows are the table's header rows
+func (trb *TableRowBox) Header() bool {
+	return trb.group < trb.table.headerGroups
+}
+
+// headers returns the table's header rows to repeat at the top of a page, if the rows are body rows after them and
+// fit in yspace along with the header rows at width
+func (trb *TableRowBox) headers(width, yspace int) ([]Box, error) {
+	if trb.Header() || trb.repeated || trb.table.headerGroups == 0 {
+		return nil, nil
+	}
+	tl, err := trb.table.layout(width)
+	if err != nil {
+		return nil, err
+	}
+	hg := trb.table.groups[trb.table.headerGroups-1]
+	if tl.rowY[hg[1]+1]-tl.rowY[0]+tl.rowY[trb.last+1]-tl.rowY[trb.first] > yspace {
+		// Repeating them would leave no room for the rows, so the page would never fill
+		return nil, nil
+	}
+	var boxes []Box
+	for gi := 0; gi < trb.table.headerGroups; gi++ {
+		g := trb.table.groups[gi]
+		boxes = append(boxes, &TableRowBox{
+			table:    trb.table,
+			group:    gi,
+			first:    g[0],
+			last:     g[1],
+			repeated: true,
+		})
+	}
+	return boxes, nil
+}
+
+// tableLayout the layout the box was fitted to, or the table at its widest if it hasn't been
+func (trb *TableRowBox) tableLayout() *tableLayout {
+	if trb.layout != nil {
+		return trb.layout
+	}
+	return trb.table.widest
+}
+
+// AdvanceRect the width of the table
+func (trb *TableRowBox) AdvanceRect() fixed.Int26_6 {
+	tl := trb.tableLayout()
+	return fixed.I(tl.colX[len(tl.colX)-1])
+}
+
+// MetricsRect the height of the rows, all of it above the baseline
+func (trb *TableRowBox) MetricsRect() font.Metrics {
+	tl := trb.tableLayout()
+	h := fixed.I(tl.rowY[trb.last+1] - tl.rowY[trb.first])
+	return font.Metrics{
+		Height: h,
+		Ascent: h,
+	}
+}
+
+// Whitespace never
+func (trb *TableRowBox) Whitespace() bool {
+	return false
+}
+
+// DrawBox draws each cell starting in the rows
+func (trb *TableRowBox) DrawBox(i Image, y fixed.Int26_6, dc *DrawConfig) {
+	tl := trb.tableLayout()
+	top := i.Bounds().Min.Add(image.Pt(0, (y - trb.MetricsRect().Ascent).Ceil()))
+	for ci, c := range trb.table.cells {
+		if c.row < trb.first || c.row > trb.last {
+			continue
+		}
+		r := image.Rect(tl.colX[c.col], tl.rowY[c.row], tl.colX[c.col+c.colSpan], tl.rowY[c.row+c.rowSpan])
+		r = r.Add(top).Sub(image.Pt(0, tl.rowY[trb.first]))
+		b := tl.boxes[ci]
+		b.DrawBox(i.SubImage(r).(Image), b.MetricsRect().Ascent, dc)
+	}
+}
+
+// FontDrawer none, cells have their own
+func (trb *TableRowBox) FontDrawer() *font.Drawer {
+	return nil
+}
+
+// Len the runes of the cells starting in the rows, none if they are repeated headers
+func (trb *TableRowBox) Len() int {
+	if trb.repeated {
+		return 0
+	}
+	n := 0
+	for _, c := range trb.table.cells {
+		if c.row >= trb.first && c.row <= trb.last {
+			n += c.runes
+		}
+	}
+	return n
+}
+
+// TextValue the text of the rows, with cells separated by tabs and rows by new lines
+func (trb *TableRowBox) TextValue() string {
+	tl := trb.tableLayout()
+	var sb strings.Builder
+	for r := trb.first; r <= trb.last; r++ {
+		if r > trb.first {
+			sb.WriteString("\n")
+		}
+		n := 0
+		for ci, c := range trb.table.cells {
+			if c.row != r {
+				continue
+			}
+			if n > 0 {
+				sb.WriteString("\t")
+			}
+			sb.WriteString(tl.boxes[ci].TextValue())
+			n++
+		}
+	}
+	return sb.String()
+}
+
+// MinSize none
+func (trb *TableRowBox) MinSize() (fixed.Int26_6, fixed.Int26_6) {
+	return 0, 0
+}
+
+// MaxSize none
+func (trb *TableRowBox) MaxSize() (fixed.Int26_6, fixed.Int26_6) {
+	return 0, 0
+}
+
+// tableCellBox is the folded content of a cell
+type tableCellBox struct {
+	lines []Line
+	size  image.Point
+}
+
+// Ensures that tableCellBox fits model
+var _ Box = (*tableCellBox)(nil)
+
+// AdvanceRect the width of the cell
+func (cb *tableCellBox) AdvanceRect() fixed.Int26_6 {
+	return fixed.I(cb.size.X)
+}
+
+// MetricsRect the height of the content, all of it above the baseline
+func (cb *tableCellBox) MetricsRect() font.Metrics {
+	return font.Metrics{
+		Height: fixed.I(cb.size.Y),
+		Ascent: fixed.I(cb.size.Y),
+	}
+}
+
+// Whitespace never
+func (cb *tableCellBox) Whitespace() bool {
+	return false
+}
+
+// DrawBox draws the lines from the top left of the image, an error drawing them failing the draw config
+func (cb *tableCellBox) DrawBox(i Image, y fixed.Int26_6, dc *DrawConfig) {
+	at := i.Bounds().Min
+	for _, l := range cb.lines {
+		s := l.Size()
+		r := s.Add(at)
+		if err := l.DrawLine(i.SubImage(r).(Image), drawConfigOption{dc}); err != nil {
+			dc.fail(fmt.Errorf("drawing table cell: %w", err))
+			return
+		}
+		at.Y += s.Dy()
+	}
+}
+
+// FontDrawer none
+func (cb *tableCellBox) FontDrawer() *font.Drawer {
+	return nil
+}
+
+// Len the runes of the content
+func (cb *tableCellBox) Len() int {
+	n := 0
+	for _, l := range cb.lines {
+		for _, b := range l.Boxes() {
+			n += b.Len()
+		}
+	}
+	return n
+}
+
+// TextValue the text of the content
+func (cb *tableCellBox) TextValue() string {
+	var sb strings.Builder
+	for _, l := range cb.lines {
+		sb.WriteString(l.TextValue())
+	}
+	return sb.String()
+}
+
+// MinSize none
+func (cb *tableCellBox) MinSize() (fixed.Int26_6, fixed.Int26_6) {
+	return 0, 0
+}
+
+// MaxSize none
+func (cb *tableCellBox) MaxSize() (fixed.Int26_6, fixed.Int26_6) {
+	return 0, 0
+}
+
+// drawConfigOption is a DrawOption which copies a DrawConfig, for drawing nested lines with the outer configuration
+type drawConfigOption struct {
+	dc *DrawConfig
+}
+
+// Apply copies the config, other than the selection whose offsets are for the outer lines
+func (o drawConfigOption) Apply(c *DrawConfig) {
+	if o.dc != nil {
+		*c = *o.dc
+		c.Selection = nil
+		c.err = nil
+	}
+}
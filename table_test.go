@@ -0,0 +1,271 @@
+package wordwrap
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"strconv"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+func tableRows(ls []Line) []*TableRowBox {
+	var rows []*TableRowBox
+	for _, l := range ls {
+		for _, b := range l.Boxes() {
+			if trb, ok := b.(*TableRowBox); ok {
+				rows = append(rows, trb)
+			}
+		}
+	}
+	return rows
+}
+
+func TestTable(t *testing.T) {
+	fontFace := FontFace16DPI180ForTest(t)
+
+	t.Run("Lines of their own", func(t *testing.T) {
+		sw := NewRichWrapper(fontFace, "Before", Table(Row(Cell("a"), Cell("b")), Row(Cell("c"), Cell("d"))), "After")
+		ls, _, err := sw.TextToRect(image.Rect(0, 0, 800, 1000))
+		if err != nil {
+			t.Fatalf("TextToRect failed: %v", err)
+		}
+		if s := cmp.Diff([]string{"Before", "a\tb", "c\td", "After"}, lineTexts(ls)); s != "" {
+			t.Errorf("lines differ:\n%s", s)
+		}
+	})
+
+	t.Run("Column widths", func(t *testing.T) {
+		sw := NewRichWrapper(fontFace, Table(
+			Columns(FixedColumn(100), AutoColumn(), FractionColumn(1), FractionColumn(3)),
+			Row(Cell("a"), Cell("auto"), Cell("b"), Cell("c")),
+		))
+		ls, _, err := sw.TextToRect(image.Rect(0, 0, 800, 1000))
+		if err != nil {
+			t.Fatalf("TextToRect failed: %v", err)
+		}
+		rows := tableRows(ls)
+		if len(rows) != 1 {
+			t.Fatalf("expected 1 row got %d", len(rows))
+		}
+		x := rows[0].layout.colX
+		auto := rows[0].table.cells[1].maxWidth
+		if x[1] != 100 || x[2]-x[1] != auto || x[4] != 800 {
+			t.Errorf("column positions %v with auto width %d", x, auto)
+		}
+		if rest := 800 - x[2]; x[3]-x[2] != rest/4 {
+			t.Errorf("expected the fractions to split %d 1:3 got %v", rest, x)
+		}
+	})
+
+	t.Run("Fraction columns without weight share equally", func(t *testing.T) {
+		sw := NewRichWrapper(fontFace, Table(
+			Columns(FixedColumn(100), FractionColumn(0), FractionColumn(0)),
+			Row(Cell("a"), Cell("b"), Cell("c")),
+		))
+		ls, _, err := sw.TextToRect(image.Rect(0, 0, 800, 1000))
+		if err != nil {
+			t.Fatalf("TextToRect failed: %v", err)
+		}
+		rows := tableRows(ls)
+		if len(rows) != 1 {
+			t.Fatalf("expected 1 row got %d", len(rows))
+		}
+		if x := rows[0].layout.colX; x[2]-x[1] != 350 || x[3] != 800 {
+			t.Errorf("expected the fractions to split 700 equally got %v", x)
+		}
+	})
+
+	t.Run("Auto columns shrink", func(t *testing.T) {
+		long := "The quick brown fox jumps over the lazy dog again and again"
+		sw := NewRichWrapper(fontFace, Table(Row(Cell(long), Cell(long))))
+		ls, _, err := sw.TextToRect(image.Rect(0, 0, 600, 1000))
+		if err != nil {
+			t.Fatalf("TextToRect failed: %v", err)
+		}
+		rows := tableRows(ls)
+		if len(rows) != 1 || rows[0].AdvanceRect().Ceil() > 600 {
+			t.Fatalf("expected the table to fit in 600 got %v", rows)
+		}
+		if h := rows[0].MetricsRect().Height.Ceil(); h <= ls[0].Size().Dy()/2 || len(rows[0].layout.boxes[0].(*tableCellBox).lines) < 2 {
+			t.Errorf("expected the cells to wrap")
+		}
+	})
+
+	t.Run("Spans and padding", func(t *testing.T) {
+		pad := fixed.R(5, 6, 5, 6)
+		sw := NewRichWrapper(fontFace, Table(BoxPadding(pad),
+			Row(Cell(RowSpan(2), "tall"), Cell("b"), Cell("c")),
+			Row(Cell(ColSpan(2), "wide")),
+			Row(Cell("e"), Cell("f"), Cell("g")),
+		))
+		ls, _, err := sw.TextToRect(image.Rect(0, 0, 800, 1000))
+		if err != nil {
+			t.Fatalf("TextToRect failed: %v", err)
+		}
+		rows := tableRows(ls)
+		if len(rows) != 2 || rows[0].first != 0 || rows[0].last != 1 {
+			t.Fatalf("expected the spanned rows to be kept together got %d boxes", len(rows))
+		}
+		var got []string
+		for _, c := range rows[0].table.cells {
+			got = append(got, strconv.Itoa(c.row)+","+strconv.Itoa(c.col)+" "+strconv.Itoa(c.colSpan)+"x"+strconv.Itoa(c.rowSpan))
+		}
+		if s := cmp.Diff([]string{"0,0 1x2", "0,1 1x1", "0,2 1x1", "1,1 2x1", "2,0 1x1", "2,1 1x1", "2,2 1x1"}, got); s != "" {
+			t.Errorf("cells differ:\n%s", s)
+		}
+		tl := rows[0].layout
+		cell := rows[0].table.cells[1]
+		if cell.insets != image.Rect(5, 6, 5, 6) {
+			t.Errorf("insets %v", cell.insets)
+		}
+		lineHeight := tl.boxes[1].(*DecorationBox).Box.(*tableCellBox).size.Y
+		if h := tl.rowY[1] - tl.rowY[0]; h != lineHeight+12 {
+			t.Errorf("row height %d want %d", h, lineHeight+12)
+		}
+	})
+
+	t.Run("Header rows repeat", func(t *testing.T) {
+		args := []interface{}{HeaderRows(1), Row(Cell("Name"), Cell("Value"))}
+		for i := 0; i < 30; i++ {
+			args = append(args, Row(Cell("row"+strconv.Itoa(i)), Cell(strconv.Itoa(i*i))))
+		}
+		sw := NewRichWrapper(fontFace, Table(args...))
+		var body []string
+		for page := 0; sw.HasNext(); page++ {
+			ls, _, err := sw.TextToRect(image.Rect(0, 0, 600, 400))
+			if err != nil {
+				t.Fatalf("TextToRect failed: %v", err)
+			}
+			texts := lineTexts(ls)
+			if len(texts) < 2 || texts[0] != "Name\tValue" {
+				t.Fatalf("page %d doesn't start with the header: %q", page, texts)
+			}
+			if rows := tableRows(ls); rows[0].repeated != (page > 0) || rows[0].Len() != map[bool]int{true: 0, false: 9}[page > 0] {
+				t.Errorf("page %d header repeated %v len %d", page, rows[0].repeated, rows[0].Len())
+			}
+			body = append(body, texts[1:]...)
+			if page > 10 {
+				t.Fatalf("too many pages")
+			}
+		}
+		if len(body) != 30 || body[29] != "row29\t841" {
+			t.Errorf("body rows %q", body)
+		}
+	})
+
+	t.Run("Header rows left off when the next row needs the page", func(t *testing.T) {
+		ls, _, err := NewRichWrapper(fontFace, Table(Row(Cell("a")))).TextToRect(image.Rect(0, 0, 600, 1000))
+		if err != nil {
+			t.Fatalf("TextToRect failed: %v", err)
+		}
+		lh := ls[0].YValue()
+		sw := NewRichWrapper(fontFace, Table(HeaderRows(1), Row(Cell("H")), Row(Cell("a")), Row(Cell("x\nx\nx\nx"))))
+		var pages [][]string
+		for sw.HasNext() {
+			ls, _, err := sw.TextToRect(image.Rect(0, 0, 600, lh*9/2))
+			if err != nil {
+				t.Fatalf("TextToRect failed: %v", err)
+			}
+			pages = append(pages, lineTexts(ls))
+			if len(pages) > 10 {
+				t.Fatalf("too many pages: %q", pages[:3])
+			}
+		}
+		if len(pages) != 2 || len(pages[0]) != 2 || pages[0][1] != "a" || len(pages[1]) != 1 || pages[1][0] == "H" {
+			t.Errorf("pages %q", pages)
+		}
+	})
+
+	t.Run("Draws backgrounds", func(t *testing.T) {
+		red := color.RGBA{R: 255, A: 255}
+		sw := NewRichWrapper(fontFace, Table(Columns(FixedColumn(200), FixedColumn(200)), Row(Cell("a"), BgColor(red, Cell("b")))))
+		ls, _, err := sw.TextToRect(image.Rect(0, 0, 800, 1000))
+		if err != nil {
+			t.Fatalf("TextToRect failed: %v", err)
+		}
+		i := image.NewRGBA(image.Rect(0, 0, 800, 200))
+		if err := sw.RenderLines(i, ls, image.Point{}); err != nil {
+			t.Fatalf("RenderLines failed: %v", err)
+		}
+		if c := i.RGBAAt(395, 2); c != red {
+			t.Errorf("expected the second cell's background got %v", c)
+		}
+		if c := i.RGBAAt(195, 2); c == red {
+			t.Errorf("expected no background in the first cell")
+		}
+	})
+	t.Run("Content outside cells", func(t *testing.T) {
+		sw := NewRichWrapper(fontFace, "Before", Table("x", Row("a", Cell("b"), "c"), "y"), "After")
+		ls, _, err := sw.TextToRect(image.Rect(0, 0, 800, 1000))
+		if err != nil {
+			t.Fatalf("TextToRect failed: %v", err)
+		}
+		if s := cmp.Diff([]string{"Before", "x", "a\tb\tc", "y", "After"}, lineTexts(ls)); s != "" {
+			t.Errorf("lines differ:\n%s", s)
+		}
+	})
+
+	t.Run("Spans of the content kept", func(t *testing.T) {
+		tc := &tableContent{
+			rows: [][]*tableCell{{{contents: []*Content{NewContent("a")}, rowSpan: 5}}},
+		}
+		if _, err := newTableBlock(tc, NewSimpleBoxer(nil, &font.Drawer{Face: fontFace})); err != nil {
+			t.Fatalf("newTableBlock failed: %v", err)
+		}
+		if c := tc.rows[0][0]; c.rowSpan != 5 || c.colSpan != 0 {
+			t.Errorf("cell spans changed to %d,%d", c.colSpan, c.rowSpan)
+		}
+	})
+
+	t.Run("Cells boxed with the wrapper's boxer options", func(t *testing.T) {
+		mc := NewMeasureCache(0)
+		sw := NewRichWrapper(fontFace, Tokenizer(StarTokenizer), mc, Table(Row(Cell("one*two"))))
+		ls, _, err := sw.TextToRect(image.Rect(0, 0, 800, 1000))
+		if err != nil {
+			t.Fatalf("TextToRect failed: %v", err)
+		}
+		trb, ok := ls[0].Boxes()[0].(*TableRowBox)
+		if !ok {
+			t.Fatalf("expected a table row got %T", ls[0].Boxes()[0])
+		}
+		var texts []string
+		for _, b := range trb.table.cells[0].list.Boxes() {
+			texts = append(texts, b.TextValue())
+		}
+		if len(texts) < 2 {
+			t.Errorf("expected the cell split by the tokenizer got %q", texts)
+		}
+		if mc.Len() == 0 {
+			t.Errorf("expected the cell measured with the measure cache")
+		}
+	})
+
+	t.Run("Drawing errors returned", func(t *testing.T) {
+		failure := errors.New("out of ink")
+		failing := func(b Box) Box { return &failingBox{Box: b, err: failure} }
+		sw := NewRichWrapper(fontFace, Table(Row(Cell("a"), Cell(NewContent("b", WithFontColor(color.Black), WithDecorators(failing))))))
+		ls, _, err := sw.TextToRect(image.Rect(0, 0, 800, 1000))
+		if err != nil {
+			t.Fatalf("TextToRect failed: %v", err)
+		}
+		i := image.NewRGBA(image.Rect(0, 0, 800, 200))
+		if err := sw.RenderLines(i, ls, image.Point{}); !errors.Is(err, failure) {
+			t.Errorf("RenderLines expected the cell's error got %v", err)
+		}
+	})
+}
+
+// failingBox is a box which fails to draw
+type failingBox struct {
+	Box
+	err error
+}
+
+// DrawBox fails the draw config
+func (fb *failingBox) DrawBox(i Image, y fixed.Int26_6, dc *DrawConfig) {
+	dc.fail(fb.err)
+}
@@ -0,0 +1,165 @@
+package wordwrap
+
+import (
+	"unicode"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+// WhiteSpace is how the white space of content is treated, as with the CSS white-space property
+type WhiteSpace int
+
+const (
+	// WhiteSpacePreWrap keeps white space as it is, breaking lines at line breaks and wrapping them where they are too
+	// wide. The default.
+	WhiteSpacePreWrap WhiteSpace = iota
+	// WhiteSpaceNormal collapses runs of white space, including line breaks, to a single space, which is dropped at the
+	// start and end of a line, and wraps lines
+	WhiteSpaceNormal
+	// WhiteSpacePre keeps white space as it is, breaking lines only at line breaks
+	WhiteSpacePre
+	// WhiteSpaceNoWrap collapses white space as WhiteSpaceNormal does, but never wraps
+	WhiteSpaceNoWrap
+)
+
+// collapses true if runs of white space are collapsed to a space
+func (ws WhiteSpace) collapses() bool {
+	return ws == WhiteSpaceNormal || ws == WhiteSpaceNoWrap
+}
+
+// wraps true if lines are wrapped where they are too wide
+func (ws WhiteSpace) wraps() bool {
+	return ws == WhiteSpacePreWrap || ws == WhiteSpaceNormal
+}
+
+// WhiteSpaceMode returns a Group with the WhiteSpace applied, or the WhiteSpace if no args
+func WhiteSpaceMode(ws WhiteSpace, args ...interface{}) interface{} {
+	if len(args) == 0 {
+		return ws
+	}
+	return Group{Args: append([]interface{}{ws}, args...)}
+}
+
+// WithWhiteSpace sets how the white space of the content is treated
+func WithWhiteSpace(ws WhiteSpace) ContentOption {
+	return func(c *Content) {
+		if c.style == nil {
+			c.style = &Style{}
+		}
+		c.style.WhiteSpace = ws
+	}
+}
+
+// whiteSpace the white space mode of the content
+func (c *Content) whiteSpace() WhiteSpace {
+	if c.style == nil {
+		return WhiteSpacePreWrap
+	}
+	return c.style.WhiteSpace
+}
+
+// collapseSpace consumes the run of white space, including line breaks, at the start of text, returning its length
+func collapseSpace(text []rune) int {
+	n := 0
+	for n < len(text) && unicode.IsSpace(text[n]) {
+		n++
+	}
+	return n
+}
+
+// whiteSpaceBox is a box of content with a white space mode other than WhiteSpacePreWrap
+type whiteSpaceBox struct {
+	Box
+	mode WhiteSpace
+	// runes is the length of the source the box stands for, more than its text where white space was collapsed
+	runes int
+	// trimmed is set for collapsed white space dropped at the start or end of a line, which takes no space
+	trimmed bool
+}
+
+// collapsible true if the box is collapsed white space
+func (wsb *whiteSpaceBox) collapsible() bool {
+	return wsb.mode.collapses() && wsb.Box.Whitespace()
+}
+
+// trim a copy of the box that takes no space
+func (wsb *whiteSpaceBox) trim() *whiteSpaceBox {
+	cp := *wsb
+	cp.trimmed = true
+	return &cp
+}
+
+// AdvanceRect width of the box, 0 if it was trimmed
+func (wsb *whiteSpaceBox) AdvanceRect() fixed.Int26_6 {
+	if wsb.trimmed {
+		return 0
+	}
+	return wsb.Box.AdvanceRect()
+}
+
+// DrawBox draws the box unless it was trimmed
+func (wsb *whiteSpaceBox) DrawBox(i Image, y fixed.Int26_6, dc *DrawConfig) {
+	if wsb.trimmed {
+		return
+	}
+	wsb.Box.DrawBox(i, y, dc)
+}
+
+// Len the length of the source the box stands for
+func (wsb *whiteSpaceBox) Len() int {
+	return wsb.runes
+}
+
+// TextValue the text of the box, nothing if it was trimmed
+func (wsb *whiteSpaceBox) TextValue() string {
+	if wsb.trimmed {
+		return ""
+	}
+	return wsb.Box.TextValue()
+}
+
+// FontDrawer font used
+func (wsb *whiteSpaceBox) FontDrawer() *font.Drawer {
+	return wsb.Box.FontDrawer()
+}
+
+// noWrap true if the line can't be wrapped before the box
+func noWrap(b Box) bool {
+	wsb, ok := b.(*whiteSpaceBox)
+	return ok && !wsb.mode.wraps()
+}
+
+// collapseSpaceBox folds collapsed white space, dropping it at the start of a line or after other white space, and
+// breaking the line at it where the line is full. Returns the box to fold if it wasn't placed, which is no longer
+// trimmed if it was trimmed on a line that was rolled back, if it was placed and if the line is done.
+func (sf *SimpleFolder) collapseSpaceBox(b Box, r *SimpleLine) (Box, bool, bool) {
+	wsb, ok := b.(*whiteSpaceBox)
+	if !ok || !wsb.collapsible() {
+		return b, false, false
+	}
+	w := r.size.Max.X - r.size.Min.X
+	if last, ok := r.lastBox().(*whiteSpaceBox); w == 0 || ok && last.collapsible() {
+		r.Push(wsb.trim(), 0)
+		return b, true, false
+	}
+	cp := *wsb
+	cp.trimmed = false
+	if cp.mode.wraps() && (w+cp.AdvanceRect()).Ceil() > r.width {
+		r.Push(cp.trim(), 0)
+		return b, true, true
+	}
+	return &cp, false, false
+}
+
+// trimEnd drops the collapsed white space at the end of the line
+func (l *SimpleLine) trimEnd() {
+	for i := len(l.boxes) - 1; i >= 0; i-- {
+		wsb, ok := l.boxes[i].(*whiteSpaceBox)
+		if !ok || !wsb.collapsible() {
+			return
+		}
+		l.size.Max.X -= wsb.AdvanceRect()
+		l.boxes[i] = wsb.trim()
+	}
+}
@@ -0,0 +1,62 @@
+package wordwrap
+
+import (
+	"image"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestWhiteSpace(t *testing.T) {
+	fontFace := FontFace16DPI180ForTest(t)
+	text := "  The  quick\n brown   fox jumps over the lazy dog  "
+	tests := []struct {
+		name  string
+		mode  WhiteSpace
+		width int
+		want  []string
+	}{
+		{name: "Normal collapses and wraps", mode: WhiteSpaceNormal, width: 600, want: []string{"The quick brown fox jumps over", "the lazy dog"}},
+		{name: "No wrap collapses", mode: WhiteSpaceNoWrap, width: 600, want: []string{"The quick brown fox jumps over the lazy dog"}},
+		{name: "Pre keeps white space", mode: WhiteSpacePre, width: 600, want: []string{"  The  quick\n", " brown   fox jumps over the lazy dog  "}},
+		{name: "Pre wrap keeps white space and wraps", mode: WhiteSpacePreWrap, width: 600, want: []string{"  The  quick\n", " brown   fox jumps over the lazy ", "dog  "}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sw := NewRichWrapper(fontFace, WhiteSpaceMode(tt.mode, text))
+			ls, _, err := sw.TextToRect(image.Rect(0, 0, tt.width, 1000))
+			if err != nil {
+				t.Fatalf("TextToRect failed: %v", err)
+			}
+			if s := cmp.Diff(tt.want, lineTexts(ls)); s != "" {
+				t.Errorf("lines differ:\n%s", s)
+			}
+			if sw.HasNext() {
+				t.Errorf("expected all the text to be used")
+			}
+			n := 0
+			for _, l := range ls {
+				for _, b := range l.Boxes() {
+					n += b.Len()
+				}
+			}
+			if n != len([]rune(text)) {
+				t.Errorf("expected the boxes to cover %d runes got %d", len([]rune(text)), n)
+			}
+		})
+	}
+
+	t.Run("Trimmed space takes no width", func(t *testing.T) {
+		ls, _, err := NewRichWrapper(fontFace, WhiteSpaceMode(WhiteSpaceNormal, "   fox   ")).TextToRect(image.Rect(0, 0, 600, 1000))
+		if err != nil {
+			t.Fatalf("TextToRect failed: %v", err)
+		}
+		plain, _, err := NewRichWrapper(fontFace, "fox").TextToRect(image.Rect(0, 0, 600, 1000))
+		if err != nil {
+			t.Fatalf("TextToRect failed: %v", err)
+		}
+		if ls[0].Size().Dx() != plain[0].Size().Dx() {
+			t.Errorf("expected width %d got %d", plain[0].Size().Dx(), ls[0].Size().Dx())
+		}
+	})
+}
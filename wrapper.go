@@ -1,22 +1,51 @@
 package wordwrap
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"image"
 
 	"golang.org/x/image/font"
 )
 
+// ErrNoProgress is returned when a page can't fit any of the remaining content
+var ErrNoProgress = errors.New("no content fits on the page")
+
 // SimpleWrapper provides basic text wrapping functionality.
 type SimpleWrapper struct {
+	wrapperConfig
+	boxer       Boxer
+	fontDrawer  *font.Drawer
+	currentPage int
+	boxCount    int
+	// pageCount is the number of pages laid out for the page limit, restarted by each TextToSpecs call
+	pageCount int
+	// indent is the indent of the list item the last page ended in, for the next page to carry on with
+	indent int
+	// footnotes are the footnotes, or the rest of them, that didn't fit on the last page
+	footnotes []*pendingNote
+	// fields are what fields resolve to, carrying the section on from page to page
+	fields fieldState
+	// sourceLines is the number of source lines ended so far, for numbering lines in the gutter
+	sourceLines int
+	// continued is set if the last page ended part way through a wrapped source line
+	continued bool
+}
+
+// wrapperConfig is the part of the wrapper set by its options, kept together so options can be undone by restoring it
+type wrapperConfig struct {
 	folderOptions           []FolderOption
 	boxerOptions            []BoxerOption
-	boxer                   Boxer
-	fontDrawer              *font.Drawer
-	currentPage             int
-	boxCount                int
 	horizontalBlockPosition HorizontalBlockPosition
 	verticalBlockPosition   VerticalBlockPosition
+	limits                  Limits
+	// gutter is the line number gutter, if any
+	gutter *gutter
+	// baselineGrid is the increment baselines are snapped to, 0 if they aren't
+	baselineGrid BaselineGrid
+	// verticalJustify is how lines are spread to fill the height with JustifyBlock
+	verticalJustify VerticalJustify
 }
 
 // horizontalPosition sets the horizontalBlockPosition
@@ -62,8 +91,8 @@ func NewRichWrapper(args ...interface{}) *SimpleWrapper {
 	contents, fontDrawer, wrapperOptions, boxerOptions, boxer, tokenizer := ProcessRichArgs(args...)
 
 	sw := &SimpleWrapper{
-		fontDrawer:   fontDrawer,
-		boxerOptions: boxerOptions,
+		fontDrawer:    fontDrawer,
+		wrapperConfig: wrapperConfig{boxerOptions: boxerOptions},
 	}
 	sw.ApplyOptions(wrapperOptions...)
 	if boxer == nil {
@@ -84,27 +113,79 @@ type HorizontalLinePositioner interface {
 }
 
 // RenderLines draws the boxes for the given lines. on the image, starting at the specified point ignoring the original
-// boundaries but maintaining the wrapping. Also applies alignment options.
+// boundaries but maintaining the wrapping. Also applies alignment options, right and centred lines being aligned in
+// the width from the point to the right of the image.
 func (sw *SimpleWrapper) RenderLines(i Image, ls []Line, at image.Point, options ...DrawOption) error {
-	bounds := i.Bounds()
+	for li, r := range sw.lineRects(ls, i.Bounds(), at) {
+		rgba := i.SubImage(r).(Image)
+		if err := ls[li].DrawLine(rgba, options...); err != nil {
+			return fmt.Errorf("drawing text: %w", err)
+		}
+	}
+	dc := NewDrawConfig(options...)
+	sw.drawFloats(i, ls, at, dc)
+	sw.drawMarkers(i, ls, at, dc)
+	sw.drawGutter(i, ls, at, dc)
+	if dc.err != nil {
+		return fmt.Errorf("drawing floats: %w", dc.err)
+	}
+	return nil
+}
+
+// lineRects calculates where RenderLines would place each line given the destination bounds and starting point.
+func (sw *SimpleWrapper) lineRects(ls []Line, bounds image.Rectangle, at image.Point) []image.Rectangle {
 	offset := sw.calculateAlignmentOffset(ls, bounds)
-	for _, l := range ls {
+	rs := make([]image.Rectangle, 0, len(ls))
+	available := sw.renderWidth(bounds, at)
+	// The lines are to the right of the gutter
+	at.X += sw.gutterWidth()
+	top := at.Y
+	spread := sw.verticalSpread(ls, bounds, at)
+	// Footnotes are at the bottom
+	notesStart, notesHeight := footnotesHeight(ls)
+	for li, l := range ls {
 		s := l.Size()
-		if l, ok := l.(HorizontalLinePositioner); ok {
-			switch l.getHorizontalLinePosition() {
+		if spread != nil {
+			at.Y += spread[li]
+		}
+		if li == notesStart && notesHeight > 0 {
+			at.Y = max(at.Y, bounds.Max.Y-offset.Y-notesHeight)
+		}
+		if sl, ok := l.(shapedLine); ok {
+			if y, shaped := sl.shapeTop(); shaped {
+				at.Y = top + y
+			}
+		}
+		width := available
+		xoffset := 0
+		if fl, ok := l.(floatedLine); ok {
+			if x, w, narrowed := fl.floatOffset(); narrowed {
+				// Aligned within the span it was folded into, beside its floats or in its shape, whatever it is
+				// rendered in
+				xoffset, width = x, w
+			}
+		}
+		if hp, ok := l.(HorizontalLinePositioner); ok {
+			switch hp.getHorizontalLinePosition() {
 			case HorizontalCenterLines:
-				s = s.Add(image.Pt((bounds.Max.X-(s.Max.X-s.Min.X))/2, 0))
+				s = s.Add(image.Pt((width-opticalWidth(l, s.Dx()))/2, 0))
 			case RightLines:
-				s = s.Add(image.Pt(bounds.Max.X-(s.Max.X-s.Min.X), 0))
+				s = s.Add(image.Pt(width-opticalWidth(l, s.Dx()), 0))
 			}
 		}
-		rgba := i.SubImage(s.Add(offset).Add(at)).(Image)
-		if err := l.DrawLine(rgba, options...); err != nil {
-			return fmt.Errorf("drawing text: %s", err)
-		}
+		s = s.Add(image.Pt(xoffset, 0))
+		// What hangs into the right margin isn't fitted in the width but is drawn
+		s.Max.X += rightHang(l)
+		rs = append(rs, s.Add(offset).Add(at))
 		at.Y += s.Dy()
 	}
-	return nil
+	return rs
+}
+
+// renderWidth the width lines rendered at at within bounds are aligned in, from at, right of the gutter, to the right of
+// the bounds
+func (sw *SimpleWrapper) renderWidth(bounds image.Rectangle, at image.Point) int {
+	return bounds.Max.X - at.X - sw.gutterWidth()
 }
 
 // calculateAlignmentOffset calculates the appropriate alignment offset for the block alignments VerticalBlockPosition
@@ -121,11 +202,13 @@ func (sw *SimpleWrapper) calculateAlignmentOffset(ls []Line, bounds image.Rectan
 			}
 		}
 	}
+	// The lines are aligned in the width beside the gutter
+	width := bounds.Dx() - sw.gutterWidth()
 	switch sw.horizontalBlockPosition {
 	case HorizontalCenterBlock:
-		offset.X = (bounds.Dx() - actualSize.X) / 2
+		offset.X = (width - actualSize.X) / 2
 	case RightBlock:
-		offset.X = bounds.Dx() - actualSize.X
+		offset.X = width - actualSize.X
 	}
 	switch sw.verticalBlockPosition {
 	case VerticalCenterBlock:
@@ -157,6 +240,10 @@ func (sw *SimpleWrapper) addBoxConfig(bo BoxerOption) {
 
 type FitterConfig struct {
 	IgnoreY bool
+	// WidestSpan folds only into the widest span of each band of a shape
+	WidestSpan bool
+	// shape is the shape to fold into instead of the rectangle
+	shape Shape
 }
 
 type FitterOption interface {
@@ -173,22 +260,71 @@ var _ FitterOption = (*FitterIgnoreY)(nil)
 
 // TextToRect calculates and returns the position of each box and the image.Point it would end.
 func (sw *SimpleWrapper) TextToRect(r image.Rectangle, ops ...FitterOption) ([]Line, image.Point, error) {
+	return sw.TextToRectContext(context.Background(), r, ops...)
+}
+
+// TextToRectContext is TextToRect which gives up with the context's error if it is done before the layout is finished.
+// The context is checked between lines.
+func (sw *SimpleWrapper) TextToRectContext(ctx context.Context, r image.Rectangle, ops ...FitterOption) ([]Line, image.Point, error) {
+	if err := sw.checkPixelArea(r.Dx(), r.Dy()); err != nil {
+		return nil, image.Point{}, err
+	}
+	if err := sw.startPage(); err != nil {
+		return nil, image.Point{}, err
+	}
+	return sw.textToRect(ctx, r, ops...)
+}
+
+// textToRect lays out a page without counting it against the page limit
+func (sw *SimpleWrapper) textToRect(ctx context.Context, r image.Rectangle, ops ...FitterOption) ([]Line, image.Point, error) {
 	config := FitterConfig{}
 	for _, op := range ops {
 		op.Apply(&config)
 	}
 	ls := make([]Line, 0)
+	// The text is folded beside the gutter
+	r.Min.X = min(r.Min.X+sw.gutterWidth(), r.Max.X-1)
 	p := r.Min
-	sf := NewSimpleFolder(sw.boxer, r, sw.fontDrawer, sw.folderOptions...)
+	sf := NewSimpleFolder(limitBoxes(sw.boxer, sw.limits.MaxBoxes), r, sw.fontDrawer, sw.folderOptions...)
+	sf.shape = config.shape
+	sf.widestSpan = config.WidestSpan
+	sf.indent = sw.indent
+	sw.fields.page = sw.currentPage + 1
+	sf.fields = &sw.fields
+	notes, err := sw.newFootnoteArea(r.Dx())
+	if err != nil {
+		return nil, image.Point{}, err
+	}
 	pageBoxCount := 0
 	for (p.Y-r.Min.Y) <= r.Dy() || config.IgnoreY {
-		l, err := sf.Next(r.Dy() - (p.Y - r.Min.Y))
+		if err := ctx.Err(); err != nil {
+			return nil, image.Point{}, fmt.Errorf("laying out line %d: %w", len(ls), err)
+		}
+		runeOffset := sf.boxer.Pos()
+		yspace := r.Dy() - (p.Y - r.Min.Y)
+		if config.IgnoreY {
+			yspace = unboundedCell
+		} else {
+			// Space is kept for the footnotes so far
+			yspace -= min(notes.height(len(notes.notes)), r.Dy())
+		}
+		l, err := sf.Next(yspace)
 		if err != nil {
 			return nil, image.Point{}, fmt.Errorf("boxing text at line %d: %w", len(ls), err)
 		}
 		if l == nil {
 			break
 		}
+		if gl, ok := l.(griddedLine); ok && sw.baselineGrid > 0 && sf.shape == nil {
+			// Moving the line onto the grid makes it taller
+			h := l.Size().Dy()
+			gl.snapBaseline(p.Y-r.Min.Y, sw.baselineGrid)
+			sf.y += l.Size().Dy() - h
+			if h := l.Size().Dy(); !config.IgnoreY && sf.yOverflow == StrictBorders && len(ls) > 0 && h > yspace {
+				sf.unfold(l)
+				break
+			}
+		}
 		s := l.Size()
 		stop := false
 		switch sf.yOverflow {
@@ -196,28 +332,65 @@ func (sw *SimpleWrapper) TextToRect(r image.Rectangle, ops ...FitterOption) ([]L
 			// Handled elsewhere
 		case DescentOverflow:
 			if (p.Y - r.Min.Y + l.YValue()) > r.Dy() {
-				sf.boxer.Push(l.Boxes()...)
+				sf.unfold(l)
 				stop = true
 			}
 		case FullOverflowDuplicate:
 			if (p.Y - r.Min.Y + s.Dy()) > r.Dy() {
-				sf.boxer.Push(l.Boxes()...)
+				sf.unfold(l)
 			}
 		}
 		if stop {
 			break
 		}
-		l.setStats(len(ls), sw.currentPage, sw.boxCount, pageBoxCount)
+		full := false
+		if fns := footnotesIn(l, runeOffset); len(fns) > 0 {
+			from := len(notes.notes)
+			if err := notes.add(fns); err != nil {
+				return nil, image.Point{}, err
+			}
+			if free := r.Dy() - (p.Y - r.Min.Y) - s.Dy(); !config.IgnoreY && notes.height(len(notes.notes)) > free {
+				if notes.height(from) > free && len(ls) > 0 {
+					// Not even the first line of its footnotes fits below the line so it starts the next page
+					notes.drop(from)
+					sf.unfold(l)
+					break
+				}
+				// The rest of the page is taken by the footnotes, which carry on to the next
+				full = true
+			}
+		}
+		if err := checkLimit("lines", sw.limits.MaxLines, len(ls)+1); err != nil {
+			return nil, image.Point{}, err
+		}
+		l.setStats(len(ls), sw.currentPage, sw.boxCount, pageBoxCount, runeOffset)
 		boxCount := len(l.Boxes())
 		sw.boxCount += boxCount
 		pageBoxCount += boxCount
 		ls = append(ls, l)
-		p.Y += s.Dy()
+		if sw.gutter != nil {
+			if err := sw.numberLine(l); err != nil {
+				return nil, image.Point{}, err
+			}
+		}
+		if sf.shape != nil {
+			// Lines in a shape share bands
+			p.Y = r.Min.Y + sf.y
+		} else {
+			p.Y += s.Dy()
+		}
+		if full {
+			break
+		}
 	}
 	if sf.pageBreakBox != nil && sf.boxer.HasNext() {
 		if len(ls) > 0 {
 			line := ls[len(ls)-1]
-			if n, err := line.PopSpaceFor(sf, r, NewPageBreak(sf.pageBreakBox)); err != nil {
+			pbb, err := sw.fields.resolveFields(sf.pageBreakBox)
+			if err != nil {
+				return nil, image.Point{}, err
+			}
+			if n, err := line.PopSpaceFor(sf, r, NewPageBreak(pbb)); err != nil {
 				return nil, image.Point{}, err
 			} else {
 				sw.boxCount -= n
@@ -229,12 +402,70 @@ func (sw *SimpleWrapper) TextToRect(r image.Rectangle, ops ...FitterOption) ([]L
 			return nil, image.Point{}, fmt.Errorf("page break too tall or rect too small")
 		}
 	}
+	space := r.Dy() - (p.Y - r.Min.Y)
+	if config.IgnoreY {
+		space = unboundedCell
+	}
+	nls, offsets, carried, err := notes.place(space, len(ls) == 0)
+	if err != nil {
+		return nil, image.Point{}, err
+	}
+	for li, l := range nls {
+		l.setStats(len(ls), sw.currentPage, sw.boxCount, pageBoxCount, offsets[li])
+		boxCount := len(l.Boxes())
+		sw.boxCount += boxCount
+		pageBoxCount += boxCount
+		ls = append(ls, l)
+		p.Y += l.Size().Dy()
+	}
+	sw.footnotes = carried
+	// Floats may hang below the last line, but not past the page
+	bottom := r.Min.Y + sf.floatBottom
+	if !config.IgnoreY {
+		bottom = min(bottom, r.Max.Y)
+	}
+	if bottom > p.Y {
+		p.Y = bottom
+	}
+	if err := sw.boxerErr(); err != nil {
+		return nil, image.Point{}, err
+	}
 	sw.currentPage++
 	sw.fontDrawer = sf.lastFontDrawer
+	sw.indent = sf.indent
 	return ls, p, nil
 }
 
-// HasNext are there any unprocessed bytes in the boxer
+// StreamPages lays out the remaining text a page of size r at a time, calling fn with each page's lines as soon as it
+// is laid out, so only one page needs to be held at once. Stops at the first error, including one returned by fn.
+func (sw *SimpleWrapper) StreamPages(r image.Rectangle, fn func(page int, ls []Line) error, ops ...FitterOption) error {
+	for page := 0; sw.HasNext(); page++ {
+		ls, _, err := sw.TextToRect(r, ops...)
+		if err != nil {
+			return fmt.Errorf("laying out page %d: %w", page, err)
+		}
+		if len(ls) == 0 {
+			return fmt.Errorf("laying out page %d: %w", page, ErrNoProgress)
+		}
+		if err := fn(page, ls); err != nil {
+			return err
+		}
+	}
+	return sw.boxerErr()
+}
+
+// restart goes back to the start of the text
+func (sw *SimpleWrapper) restart() {
+	sw.boxer.Reset()
+	sw.indent = 0
+	sw.footnotes = nil
+	sw.currentPage = 0
+	sw.fields.section = ""
+	sw.sourceLines = 0
+	sw.continued = false
+}
+
+// HasNext are there any unprocessed bytes in the boxer, or footnotes still to place
 func (sw *SimpleWrapper) HasNext() bool {
-	return sw.boxer.HasNext()
+	return sw.boxer.HasNext() || len(sw.footnotes) > 0
 }
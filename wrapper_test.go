@@ -303,3 +303,40 @@ func TestBlockAlignment(t *testing.T) {
 		t.Errorf("Right/Bottom offset = %v, want %v", off, want)
 	}
 }
+
+func TestLinePositionStartingRightOfBounds(t *testing.T) {
+	fontFace := FontFace16DPI180ForTest(t)
+	tests := []struct {
+		name     string
+		position HorizontalLinePosition
+		bounds   image.Rectangle
+		at       image.Point
+	}{
+		{name: "Right lines at an offset", position: RightLines, bounds: image.Rect(0, 0, 600, 200), at: image.Pt(100, 0)},
+		{name: "Right lines in a sub image", position: RightLines, bounds: image.Rect(100, 0, 600, 200), at: image.Pt(100, 0)},
+		{name: "Centred lines at an offset", position: HorizontalCenterLines, bounds: image.Rect(0, 0, 600, 200), at: image.Pt(100, 0)},
+		{name: "Centred lines in a sub image", position: HorizontalCenterLines, bounds: image.Rect(100, 0, 600, 200), at: image.Pt(100, 0)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sw := NewRichWrapper(fontFace, "Short", tt.position)
+			ls, _, err := sw.TextToRect(image.Rect(0, 0, 400, 200))
+			if err != nil {
+				t.Fatalf("TextToRect failed: %v", err)
+			}
+			// Lines are aligned in the width from where they start to the right of the bounds
+			lr := sw.lineRects(ls, tt.bounds, tt.at)[0]
+			left, right := lr.Min.X-tt.at.X, tt.bounds.Max.X-lr.Max.X
+			switch tt.position {
+			case RightLines:
+				if right != 0 {
+					t.Errorf("line %v ends %d from the right of %v", lr, right, tt.bounds)
+				}
+			case HorizontalCenterLines:
+				if left-right > 1 || right-left > 1 {
+					t.Errorf("line %v isn't centred between %d and %d", lr, tt.at.X, tt.bounds.Max.X)
+				}
+			}
+		})
+	}
+}